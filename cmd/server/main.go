@@ -2,17 +2,34 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
 	apiClient "github.com/leinonen/hexagonal-architecture-go/internal/adapters/api"
+	"github.com/leinonen/hexagonal-architecture-go/internal/adapters/api/breaker"
+	"github.com/leinonen/hexagonal-architecture-go/internal/adapters/api/cache"
+	"github.com/leinonen/hexagonal-architecture-go/internal/adapters/api/coalesce"
+	"github.com/leinonen/hexagonal-architecture-go/internal/adapters/api/failover"
+	"github.com/leinonen/hexagonal-architecture-go/internal/adapters/api/fake"
+	"github.com/leinonen/hexagonal-architecture-go/internal/adapters/api/history"
+	"github.com/leinonen/hexagonal-architecture-go/internal/adapters/api/limit"
 	httpHandler "github.com/leinonen/hexagonal-architecture-go/internal/adapters/http"
+	"github.com/leinonen/hexagonal-architecture-go/internal/adapters/jwt"
+	"github.com/leinonen/hexagonal-architecture-go/internal/adapters/password"
 	"github.com/leinonen/hexagonal-architecture-go/internal/adapters/repository/memory"
+	"github.com/leinonen/hexagonal-architecture-go/internal/adapters/webhook"
 	"github.com/leinonen/hexagonal-architecture-go/internal/application"
+	"github.com/leinonen/hexagonal-architecture-go/internal/domain"
+	"github.com/leinonen/hexagonal-architecture-go/internal/metrics"
+	"github.com/leinonen/hexagonal-architecture-go/internal/ports"
 )
 
 func main() {
@@ -21,22 +38,76 @@ func main() {
 		port = "8080"
 	}
 
-	weatherAPIKey := os.Getenv("WEATHER_API_KEY")
-	if weatherAPIKey == "" {
-		weatherAPIKey = "demo-key"
-		log.Println("Warning: WEATHER_API_KEY not set, using demo key")
+	signingKey, err := newSigningKey()
+	if err != nil {
+		log.Fatalf("Invalid JWT signing key: %v", err)
 	}
 
+	tokenVerifier, err := newTokenVerifier(signingKey)
+	if err != nil {
+		log.Fatalf("Invalid JWT configuration: %v", err)
+	}
+
+	registry := metrics.NewRegistry()
+
 	userRepo := memory.NewUserRepository()
-	weatherClient := apiClient.NewWeatherClient(weatherAPIKey)
+	apiKeyRepo := memory.NewAPIKeyRepository()
+	sessionRepo := memory.NewSessionRepository()
+	locationRepo := memory.NewLocationRepository()
+	alertRuleRepo := memory.NewAlertRuleRepository()
+	observationRepo, err := newObservationRepository()
+	if err != nil {
+		log.Fatalf("Invalid weather history configuration: %v", err)
+	}
+	budgets := make(map[string]*limit.Budget)
+	weatherProvider, healthCheckers, err := newWeatherProvider(registry, budgets)
+	if err != nil {
+		log.Fatalf("Invalid weather provider configuration: %v", err)
+	}
+
+	recorder := history.NewWeatherRecorder(weatherProvider, observationRepo)
+	weatherClient, err := newWeatherCache(coalesce.NewWeatherCoalescer(recorder), registry)
+	if err != nil {
+		log.Fatalf("Invalid weather cache configuration: %v", err)
+	}
+
+	geocoder, err := newGeocoder(registry, budgets)
+	if err != nil {
+		log.Fatalf("Invalid geocoder configuration: %v", err)
+	}
 
-	userService := application.NewUserService(userRepo)
-	weatherService := application.NewWeatherService(weatherClient, userRepo)
+	alertEvaluator, err := newAlertEvaluator(alertRuleRepo, weatherClient)
+	if err != nil {
+		log.Fatalf("Invalid alert configuration: %v", err)
+	}
+
+	sampler, err := newObservationSampler(weatherClient)
+	if err != nil {
+		log.Fatalf("Invalid weather history configuration: %v", err)
+	}
 
-	handler := httpHandler.NewHandler(userService, weatherService)
+	passwordHasher := password.NewBcryptHasher(0)
+	tokenIssuer := jwt.NewIssuer(os.Getenv("JWT_ISSUER"), os.Getenv("JWT_AUDIENCE"), signingKey)
+
+	userService := application.NewUserService(userRepo, sessionRepo, passwordHasher, locationRepo, alertRuleRepo)
+	weatherService := application.NewWeatherService(weatherClient, userRepo, application.WithGeocoder(geocoder), application.WithLocations(locationRepo), application.WithObservations(observationRepo))
+	authService := application.NewAuthService(apiKeyRepo, sessionRepo, userRepo, tokenVerifier, passwordHasher, tokenIssuer)
+	healthService := application.NewHealthService(healthCheckers...)
+	locationService := application.NewLocationService(locationRepo, userRepo)
+	alertService := application.NewAlertService(alertRuleRepo, userRepo)
+
+	if adminEmail := os.Getenv("ADMIN_EMAIL"); adminEmail != "" {
+		if err := bootstrapAdmin(userService, authService, adminEmail, os.Getenv("ADMIN_NAME"), os.Getenv("ADMIN_API_KEY")); err != nil {
+			log.Fatalf("Failed to bootstrap admin: %v", err)
+		}
+		log.Printf("Admin account %s is ready", adminEmail)
+	}
+
+	handler := httpHandler.NewHandler(userService, weatherService, authService, healthService, locationService, alertService)
 
 	mux := http.NewServeMux()
 	handler.RegisterRoutes(mux)
+	mux.Handle("GET /metrics", registry)
 
 	loggingMiddleware := func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -62,11 +133,20 @@ func main() {
 		}
 	}()
 
+	background, stopBackground := context.WithCancel(context.Background())
+	if alertEvaluator != nil {
+		go alertEvaluator.Run(background)
+	}
+	if sampler != nil {
+		go sampler.Run(background)
+	}
+
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
 	log.Println("Server shutting down...")
+	stopBackground()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
@@ -77,3 +157,531 @@ func main() {
 
 	log.Println("Server exited")
 }
+
+// bootstrapAdmin makes sure the configured admin account exists and accepts
+// the operator-supplied API key, so the first admin never has to be created
+// through the API.
+func bootstrapAdmin(userService *application.UserService, authService *application.AuthService, email, name, apiKey string) error {
+	if apiKey == "" {
+		return fmt.Errorf("ADMIN_API_KEY is required when ADMIN_EMAIL is set")
+	}
+	if name == "" {
+		name = "Administrator"
+	}
+
+	ctx := context.Background()
+
+	admin, err := userService.EnsureAdmin(ctx, email, name)
+	if err != nil {
+		return err
+	}
+
+	_, err = authService.RegisterAPIKey(ctx, admin.ID, "bootstrap", apiKey)
+	return err
+}
+
+// newWeatherProvider builds the providers listed in WEATHER_PROVIDERS (or the
+// single WEATHER_PROVIDER), highest priority first, each behind its own
+// circuit breaker, with HTTP providers spending from their own rate budget.
+// With more than one provider, lookups fail over to the next provider when
+// one is down, and readiness only fails once every provider is. The
+// providers' rate budgets are kept in budgets.
+func newWeatherProvider(registry *metrics.Registry, budgets map[string]*limit.Budget) (ports.WeatherService, []ports.HealthChecker, error) {
+	options, err := newClientOptions()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	names := os.Getenv("WEATHER_PROVIDERS")
+	if names == "" {
+		names = os.Getenv("WEATHER_PROVIDER")
+	}
+	if names == "" {
+		names = apiClient.ProviderOpenWeatherMap
+	}
+
+	var providers []failover.Provider
+	var checkers []ports.HealthChecker
+
+	for _, name := range strings.Split(names, ",") {
+		name = strings.TrimSpace(name)
+
+		var client ports.WeatherService
+		switch name {
+		case apiClient.ProviderOpenWeatherMap:
+			apiKey := os.Getenv("WEATHER_API_KEY")
+			if apiKey == "" {
+				apiKey = "demo-key"
+				log.Println("Warning: WEATHER_API_KEY not set, using demo key")
+			}
+			clientOptions, err := withBudget(withBaseURL(options, "WEATHER_API_BASE_URL"), budgets, name, registry)
+			if err != nil {
+				return nil, nil, err
+			}
+			client = apiClient.NewWeatherClient(apiKey, clientOptions...)
+		case apiClient.ProviderWeatherAPI:
+			apiKey := os.Getenv("WEATHERAPI_KEY")
+			if apiKey == "" {
+				return nil, nil, fmt.Errorf("WEATHERAPI_KEY is required for the %s provider", name)
+			}
+			clientOptions, err := withBudget(withBaseURL(options, "WEATHERAPI_BASE_URL"), budgets, name, registry)
+			if err != nil {
+				return nil, nil, err
+			}
+			client = apiClient.NewWeatherAPIClient(apiKey, clientOptions...)
+		case fake.Provider:
+			client, err = newFakeWeatherService()
+			if err != nil {
+				return nil, nil, err
+			}
+		default:
+			return nil, nil, fmt.Errorf("unknown weather provider %q", name)
+		}
+
+		circuit, err := newCircuitBreaker(client, name, registry)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		providers = append(providers, failover.Provider{Name: name, Service: circuit})
+		checkers = append(checkers, circuit)
+	}
+
+	log.Printf("Weather providers: %s", names)
+
+	if len(providers) == 1 {
+		return providers[0].Service, checkers, nil
+	}
+	return failover.NewWeatherFailover(providers...), failover.NewHealthCheckers(checkers...), nil
+}
+
+// newGeocoder resolves place names through OpenWeatherMap, so it is only
+// available when WEATHER_API_KEY is set. Geocoding counts against the same
+// plan as OpenWeatherMap weather lookups, so it spends from their budget.
+func newGeocoder(registry *metrics.Registry, budgets map[string]*limit.Budget) (ports.Geocoder, error) {
+	apiKey := os.Getenv("WEATHER_API_KEY")
+	if apiKey == "" {
+		return nil, nil
+	}
+
+	options, err := newClientOptions()
+	if err != nil {
+		return nil, err
+	}
+	options, err = withBudget(withBaseURL(options, "WEATHER_GEOCODING_BASE_URL"), budgets, apiClient.ProviderOpenWeatherMap, registry)
+	if err != nil {
+		return nil, err
+	}
+	return apiClient.NewGeocoder(apiKey, options...), nil
+}
+
+// newFakeWeatherService builds the offline provider, pinning the cities in the
+// WEATHER_FAKE_FIXTURES file if one is given.
+func newFakeWeatherService() (ports.WeatherService, error) {
+	path := os.Getenv("WEATHER_FAKE_FIXTURES")
+	if path == "" {
+		return fake.NewWeatherService(), nil
+	}
+
+	fixtures, err := fake.LoadFixtures(path)
+	if err != nil {
+		return nil, err
+	}
+	return fake.NewWeatherService(fixtures...), nil
+}
+
+// newClientOptions configures the provider clients' HTTP plumbing from the
+// retry settings, WEATHER_HTTP_TIMEOUT, WEATHER_HTTP_USER_AGENT and
+// WEATHER_HTTP_MAX_IDLE_CONNS. Outbound proxies come from the standard
+// HTTPS_PROXY and NO_PROXY variables.
+func newClientOptions() ([]apiClient.Option, error) {
+	retryPolicy, err := newRetryPolicy()
+	if err != nil {
+		return nil, err
+	}
+
+	options := []apiClient.Option{apiClient.WithRetryPolicy(retryPolicy)}
+
+	if value := os.Getenv("WEATHER_HTTP_TIMEOUT"); value != "" {
+		timeout, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, apiClient.WithTimeout(timeout))
+	}
+
+	if value := os.Getenv("WEATHER_HTTP_USER_AGENT"); value != "" {
+		options = append(options, apiClient.WithUserAgent(value))
+	}
+
+	maxIdleConns := apiClient.DefaultMaxIdleConns
+	if value := os.Getenv("WEATHER_HTTP_MAX_IDLE_CONNS"); value != "" {
+		maxIdleConns, err = strconv.Atoi(value)
+		if err != nil {
+			return nil, err
+		}
+	}
+	options = append(options, apiClient.WithMaxIdleConns(maxIdleConns))
+
+	return options, nil
+}
+
+// withBaseURL adds the base URL from the named variable, if set, to a copy of
+// the shared options.
+func withBaseURL(options []apiClient.Option, name string) []apiClient.Option {
+	options = append([]apiClient.Option(nil), options...)
+	if baseURL := os.Getenv(name); baseURL != "" {
+		options = append(options, apiClient.WithBaseURL(baseURL))
+	}
+	return options
+}
+
+// withBudget adds the named provider's rate budget, if one is configured, to
+// options.
+func withBudget(options []apiClient.Option, budgets map[string]*limit.Budget, name string, registry *metrics.Registry) ([]apiClient.Option, error) {
+	budget, err := newRateBudget(budgets, name, registry)
+	if err != nil || budget == nil {
+		return options, err
+	}
+	return append(options, apiClient.WithBudget(budget)), nil
+}
+
+// newRetryPolicy starts from the client's default policy and applies
+// WEATHER_RETRY_MAX_ATTEMPTS, WEATHER_RETRY_BASE_DELAY and
+// WEATHER_RETRY_MAX_DELAY.
+func newRetryPolicy() (apiClient.RetryPolicy, error) {
+	policy := apiClient.DefaultRetryPolicy()
+
+	if value := os.Getenv("WEATHER_RETRY_MAX_ATTEMPTS"); value != "" {
+		attempts, err := strconv.Atoi(value)
+		if err != nil {
+			return policy, err
+		}
+		policy.MaxAttempts = attempts
+	}
+
+	if value := os.Getenv("WEATHER_RETRY_BASE_DELAY"); value != "" {
+		delay, err := time.ParseDuration(value)
+		if err != nil {
+			return policy, err
+		}
+		policy.BaseDelay = delay
+	}
+
+	if value := os.Getenv("WEATHER_RETRY_MAX_DELAY"); value != "" {
+		delay, err := time.ParseDuration(value)
+		if err != nil {
+			return policy, err
+		}
+		policy.MaxDelay = delay
+	}
+
+	return policy, nil
+}
+
+// newCircuitBreaker guards a weather provider with a circuit breaker tuned by
+// WEATHER_BREAKER_FAILURE_RATE and WEATHER_BREAKER_COOLDOWN, and publishes its
+// state as 0 (closed), 1 (half-open) or 2 (open).
+func newCircuitBreaker(next ports.WeatherService, name string, registry *metrics.Registry) (*breaker.CircuitBreaker, error) {
+	config := breaker.DefaultConfig()
+	config.Name = name
+
+	if value := os.Getenv("WEATHER_BREAKER_FAILURE_RATE"); value != "" {
+		rate, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, err
+		}
+		config.FailureRate = rate
+	}
+
+	if value := os.Getenv("WEATHER_BREAKER_COOLDOWN"); value != "" {
+		cooldown, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, err
+		}
+		config.Cooldown = cooldown
+	}
+
+	circuit := breaker.NewCircuitBreaker(next, config)
+
+	registry.GaugeFunc(fmt.Sprintf("weather_circuit_state{provider=%q}", name), "Weather provider circuit state: 0 closed, 1 half-open, 2 open.", func() float64 {
+		return float64(circuit.State())
+	})
+
+	return circuit, nil
+}
+
+// newRateBudget returns the budget that keeps requests to a provider within
+// WEATHER_RATE_LIMIT a minute, retries included, allowing bursts of
+// WEATHER_RATE_LIMIT_BURST and queueing requests for up to
+// WEATHER_RATE_LIMIT_MAX_WAIT. Each provider gets one budget, created on first
+// use and shared by every client of its API. Without WEATHER_RATE_LIMIT
+// requests are not limited and the budget is nil. The circuit breaker ignores
+// requests our own budget refuses, so they never count as provider failures.
+func newRateBudget(budgets map[string]*limit.Budget, name string, registry *metrics.Registry) (*limit.Budget, error) {
+	if budget, ok := budgets[name]; ok {
+		return budget, nil
+	}
+
+	value := os.Getenv("WEATHER_RATE_LIMIT")
+	if value == "" {
+		return nil, nil
+	}
+
+	config := limit.DefaultConfig()
+	config.Name = name
+
+	perMinute, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, err
+	}
+	if perMinute <= 0 {
+		return nil, fmt.Errorf("WEATHER_RATE_LIMIT must be positive, got %s", value)
+	}
+	config.Rate = perMinute / 60
+
+	if value := os.Getenv("WEATHER_RATE_LIMIT_BURST"); value != "" {
+		burst, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, err
+		}
+		config.Burst = burst
+	}
+
+	if value := os.Getenv("WEATHER_RATE_LIMIT_MAX_WAIT"); value != "" {
+		wait, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, err
+		}
+		config.MaxWait = wait
+	}
+
+	budget := limit.NewBudget(config)
+	budgets[name] = budget
+
+	registry.CounterFunc(fmt.Sprintf("weather_rate_limit_queued_total{provider=%q}", name), "Weather provider requests that waited for the local rate limit.", func() float64 {
+		return float64(budget.Stats().Queued)
+	})
+	registry.CounterFunc(fmt.Sprintf("weather_rate_limit_rejected_total{provider=%q}", name), "Weather provider requests rejected by the local rate limit.", func() float64 {
+		return float64(budget.Stats().Rejected)
+	})
+
+	return budget, nil
+}
+
+// newWeatherCache wraps the weather provider in a cache configured by
+// WEATHER_CACHE_TTL, WEATHER_CACHE_NEGATIVE_TTL and WEATHER_CACHE_SIZE, and
+// publishes its hit and miss counters.
+func newWeatherCache(next ports.WeatherService, registry *metrics.Registry) (ports.WeatherService, error) {
+	config := cache.Config{
+		TTL:         cache.DefaultTTL,
+		NegativeTTL: cache.DefaultNegativeTTL,
+		MaxEntries:  cache.DefaultMaxEntries,
+	}
+
+	if value := os.Getenv("WEATHER_CACHE_TTL"); value != "" {
+		ttl, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, err
+		}
+		if ttl == 0 {
+			log.Println("Weather cache disabled")
+			return next, nil
+		}
+		config.TTL = ttl
+	}
+
+	if value := os.Getenv("WEATHER_CACHE_NEGATIVE_TTL"); value != "" {
+		ttl, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, err
+		}
+		config.NegativeTTL = ttl
+	}
+
+	if value := os.Getenv("WEATHER_CACHE_SIZE"); value != "" {
+		size, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, err
+		}
+		config.MaxEntries = size
+	}
+
+	weatherCache := cache.NewWeatherCache(next, config)
+
+	registry.CounterFunc("weather_cache_hits_total", "Weather lookups answered from the cache.", func() float64 {
+		return float64(weatherCache.Stats().Hits)
+	})
+	registry.CounterFunc("weather_cache_misses_total", "Weather lookups that went to the provider.", func() float64 {
+		return float64(weatherCache.Stats().Misses)
+	})
+	registry.CounterFunc("weather_cache_evictions_total", "Weather cache entries evicted to stay within the size bound.", func() float64 {
+		return float64(weatherCache.Stats().Evictions)
+	})
+	registry.GaugeFunc("weather_cache_entries", "Weather cache entries currently held.", func() float64 {
+		return float64(weatherCache.Stats().Entries)
+	})
+
+	return weatherCache, nil
+}
+
+// newAlertEvaluator checks alert rules every ALERT_CHECK_INTERVAL and
+// delivers alerts to ALERT_WEBHOOK_URL, signed with ALERT_WEBHOOK_SECRET.
+// Without a webhook, rules can still be managed but are not evaluated.
+func newAlertEvaluator(ruleRepo ports.AlertRuleRepository, weatherClient ports.WeatherService) (*application.AlertEvaluator, error) {
+	webhookURL := os.Getenv("ALERT_WEBHOOK_URL")
+	if webhookURL == "" {
+		log.Println("Warning: ALERT_WEBHOOK_URL not set, alert rules will not be evaluated")
+		return nil, nil
+	}
+	if parsed, err := url.Parse(webhookURL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("ALERT_WEBHOOK_URL must be an http or https URL, got %q", webhookURL)
+	}
+
+	secret := os.Getenv("ALERT_WEBHOOK_SECRET")
+	if secret == "" {
+		return nil, fmt.Errorf("ALERT_WEBHOOK_SECRET is required when ALERT_WEBHOOK_URL is set")
+	}
+
+	config := webhook.DefaultConfig()
+	config.URL = webhookURL
+	config.Secret = []byte(secret)
+
+	if value := os.Getenv("ALERT_WEBHOOK_MAX_ATTEMPTS"); value != "" {
+		attempts, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, err
+		}
+		config.MaxAttempts = attempts
+	}
+
+	interval := application.DefaultAlertInterval
+	if value := os.Getenv("ALERT_CHECK_INTERVAL"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, err
+		}
+		interval = parsed
+	}
+
+	log.Printf("Alert rules checked every %v", interval)
+
+	return application.NewAlertEvaluator(ruleRepo, weatherClient, webhook.NewNotifier(config), interval), nil
+}
+
+// newObservationRepository keeps fetched weather for
+// WEATHER_HISTORY_RETENTION.
+func newObservationRepository() (ports.ObservationRepository, error) {
+	retention := memory.DefaultObservationRetention
+	if value := os.Getenv("WEATHER_HISTORY_RETENTION"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, err
+		}
+		retention = parsed
+	}
+	return memory.NewObservationRepository(retention), nil
+}
+
+// newObservationSampler looks up the places in WEATHER_HISTORY_CITIES every
+// WEATHER_HISTORY_INTERVAL. Places are separated by semicolons, each a city
+// optionally followed by a comma and a country code, e.g. "London,GB;Paris".
+// Without it nothing is sampled.
+func newObservationSampler(weatherClient ports.WeatherService) (*application.ObservationSampler, error) {
+	value := os.Getenv("WEATHER_HISTORY_CITIES")
+	if value == "" {
+		return nil, nil
+	}
+
+	var places []domain.WeatherQuery
+	for _, entry := range strings.Split(value, ";") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		city, country, _ := strings.Cut(entry, ",")
+		place := domain.WeatherQuery{City: strings.TrimSpace(city), Country: strings.ToUpper(strings.TrimSpace(country))}
+		if err := place.Validate(); err != nil {
+			return nil, fmt.Errorf("WEATHER_HISTORY_CITIES entry %q: %w", entry, err)
+		}
+		places = append(places, place)
+	}
+
+	interval := application.DefaultSampleInterval
+	if value := os.Getenv("WEATHER_HISTORY_INTERVAL"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, err
+		}
+		interval = parsed
+	}
+
+	log.Printf("Sampling weather at %d place(s) every %v", len(places), interval)
+
+	return application.NewObservationSampler(weatherClient, places, interval), nil
+}
+
+// newSigningKey loads the HS256 key used to sign login access tokens from
+// JWT_SIGNING_KEY_FILE. Without it a random key is generated, so issued
+// tokens stop working when the process restarts.
+func newSigningKey() (jwt.SigningKey, error) {
+	path := os.Getenv("JWT_SIGNING_KEY_FILE")
+	if path == "" {
+		log.Println("Warning: JWT_SIGNING_KEY_FILE not set, access tokens will not survive a restart")
+		return jwt.GenerateHMACKey("local")
+	}
+
+	key, err := jwt.LoadHMACKeyFile(path, "local")
+	if err != nil {
+		return jwt.SigningKey{}, err
+	}
+	return jwt.SigningKey{ID: key.ID, Algorithm: key.Algorithm, Secret: key.Secret}, nil
+}
+
+// newTokenVerifier builds a JWT verifier that accepts tokens signed with the
+// local signing key plus any keys from JWT_HMAC_KEY_FILE, JWT_PUBLIC_KEY_FILE
+// and JWT_JWKS_FILE.
+func newTokenVerifier(signingKey jwt.SigningKey) (ports.TokenVerifier, error) {
+	keys := []jwt.Key{signingKey.VerificationKey()}
+
+	if path := os.Getenv("JWT_HMAC_KEY_FILE"); path != "" {
+		key, err := jwt.LoadHMACKeyFile(path, os.Getenv("JWT_HMAC_KEY_ID"))
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	if path := os.Getenv("JWT_PUBLIC_KEY_FILE"); path != "" {
+		key, err := jwt.LoadPublicKeyFile(path, os.Getenv("JWT_PUBLIC_KEY_ID"))
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	if path := os.Getenv("JWT_JWKS_FILE"); path != "" {
+		jwks, err := jwt.LoadJWKSFile(path)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, jwks...)
+	}
+
+	leeway := 30 * time.Second
+	if value := os.Getenv("JWT_LEEWAY"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, err
+		}
+		leeway = parsed
+	}
+
+	log.Printf("JWT authentication enabled with %d key(s)", len(keys))
+
+	return jwt.NewVerifier(jwt.Config{
+		Issuer:   os.Getenv("JWT_ISSUER"),
+		Audience: os.Getenv("JWT_AUDIENCE"),
+		Leeway:   leeway,
+		Keys:     keys,
+	})
+}
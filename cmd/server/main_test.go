@@ -2,31 +2,177 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
-	apiClient "github.com/leinonen/hexagonal-architecture-go/internal/adapters/api"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/leinonen/hexagonal-architecture-go/internal/adapters/api/fake"
+	"github.com/leinonen/hexagonal-architecture-go/internal/adapters/api/history"
+	"github.com/leinonen/hexagonal-architecture-go/internal/adapters/api/limit"
 	httpHandler "github.com/leinonen/hexagonal-architecture-go/internal/adapters/http"
+	"github.com/leinonen/hexagonal-architecture-go/internal/adapters/jwt"
+	"github.com/leinonen/hexagonal-architecture-go/internal/adapters/password"
 	"github.com/leinonen/hexagonal-architecture-go/internal/adapters/repository/memory"
+	"github.com/leinonen/hexagonal-architecture-go/internal/adapters/webhook"
 	"github.com/leinonen/hexagonal-architecture-go/internal/application"
+	"github.com/leinonen/hexagonal-architecture-go/internal/domain"
+	"github.com/leinonen/hexagonal-architecture-go/internal/errors"
+	"github.com/leinonen/hexagonal-architecture-go/internal/metrics"
+	"github.com/leinonen/hexagonal-architecture-go/internal/ports"
 )
 
+const testAdminKey = "hak_integration-admin-key-0123456789"
+
+var testJWTKey = jwt.SigningKey{Algorithm: jwt.HS256, Secret: []byte("integration-test-secret-32-bytes")}
+
+// testApp exposes what integration tests drive other than through the API.
+type testApp struct {
+	weatherClient ports.WeatherService
+	alertRuleRepo ports.AlertRuleRepository
+}
+
 func setupTestServer() *httptest.Server {
+	server, _ := setupTestApp()
+	return server
+}
+
+func setupTestApp() (*httptest.Server, testApp) {
+	tokenVerifier, _ := jwt.NewVerifier(jwt.Config{
+		Issuer:   "integration-test",
+		Audience: "hex-api",
+		Keys:     []jwt.Key{{Algorithm: testJWTKey.Algorithm, Secret: testJWTKey.Secret}},
+	})
+
 	userRepo := memory.NewUserRepository()
-	weatherClient := apiClient.NewWeatherClient("test-key")
+	apiKeyRepo := memory.NewAPIKeyRepository()
+	sessionRepo := memory.NewSessionRepository()
+	locationRepo := memory.NewLocationRepository()
+	alertRuleRepo := memory.NewAlertRuleRepository()
+	observationRepo := memory.NewObservationRepository(0)
+	provider := fake.NewWeatherService(
+		fake.Fixture{City: "London", Country: "GB", Latitude: 51.51, Longitude: -0.13, Temperature: 10, Condition: domain.ConditionRain, Description: "light rain", Humidity: 80, WindSpeed: 4},
+		fake.Fixture{City: "Atlantis", NotFound: true},
+	)
+	weatherClient := history.NewWeatherRecorder(provider, observationRepo)
+
+	passwordHasher := password.NewBcryptHasher(bcrypt.MinCost)
+	tokenIssuer := jwt.NewIssuer("integration-test", "hex-api", testJWTKey)
+
+	userService := application.NewUserService(userRepo, sessionRepo, passwordHasher)
+	weatherService := application.NewWeatherService(weatherClient, userRepo, application.WithLocations(locationRepo), application.WithObservations(observationRepo))
+	authService := application.NewAuthService(apiKeyRepo, sessionRepo, userRepo, tokenVerifier, passwordHasher, tokenIssuer)
 
-	userService := application.NewUserService(userRepo)
-	weatherService := application.NewWeatherService(weatherClient, userRepo)
+	if err := bootstrapAdmin(userService, authService, "admin@test.com", "Admin", testAdminKey); err != nil {
+		panic(err)
+	}
+
+	locationService := application.NewLocationService(locationRepo, userRepo)
 
-	handler := httpHandler.NewHandler(userService, weatherService)
+	handler := httpHandler.NewHandler(userService, weatherService, authService, application.NewHealthService(), locationService, application.NewAlertService(alertRuleRepo, userRepo))
 
 	mux := http.NewServeMux()
 	handler.RegisterRoutes(mux)
 
-	return httptest.NewServer(mux)
+	return httptest.NewServer(mux), testApp{weatherClient: weatherClient, alertRuleRepo: alertRuleRepo}
+}
+
+func registerUser(t *testing.T, client *http.Client, serverURL, email, name string) (string, string) {
+	t.Helper()
+
+	body, _ := json.Marshal(map[string]string{
+		"email": email,
+		"name":  name,
+	})
+
+	resp, err := client.Post(serverURL+"/api/users", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("Failed to register user: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("Register user status = %v, want %v", resp.StatusCode, http.StatusCreated)
+	}
+
+	var created map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&created)
+
+	return created["id"].(string), created["api_key"].(string)
+}
+
+func newAuthorizedRequest(t *testing.T, method, url, apiKey string, body []byte) *http.Request {
+	t.Helper()
+
+	var req *http.Request
+	var err error
+	if body != nil {
+		req, err = http.NewRequest(method, url, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+	} else {
+		req, err = http.NewRequest(method, url, nil)
+	}
+	if err != nil {
+		t.Fatalf("Failed to build request: %v", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+apiKey)
+	return req
+}
+
+func TestNewWeatherProvider_Fake(t *testing.T) {
+	fixtures := filepath.Join(t.TempDir(), "weather.json")
+	os.WriteFile(fixtures, []byte(`[
+		{"city": "London", "country": "GB", "temperature": 14.5, "condition": "rain"},
+		{"city": "Atlantis", "not_found": true}
+	]`), 0o600)
+
+	tests := []struct {
+		name     string
+		fixtures string
+		wantErr  bool
+	}{
+		{name: "fixtures", fixtures: fixtures},
+		{name: "missing fixtures file", fixtures: filepath.Join(t.TempDir(), "missing.json"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("WEATHER_PROVIDERS", "")
+			t.Setenv("WEATHER_PROVIDER", "fake")
+			t.Setenv("WEATHER_FAKE_FIXTURES", tt.fixtures)
+
+			provider, checkers, err := newWeatherProvider(metrics.NewRegistry(), map[string]*limit.Budget{})
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("newWeatherProvider() should fail")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("newWeatherProvider() unexpected error: %v", err)
+			}
+			if len(checkers) != 1 {
+				t.Errorf("newWeatherProvider() health checkers = %d, want 1", len(checkers))
+			}
+
+			ctx := context.Background()
+			weather, err := provider.GetWeather(ctx, domain.CityQuery("London"))
+			if err != nil || weather.Temperature != 14.5 || weather.Provider != "fake" {
+				t.Errorf("GetWeather() = %+v, %v, want the London fixture from the fake provider", weather, err)
+			}
+			if _, err := provider.GetWeather(ctx, domain.CityQuery("Atlantis")); !errors.IsNotFound(err) {
+				t.Errorf("GetWeather() error = %v, want not found", err)
+			}
+		})
+	}
 }
 
 func TestIntegration_UserCRUD(t *testing.T) {
@@ -59,10 +205,15 @@ func TestIntegration_UserCRUD(t *testing.T) {
 			t.Errorf("Create user response missing ID")
 		}
 
+		apiKey, _ := createResp["api_key"].(string)
+		if apiKey == "" {
+			t.Fatalf("Create user response missing api_key")
+		}
+
 		userID := createResp["id"].(string)
 
 		t.Run("Get Created User", func(t *testing.T) {
-			resp, err := client.Get(server.URL + "/api/users/" + userID)
+			resp, err := client.Do(newAuthorizedRequest(t, "GET", server.URL+"/api/users/"+userID, apiKey, nil))
 			if err != nil {
 				t.Fatalf("Failed to get user: %v", err)
 			}
@@ -86,10 +237,7 @@ func TestIntegration_UserCRUD(t *testing.T) {
 			}
 			body, _ := json.Marshal(updateBody)
 
-			req, _ := http.NewRequest("PUT", server.URL+"/api/users/"+userID, bytes.NewReader(body))
-			req.Header.Set("Content-Type", "application/json")
-
-			resp, err := client.Do(req)
+			resp, err := client.Do(newAuthorizedRequest(t, "PUT", server.URL+"/api/users/"+userID, apiKey, body))
 			if err != nil {
 				t.Fatalf("Failed to update user: %v", err)
 			}
@@ -108,7 +256,7 @@ func TestIntegration_UserCRUD(t *testing.T) {
 		})
 
 		t.Run("List Users", func(t *testing.T) {
-			resp, err := client.Get(server.URL + "/api/users")
+			resp, err := client.Do(newAuthorizedRequest(t, "GET", server.URL+"/api/users", testAdminKey, nil))
 			if err != nil {
 				t.Fatalf("Failed to list users: %v", err)
 			}
@@ -127,9 +275,17 @@ func TestIntegration_UserCRUD(t *testing.T) {
 		})
 
 		t.Run("Delete User", func(t *testing.T) {
-			req, _ := http.NewRequest("DELETE", server.URL+"/api/users/"+userID, nil)
+			resp, err := client.Do(newAuthorizedRequest(t, "DELETE", server.URL+"/api/users/"+userID, apiKey, nil))
+			if err != nil {
+				t.Fatalf("Failed to delete user: %v", err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != http.StatusForbidden {
+				t.Errorf("Self delete status = %v, want %v", resp.StatusCode, http.StatusForbidden)
+			}
 
-			resp, err := client.Do(req)
+			resp, err = client.Do(newAuthorizedRequest(t, "DELETE", server.URL+"/api/users/"+userID, testAdminKey, nil))
 			if err != nil {
 				t.Fatalf("Failed to delete user: %v", err)
 			}
@@ -139,7 +295,7 @@ func TestIntegration_UserCRUD(t *testing.T) {
 				t.Errorf("Delete user status = %v, want %v", resp.StatusCode, http.StatusNoContent)
 			}
 
-			resp, _ = client.Get(server.URL + "/api/users/" + userID)
+			resp, _ = client.Do(newAuthorizedRequest(t, "GET", server.URL+"/api/users/"+userID, testAdminKey, nil))
 			if resp.StatusCode != http.StatusNotFound {
 				t.Errorf("Get deleted user status = %v, want %v", resp.StatusCode, http.StatusNotFound)
 			}
@@ -201,16 +357,184 @@ func TestIntegration_ErrorHandling(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			var req *http.Request
+			var body []byte
 			if tt.body != nil {
-				body, _ := json.Marshal(tt.body)
-				req, _ = http.NewRequest(tt.method, server.URL+tt.path, bytes.NewReader(body))
-				req.Header.Set("Content-Type", "application/json")
-			} else {
-				req, _ = http.NewRequest(tt.method, server.URL+tt.path, nil)
+				body, _ = json.Marshal(tt.body)
+			}
+
+			resp, err := client.Do(newAuthorizedRequest(t, tt.method, server.URL+tt.path, testAdminKey, body))
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("%s status = %v, want %v", tt.name, resp.StatusCode, tt.wantStatus)
 			}
+		})
+	}
+}
+
+func TestIntegration_Authentication(t *testing.T) {
+	server := setupTestServer()
+	defer server.Close()
+
+	client := &http.Client{Timeout: 5 * time.Second}
+
+	aliceID, aliceKey := registerUser(t, client, server.URL, "alice@test.com", "Alice")
+	_, bobKey := registerUser(t, client, server.URL, "bob@test.com", "Bob")
+
+	t.Run("Missing credentials", func(t *testing.T) {
+		resp, err := client.Get(server.URL + "/api/users/" + aliceID)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("Missing credentials status = %v, want %v", resp.StatusCode, http.StatusUnauthorized)
+		}
+	})
+
+	t.Run("Invalid key", func(t *testing.T) {
+		resp, err := client.Do(newAuthorizedRequest(t, "GET", server.URL+"/api/users/"+aliceID, "hak_invalid", nil))
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		defer resp.Body.Close()
 
-			resp, err := client.Do(req)
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("Invalid key status = %v, want %v", resp.StatusCode, http.StatusUnauthorized)
+		}
+	})
+
+	t.Run("X-API-Key header", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", server.URL+"/api/users/"+aliceID, nil)
+		req.Header.Set("X-API-Key", aliceKey)
+
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("X-API-Key status = %v, want %v", resp.StatusCode, http.StatusOK)
+		}
+	})
+
+	t.Run("Issue and revoke key", func(t *testing.T) {
+		body, _ := json.Marshal(map[string]string{"name": "ci"})
+		resp, err := client.Do(newAuthorizedRequest(t, "POST", server.URL+"/api/users/"+aliceID+"/api-keys", aliceKey, body))
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusCreated {
+			t.Fatalf("Issue key status = %v, want %v", resp.StatusCode, http.StatusCreated)
+		}
+
+		var issued map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&issued)
+
+		newKey := issued["key"].(string)
+		keyID := issued["id"].(string)
+
+		resp, _ = client.Do(newAuthorizedRequest(t, "DELETE", server.URL+"/api/users/"+aliceID+"/api-keys/"+keyID, aliceKey, nil))
+		if resp.StatusCode != http.StatusNoContent {
+			t.Errorf("Revoke key status = %v, want %v", resp.StatusCode, http.StatusNoContent)
+		}
+
+		resp, _ = client.Do(newAuthorizedRequest(t, "GET", server.URL+"/api/users/"+aliceID, newKey, nil))
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("Revoked key status = %v, want %v", resp.StatusCode, http.StatusUnauthorized)
+		}
+	})
+
+	t.Run("Weather for another user", func(t *testing.T) {
+		resp, err := client.Do(newAuthorizedRequest(t, "GET", server.URL+"/api/users/"+aliceID+"/weather?city=London", bobKey, nil))
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusForbidden {
+			t.Errorf("Foreign weather status = %v, want %v", resp.StatusCode, http.StatusForbidden)
+		}
+	})
+
+	t.Run("Another user's profile", func(t *testing.T) {
+		resp, err := client.Do(newAuthorizedRequest(t, "GET", server.URL+"/api/users/"+aliceID, bobKey, nil))
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusForbidden {
+			t.Errorf("Foreign profile status = %v, want %v", resp.StatusCode, http.StatusForbidden)
+		}
+	})
+
+	t.Run("Admin reads any profile", func(t *testing.T) {
+		resp, err := client.Do(newAuthorizedRequest(t, "GET", server.URL+"/api/users/"+aliceID, testAdminKey, nil))
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("Admin profile status = %v, want %v", resp.StatusCode, http.StatusOK)
+		}
+	})
+}
+
+func TestIntegration_JWTAuthentication(t *testing.T) {
+	server := setupTestServer()
+	defer server.Close()
+
+	client := &http.Client{Timeout: 5 * time.Second}
+
+	userID, _ := registerUser(t, client, server.URL, "service@test.com", "Service")
+
+	mintToken := func(expiresIn time.Duration, audience string) string {
+		token, err := jwt.Mint(jwt.Claims{
+			Subject:   userID,
+			Issuer:    "integration-test",
+			Audience:  jwt.Audience{audience},
+			ExpiresAt: time.Now().Add(expiresIn).Unix(),
+		}, testJWTKey)
+		if err != nil {
+			t.Fatalf("Mint() unexpected error: %v", err)
+		}
+		return token
+	}
+
+	tests := []struct {
+		name       string
+		token      string
+		wantStatus int
+	}{
+		{
+			name:       "valid token",
+			token:      mintToken(time.Minute, "hex-api"),
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "expired token",
+			token:      mintToken(-time.Hour, "hex-api"),
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "foreign audience",
+			token:      mintToken(time.Minute, "other-api"),
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := client.Do(newAuthorizedRequest(t, "GET", server.URL+"/api/users/"+userID, tt.token, nil))
 			if err != nil {
 				t.Fatalf("Request failed: %v", err)
 			}
@@ -223,6 +547,109 @@ func TestIntegration_ErrorHandling(t *testing.T) {
 	}
 }
 
+func TestIntegration_PasswordLogin(t *testing.T) {
+	server := setupTestServer()
+	defer server.Close()
+
+	client := &http.Client{Timeout: 5 * time.Second}
+
+	body, _ := json.Marshal(map[string]string{
+		"email":    "login@test.com",
+		"name":     "Login",
+		"password": "correcthorse42",
+	})
+	resp, err := client.Post(server.URL+"/api/users", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("Failed to register user: %v", err)
+	}
+	var created map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&created)
+	resp.Body.Close()
+	userID := created["id"].(string)
+
+	login := func(password string) (*http.Response, map[string]interface{}) {
+		body, _ := json.Marshal(map[string]string{"email": "login@test.com", "password": password})
+		resp, err := client.Post(server.URL+"/api/auth/login", "application/json", bytes.NewReader(body))
+		if err != nil {
+			t.Fatalf("Login request failed: %v", err)
+		}
+		defer resp.Body.Close()
+
+		var payload map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&payload)
+		return resp, payload
+	}
+
+	t.Run("Weak password rejected at registration", func(t *testing.T) {
+		body, _ := json.Marshal(map[string]string{"email": "weak@test.com", "name": "Weak", "password": "123"})
+		resp, err := client.Post(server.URL+"/api/users", "application/json", bytes.NewReader(body))
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("Weak password status = %v, want %v", resp.StatusCode, http.StatusBadRequest)
+		}
+	})
+
+	t.Run("Wrong password", func(t *testing.T) {
+		resp, _ := login("wrong-password-1")
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("Wrong password status = %v, want %v", resp.StatusCode, http.StatusUnauthorized)
+		}
+	})
+
+	t.Run("Login and use access token", func(t *testing.T) {
+		resp, payload := login("correcthorse42")
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Login status = %v, want %v", resp.StatusCode, http.StatusOK)
+		}
+
+		accessToken, _ := payload["access_token"].(string)
+		if accessToken == "" || payload["token_type"] != "Bearer" {
+			t.Fatalf("Login response = %v, want bearer access token", payload)
+		}
+
+		resp, err := client.Do(newAuthorizedRequest(t, "GET", server.URL+"/api/users/"+userID, accessToken, nil))
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("Access token status = %v, want %v", resp.StatusCode, http.StatusOK)
+		}
+	})
+
+	t.Run("Change password", func(t *testing.T) {
+		_, payload := login("correcthorse42")
+		accessToken := payload["access_token"].(string)
+
+		body, _ := json.Marshal(map[string]string{
+			"current_password": "correcthorse42",
+			"new_password":     "batterystaple99",
+		})
+		resp, err := client.Do(newAuthorizedRequest(t, "PUT", server.URL+"/api/users/"+userID+"/password", accessToken, body))
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusNoContent {
+			t.Fatalf("Change password status = %v, want %v", resp.StatusCode, http.StatusNoContent)
+		}
+
+		if resp, _ := login("correcthorse42"); resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("Old password status = %v, want %v", resp.StatusCode, http.StatusUnauthorized)
+		}
+
+		if resp, _ := login("batterystaple99"); resp.StatusCode != http.StatusOK {
+			t.Errorf("New password status = %v, want %v", resp.StatusCode, http.StatusOK)
+		}
+	})
+}
+
 func TestIntegration_DuplicateEmail(t *testing.T) {
 	server := setupTestServer()
 	defer server.Close()
@@ -285,3 +712,348 @@ func TestIntegration_HealthCheck(t *testing.T) {
 		t.Errorf("Health check status = %v, want healthy", health["status"])
 	}
 }
+
+func TestIntegration_RefreshAndLogout(t *testing.T) {
+	server := setupTestServer()
+	defer server.Close()
+
+	client := &http.Client{Timeout: 5 * time.Second}
+
+	body, _ := json.Marshal(map[string]string{
+		"email":    "session@test.com",
+		"name":     "Session",
+		"password": "correcthorse42",
+	})
+	resp, err := client.Post(server.URL+"/api/users", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("Failed to register user: %v", err)
+	}
+	var created map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&created)
+	resp.Body.Close()
+	userID := created["id"].(string)
+
+	post := func(path string, payload map[string]string) (*http.Response, map[string]interface{}) {
+		body, _ := json.Marshal(payload)
+		resp, err := client.Post(server.URL+path, "application/json", bytes.NewReader(body))
+		if err != nil {
+			t.Fatalf("Request to %s failed: %v", path, err)
+		}
+		defer resp.Body.Close()
+
+		var result map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&result)
+		return resp, result
+	}
+
+	resp, tokens := post("/api/auth/login", map[string]string{"email": "session@test.com", "password": "correcthorse42"})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Login status = %v, want %v", resp.StatusCode, http.StatusOK)
+	}
+	refreshToken, _ := tokens["refresh_token"].(string)
+	sessionID, _ := tokens["session_id"].(string)
+	if refreshToken == "" || sessionID == "" {
+		t.Fatalf("Login response = %v, want refresh token and session", tokens)
+	}
+
+	resp, refreshed := post("/api/auth/refresh", map[string]string{"refresh_token": refreshToken})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Refresh status = %v, want %v", resp.StatusCode, http.StatusOK)
+	}
+	rotated, _ := refreshed["refresh_token"].(string)
+	accessToken, _ := refreshed["access_token"].(string)
+	if rotated == "" || rotated == refreshToken || accessToken == "" {
+		t.Fatalf("Refresh response = %v, want rotated tokens", refreshed)
+	}
+
+	t.Run("List sessions", func(t *testing.T) {
+		resp, err := client.Do(newAuthorizedRequest(t, "GET", server.URL+"/api/users/"+userID+"/sessions", accessToken, nil))
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		defer resp.Body.Close()
+
+		var sessions []map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&sessions)
+		if resp.StatusCode != http.StatusOK || len(sessions) != 1 || sessions[0]["id"] != sessionID {
+			t.Errorf("List sessions = %v %v, want the login session", resp.StatusCode, sessions)
+		}
+		if _, leaked := sessions[0]["refresh_token_hash"]; leaked {
+			t.Errorf("List sessions exposes token hashes")
+		}
+	})
+
+	t.Run("Logout revokes the session", func(t *testing.T) {
+		resp, _ := post("/api/auth/logout", map[string]string{"refresh_token": rotated})
+		if resp.StatusCode != http.StatusNoContent {
+			t.Fatalf("Logout status = %v, want %v", resp.StatusCode, http.StatusNoContent)
+		}
+
+		resp, _ = post("/api/auth/refresh", map[string]string{"refresh_token": rotated})
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("Refresh after logout status = %v, want %v", resp.StatusCode, http.StatusUnauthorized)
+		}
+	})
+}
+
+func TestIntegration_Weather(t *testing.T) {
+	server := setupTestServer()
+	defer server.Close()
+
+	client := &http.Client{Timeout: 5 * time.Second}
+
+	getJSON := func(req *http.Request, wantStatus int) map[string]interface{} {
+		t.Helper()
+
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s failed: %v", req.Method, req.URL, err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != wantStatus {
+			t.Fatalf("%s %s status = %v, want %v", req.Method, req.URL, resp.StatusCode, wantStatus)
+		}
+
+		var body map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&body)
+		return body
+	}
+	get := func(path string, wantStatus int) map[string]interface{} {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodGet, server.URL+path, nil)
+		return getJSON(req, wantStatus)
+	}
+
+	weather := get("/api/weather?city=London", http.StatusOK)
+	if weather["temperature"] != 10.0 || weather["condition"] != "rain" || weather["provider"] != fake.Provider || weather["units"] != "metric" {
+		t.Errorf("GET /api/weather = %v, want the London fixture in metric", weather)
+	}
+
+	imperial := get("/api/weather?city=London&units=imperial", http.StatusOK)
+	if imperial["temperature"] != 50.0 || imperial["units"] != "imperial" {
+		t.Errorf("GET /api/weather?units=imperial temperature = %v %v, want 50 imperial", imperial["temperature"], imperial["units"])
+	}
+
+	get("/api/weather?lat=35.68&lon=139.69", http.StatusOK)
+	get("/api/weather?city=Atlantis", http.StatusNotFound)
+	get("/api/weather?lat=91&lon=0", http.StatusBadRequest)
+
+	forecast := get("/api/weather/forecast?city=London&days=2", http.StatusOK)
+	if daily, _ := forecast["daily"].([]interface{}); len(daily) != 2 {
+		t.Errorf("GET /api/weather/forecast returned %d days, want 2", len(daily))
+	}
+
+	userID, apiKey := registerUser(t, client, server.URL, "weather@example.com", "Weather Watcher")
+
+	body, _ := json.Marshal(map[string]string{"units": "imperial"})
+	getJSON(newAuthorizedRequest(t, http.MethodPut, server.URL+"/api/users/"+userID, apiKey, body), http.StatusOK)
+
+	userWeather := getJSON(newAuthorizedRequest(t, http.MethodGet, server.URL+"/api/users/"+userID+"/weather?city=London", apiKey, nil), http.StatusOK)
+	if userWeather["units"] != "imperial" || userWeather["temperature"] != 50.0 {
+		t.Errorf("GET /api/users/{id}/weather = %v %v, want the user's preferred imperial units", userWeather["temperature"], userWeather["units"])
+	}
+
+	userWeatherURL := server.URL + "/api/users/" + userID + "/weather"
+	locationsURL := server.URL + "/api/users/" + userID + "/locations"
+
+	getJSON(newAuthorizedRequest(t, http.MethodGet, userWeatherURL, apiKey, nil), http.StatusBadRequest)
+
+	body, _ = json.Marshal(map[string]string{"name": "Home", "city": "London"})
+	home := getJSON(newAuthorizedRequest(t, http.MethodPost, locationsURL, apiKey, body), http.StatusCreated)
+	if home["primary"] != true || home["city"] != "London" {
+		t.Errorf("POST /api/users/{id}/locations = %v, want a primary London location", home)
+	}
+	homeID, _ := home["id"].(string)
+
+	primaryWeather := getJSON(newAuthorizedRequest(t, http.MethodGet, userWeatherURL, apiKey, nil), http.StatusOK)
+	if primaryWeather["city"] != "London" {
+		t.Errorf("GET /api/users/{id}/weather without a place = %v, want the primary location London", primaryWeather["city"])
+	}
+
+	body, _ = json.Marshal(map[string]interface{}{"latitude": 35.68, "longitude": 139.69, "primary": true})
+	getJSON(newAuthorizedRequest(t, http.MethodPost, locationsURL, apiKey, body), http.StatusCreated)
+
+	resp, err := client.Do(newAuthorizedRequest(t, http.MethodGet, locationsURL, apiKey, nil))
+	if err != nil {
+		t.Fatalf("GET /api/users/{id}/locations failed: %v", err)
+	}
+	var locations []map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&locations)
+	resp.Body.Close()
+	if len(locations) != 2 || locations[0]["primary"] != false || locations[1]["primary"] != true {
+		t.Errorf("GET /api/users/{id}/locations = %v, want Home demoted by the new primary location", locations)
+	}
+
+	body, _ = json.Marshal(map[string]interface{}{"name": "Home", "city": "London", "primary": true})
+	getJSON(newAuthorizedRequest(t, http.MethodPut, locationsURL+"/"+homeID, apiKey, body), http.StatusOK)
+	getJSON(newAuthorizedRequest(t, http.MethodDelete, locationsURL+"/"+homeID, apiKey, nil), http.StatusNoContent)
+	getJSON(newAuthorizedRequest(t, http.MethodGet, locationsURL+"/"+homeID, apiKey, nil), http.StatusNotFound)
+
+	body, _ = json.Marshal(map[string]string{"city": "Atlantis"})
+	getJSON(newAuthorizedRequest(t, http.MethodPost, locationsURL, apiKey, body), http.StatusCreated)
+
+	dashboard := getJSON(newAuthorizedRequest(t, http.MethodGet, server.URL+"/api/users/"+userID+"/dashboard", apiKey, nil), http.StatusOK)
+	entries, _ := dashboard["locations"].([]interface{})
+	if len(entries) != 2 {
+		t.Fatalf("GET /api/users/{id}/dashboard returned %d locations, want 2", len(entries))
+	}
+	tokyo, _ := entries[0].(map[string]interface{})
+	if weather, _ := tokyo["weather"].(map[string]interface{}); tokyo["status"] != 200.0 || weather["units"] != "imperial" {
+		t.Errorf("dashboard entry = %v, want the weather at the saved coordinates in imperial units", tokyo)
+	}
+	atlantis, _ := entries[1].(map[string]interface{})
+	if atlantis["status"] != 404.0 || atlantis["error"] == "" || atlantis["weather"] != nil {
+		t.Errorf("dashboard entry = %v, want a 404 error for Atlantis", atlantis)
+	}
+}
+
+func TestIntegration_Alerts(t *testing.T) {
+	server, app := setupTestApp()
+	defer server.Close()
+
+	client := &http.Client{Timeout: 5 * time.Second}
+
+	var payloads []webhook.AlertPayload
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if !webhook.Verify([]byte("integration-webhook-secret"), r.Header.Get(webhook.TimestampHeader), body, r.Header.Get(webhook.SignatureHeader)) {
+			t.Errorf("webhook delivery signature does not verify")
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		var payload webhook.AlertPayload
+		json.Unmarshal(body, &payload)
+		payloads = append(payloads, payload)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	t.Setenv("ALERT_WEBHOOK_URL", receiver.URL)
+	t.Setenv("ALERT_WEBHOOK_SECRET", "integration-webhook-secret")
+	evaluator, err := newAlertEvaluator(app.alertRuleRepo, app.weatherClient)
+	if err != nil {
+		t.Fatalf("newAlertEvaluator() unexpected error: %v", err)
+	}
+
+	userID, apiKey := registerUser(t, client, server.URL, "alerts@example.com", "Alert Watcher")
+	alertsURL := server.URL + "/api/users/" + userID + "/alerts"
+
+	create := func(rule map[string]interface{}, wantStatus int) map[string]interface{} {
+		t.Helper()
+
+		body, _ := json.Marshal(rule)
+		resp, err := client.Do(newAuthorizedRequest(t, http.MethodPost, alertsURL, apiKey, body))
+		if err != nil {
+			t.Fatalf("POST /api/users/{id}/alerts failed: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != wantStatus {
+			t.Fatalf("POST /api/users/{id}/alerts status = %v, want %v", resp.StatusCode, wantStatus)
+		}
+
+		var created map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&created)
+		return created
+	}
+
+	create(map[string]interface{}{"city": "London", "metric": "temperature", "comparator": "lt"}, http.StatusBadRequest)
+	create(map[string]interface{}{"city": "London", "metric": "temperature", "comparator": "below", "threshold": 0}, http.StatusBadRequest)
+
+	chilly := create(map[string]interface{}{"city": "London", "metric": "temperature", "comparator": "lt", "threshold": 12}, http.StatusCreated)
+	create(map[string]interface{}{"city": "London", "metric": "wind_speed", "comparator": "gt", "threshold": 20}, http.StatusCreated)
+
+	if err := evaluator.Evaluate(context.Background()); err != nil {
+		t.Fatalf("Evaluate() unexpected error: %v", err)
+	}
+
+	if len(payloads) != 1 {
+		t.Fatalf("webhook received %d alerts, want 1", len(payloads))
+	}
+	if payload := payloads[0]; payload.RuleID != chilly["id"] || payload.UserID != userID || payload.Value != 10 || payload.Weather == nil || payload.Weather.City != "London" {
+		t.Errorf("webhook payload = %+v, want the London temperature rule at 10 °C", payload)
+	}
+
+	resp, err := client.Do(newAuthorizedRequest(t, http.MethodGet, alertsURL+"/"+chilly["id"].(string), apiKey, nil))
+	if err != nil {
+		t.Fatalf("GET /api/users/{id}/alerts/{alertID} failed: %v", err)
+	}
+	var rule map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&rule)
+	resp.Body.Close()
+	if rule["triggered"] != true || rule["triggered_at"] == nil {
+		t.Errorf("GET /api/users/{id}/alerts/{alertID} = %v, want the rule marked as triggered", rule)
+	}
+
+	evaluator.Evaluate(context.Background())
+	if len(payloads) != 1 {
+		t.Errorf("webhook received %d alerts after a second pass, want no repeat", len(payloads))
+	}
+
+	resp, err = client.Do(newAuthorizedRequest(t, http.MethodDelete, alertsURL+"/"+chilly["id"].(string), apiKey, nil))
+	if err != nil {
+		t.Fatalf("DELETE /api/users/{id}/alerts/{alertID} failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("DELETE /api/users/{id}/alerts/{alertID} status = %v, want %v", resp.StatusCode, http.StatusNoContent)
+	}
+}
+
+func TestIntegration_WeatherHistory(t *testing.T) {
+	server := setupTestServer()
+	defer server.Close()
+
+	client := &http.Client{Timeout: 5 * time.Second}
+
+	get := func(path string, wantStatus int) map[string]interface{} {
+		t.Helper()
+
+		resp, err := client.Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("GET %s failed: %v", path, err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != wantStatus {
+			t.Fatalf("GET %s status = %v, want %v", path, resp.StatusCode, wantStatus)
+		}
+
+		var body map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&body)
+		return body
+	}
+
+	empty := get("/api/weather/history?city=London", http.StatusOK)
+	if points, _ := empty["points"].([]interface{}); len(points) != 0 {
+		t.Errorf("GET /api/weather/history before any lookup = %v, want no points", empty)
+	}
+
+	get("/api/weather?city=London", http.StatusOK)
+	get("/api/weather?city=Atlantis", http.StatusNotFound)
+
+	// The lookup resolved London to London, GB, whose history both the lookup
+	// and the resolved place find.
+	for _, path := range []string{
+		"/api/weather/history?city=london&interval=15m&units=imperial",
+		"/api/weather/history?city=London&country=GB&interval=15m&units=imperial",
+	} {
+		history := get(path, http.StatusOK)
+		points, _ := history["points"].([]interface{})
+		if len(points) != 1 || history["city"] != "London" || history["country"] != "GB" || history["interval"] != "15m0s" || history["units"] != "imperial" {
+			t.Fatalf("GET %s = %v, want one 15 minute point for London, GB in imperial units", path, history)
+		}
+		point, _ := points[0].(map[string]interface{})
+		temperature, _ := point["temperature"].(map[string]interface{})
+		if point["count"] != 1.0 || temperature["min"] != 50.0 || temperature["max"] != 50.0 || temperature["avg"] != 50.0 {
+			t.Errorf("GET %s point = %v, want the one London lookup at 50 °F", path, point)
+		}
+	}
+
+	get("/api/weather/history?city=Atlantis", http.StatusOK)
+	get("/api/weather/history?city=London&from=yesterday", http.StatusBadRequest)
+	get("/api/weather/history?city=London&interval=often", http.StatusBadRequest)
+	get("/api/weather/history?city=London&from=2024-01-02T00:00:00Z&to=2024-01-01T00:00:00Z", http.StatusBadRequest)
+}
@@ -1,3 +1,5 @@
 module github.com/leinonen/hexagonal-architecture-go
 
-go 1.22
\ No newline at end of file
+go 1.22
+
+require golang.org/x/crypto v0.31.0
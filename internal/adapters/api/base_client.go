@@ -0,0 +1,224 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/leinonen/hexagonal-architecture-go/internal/errors"
+)
+
+const (
+	DefaultTimeout      = 10 * time.Second
+	DefaultMaxIdleConns = 100
+)
+
+// baseClient holds the HTTP plumbing shared by every weather provider client.
+type baseClient struct {
+	httpClient   *http.Client
+	baseURL      string
+	userAgent    string
+	maxIdleConns int
+	retryPolicy  RetryPolicy
+	budget       Budget
+}
+
+type Option func(*baseClient)
+
+// Budget meters the requests made to a provider, such as a *limit.Budget.
+// Wait takes one request from it, failing when none is allowed in time.
+type Budget interface {
+	Wait(ctx context.Context) error
+}
+
+// WithBudget spends one request from budget on every attempt, retries
+// included, so that the client keeps within the provider's quota. A retry the
+// budget cannot afford is not made, and 429s are not retried at all: the
+// provider has just said its quota is spent.
+func WithBudget(budget Budget) Option {
+	return func(c *baseClient) {
+		c.budget = budget
+	}
+}
+
+// WithBaseURL points the client at another server, such as a local stand-in
+// or a proxy, instead of the provider's public API.
+func WithBaseURL(baseURL string) Option {
+	return func(c *baseClient) {
+		c.baseURL = strings.TrimSuffix(baseURL, "/")
+	}
+}
+
+// WithTimeout bounds each attempt, including reading the response body.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *baseClient) {
+		c.httpClient.Timeout = timeout
+	}
+}
+
+func WithTransport(transport http.RoundTripper) Option {
+	return func(c *baseClient) {
+		c.httpClient.Transport = transport
+	}
+}
+
+func WithUserAgent(userAgent string) Option {
+	return func(c *baseClient) {
+		c.userAgent = userAgent
+	}
+}
+
+// WithMaxIdleConns sets how many idle keep-alive connections are kept to the
+// provider. It only applies when the transport is an *http.Transport.
+func WithMaxIdleConns(n int) Option {
+	return func(c *baseClient) {
+		c.maxIdleConns = n
+	}
+}
+
+func newBaseClient(baseURL string, opts []Option) baseClient {
+	base := baseClient{
+		httpClient: &http.Client{
+			Timeout: DefaultTimeout,
+		},
+		baseURL:     baseURL,
+		retryPolicy: DefaultRetryPolicy(),
+	}
+
+	for _, opt := range opts {
+		opt(&base)
+	}
+
+	if base.maxIdleConns > 0 {
+		transport, ok := base.httpClient.Transport.(*http.Transport)
+		if base.httpClient.Transport == nil {
+			transport, ok = http.DefaultTransport.(*http.Transport)
+		}
+		if ok {
+			transport = transport.Clone()
+			transport.MaxIdleConns = base.maxIdleConns
+			transport.MaxIdleConnsPerHost = base.maxIdleConns
+			base.httpClient.Transport = transport
+		}
+	}
+
+	return base
+}
+
+// get performs a GET request for path with the given query parameters,
+// retrying according to the client's retry policy. Once retries are exhausted
+// the last response is returned as is, so callers see the final status code.
+func (c *baseClient) get(ctx context.Context, path string, params url.Values) (*http.Response, error) {
+	target := c.baseURL + path + "?" + params.Encode()
+
+	if err := c.spend(ctx); err != nil {
+		return nil, err
+	}
+
+	for attempt := 1; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+		if err != nil {
+			return nil, errors.NewInternalError(fmt.Sprintf("failed to create request: %v", redactError(err)))
+		}
+		if c.userAgent != "" {
+			req.Header.Set("User-Agent", c.userAgent)
+		}
+
+		resp, err := c.httpClient.Do(req)
+
+		delay, retry := c.retryPolicy.delay(attempt, resp, err)
+		if c.budget != nil && resp != nil && resp.StatusCode == http.StatusTooManyRequests {
+			retry = false
+		}
+		if !retry || !sleep(ctx, delay) || c.spend(ctx) != nil {
+			if err != nil {
+				return nil, errors.NewExternalServiceError(fmt.Sprintf("failed to fetch weather: %v", redactError(err)))
+			}
+			return resp, nil
+		}
+
+		discard(resp)
+	}
+}
+
+// spend takes one request from the client's budget, if it has one.
+func (c *baseClient) spend(ctx context.Context) error {
+	if c.budget == nil {
+		return nil
+	}
+
+	err := c.budget.Wait(ctx)
+	if _, ok := err.(*errors.AppError); err != nil && !ok {
+		return &errors.AppError{
+			Type:    errors.ExternalService,
+			Message: "failed to fetch weather",
+			Err:     err,
+		}
+	}
+	return err
+}
+
+// maxErrorDetail bounds how much of an error response is read for its
+// detail.
+const maxErrorDetail = 4 << 10
+
+// readErrorBody decodes the provider's error payload into v, leaving v empty
+// when the body is missing or not the expected JSON.
+func readErrorBody(resp *http.Response, v any) {
+	json.NewDecoder(io.LimitReader(resp.Body, maxErrorDetail)).Decode(v)
+}
+
+// statusError classifies an unsuccessful provider response. Rejected
+// credentials are a misconfiguration rather than an outage, and 429 carries
+// the provider's Retry-After so that it can be passed on to our clients.
+func statusError(resp *http.Response, detail string) error {
+	var err error
+	switch resp.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		err = errors.NewMisconfigurationError(fmt.Sprintf("weather API rejected our credentials with status: %d", resp.StatusCode))
+	case http.StatusTooManyRequests:
+		retryAfter, _ := parseRetryAfter(resp.Header.Get("Retry-After"))
+		err = errors.NewRateLimitedError("weather API rate limit exceeded", retryAfter)
+	default:
+		err = errors.NewExternalServiceError(fmt.Sprintf("weather API returned status: %d", resp.StatusCode))
+	}
+	return errors.WithDetail(err, detail)
+}
+
+// secretParams are the query parameters providers take API keys in.
+var secretParams = []string{"appid", "key"}
+
+// redactError hides API keys in the request URL that net/http includes in
+// its errors, so they never reach error messages or logs.
+func redactError(err error) error {
+	var urlErr *url.Error
+	if !stderrors.As(err, &urlErr) {
+		return err
+	}
+
+	redacted := *urlErr
+	redacted.URL = redactURL(urlErr.URL)
+	return &redacted
+}
+
+func redactURL(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return "[unparseable URL]"
+	}
+
+	query := parsed.Query()
+	for _, param := range secretParams {
+		if query.Has(param) {
+			query.Set(param, "REDACTED")
+		}
+	}
+	parsed.RawQuery = query.Encode()
+	return parsed.String()
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/leinonen/hexagonal-architecture-go/internal/domain"
+	"github.com/leinonen/hexagonal-architecture-go/internal/errors"
+)
+
+func TestOptions_Defaults(t *testing.T) {
+	client := NewWeatherClient("test-key").(*WeatherClient)
+
+	if client.baseURL != "https://api.openweathermap.org/data/2.5" {
+		t.Errorf("baseURL = %q, want the OpenWeatherMap API", client.baseURL)
+	}
+	if client.httpClient.Timeout != DefaultTimeout {
+		t.Errorf("timeout = %v, want %v", client.httpClient.Timeout, DefaultTimeout)
+	}
+	if client.httpClient.Transport != nil {
+		t.Errorf("transport = %T, want the default transport", client.httpClient.Transport)
+	}
+}
+
+func TestOptions_BaseURLAndUserAgent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/weather" {
+			t.Errorf("path = %q, want /weather", r.URL.Path)
+		}
+		if got := r.Header.Get("User-Agent"); got != "weather-test/1.0" {
+			t.Errorf("User-Agent = %q, want weather-test/1.0", got)
+		}
+		w.Write([]byte(londonResponse))
+	}))
+	defer server.Close()
+
+	client := NewWeatherClient("test-key", WithBaseURL(server.URL+"/"), WithUserAgent("weather-test/1.0"))
+
+	if _, err := client.GetWeather(context.Background(), domain.CityQuery("London")); err != nil {
+		t.Errorf("GetWeather() unexpected error: %v", err)
+	}
+}
+
+func TestOptions_Timeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := NewWeatherClient("test-key", WithBaseURL(server.URL), WithTimeout(20*time.Millisecond), WithRetryPolicy(NoRetry()))
+
+	start := time.Now()
+	if _, err := client.GetWeather(context.Background(), domain.CityQuery("London")); !errors.IsExternalService(err) {
+		t.Errorf("GetWeather() error = %v, want external service error", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("GetWeather() took %v, want the 20ms timeout to apply", elapsed)
+	}
+}
+
+func TestOptions_MaxIdleConns(t *testing.T) {
+	client := NewWeatherClient("test-key", WithMaxIdleConns(7)).(*WeatherClient)
+
+	transport, ok := client.httpClient.Transport.(*http.Transport)
+	if !ok || transport.MaxIdleConns != 7 || transport.MaxIdleConnsPerHost != 7 {
+		t.Fatalf("transport = %+v, want 7 idle connections", client.httpClient.Transport)
+	}
+	if transport == http.DefaultTransport {
+		t.Errorf("WithMaxIdleConns() modified the shared default transport")
+	}
+
+	custom := &http.Transport{}
+	client = NewWeatherClient("test-key", WithTransport(custom), WithMaxIdleConns(3)).(*WeatherClient)
+	if tuned := client.httpClient.Transport.(*http.Transport); tuned.MaxIdleConns != 3 || custom.MaxIdleConns != 0 {
+		t.Errorf("WithMaxIdleConns() should tune a copy of a custom transport")
+	}
+}
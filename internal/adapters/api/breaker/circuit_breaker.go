@@ -0,0 +1,269 @@
+package breaker
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/leinonen/hexagonal-architecture-go/internal/adapters/api/limit"
+	"github.com/leinonen/hexagonal-architecture-go/internal/domain"
+	"github.com/leinonen/hexagonal-architecture-go/internal/errors"
+	"github.com/leinonen/hexagonal-architecture-go/internal/ports"
+)
+
+type State int
+
+const (
+	Closed State = iota
+	HalfOpen
+	Open
+)
+
+func (s State) String() string {
+	switch s {
+	case Closed:
+		return "closed"
+	case HalfOpen:
+		return "half-open"
+	case Open:
+		return "open"
+	default:
+		return "unknown"
+	}
+}
+
+type Config struct {
+	// Name identifies the protected provider in errors and health reports.
+	Name string
+	// WindowSize is how many recent outcomes the failure rate is computed over.
+	WindowSize int
+	// MinRequests is how many outcomes the window needs before it can trip.
+	MinRequests int
+	// FailureRate between 0 and 1 at or above which the circuit opens.
+	FailureRate float64
+	// Cooldown is how long the circuit stays open before letting probes through.
+	Cooldown time.Duration
+	// HalfOpenRequests is how many probes may run at once while half-open.
+	HalfOpenRequests int
+}
+
+func DefaultConfig() Config {
+	return Config{
+		Name:             "weather",
+		WindowSize:       20,
+		MinRequests:      10,
+		FailureRate:      0.5,
+		Cooldown:         30 * time.Second,
+		HalfOpenRequests: 1,
+	}
+}
+
+// CircuitBreaker is a ports.WeatherService decorator that stops calling a
+// provider which keeps failing. While open, lookups fail immediately with an
+// ExternalServiceError instead of waiting for the provider to time out.
+//
+// Only provider failures count against the circuit; answers such as "city not
+// found" show the provider is working. A provider that rejects our credentials
+// is reported down until it answers again, since only an operator can fix it.
+type CircuitBreaker struct {
+	next   ports.WeatherService
+	config Config
+
+	mu       sync.Mutex
+	state    State
+	outcomes []bool
+	cursor   int
+	filled   int
+	failures int
+	openedAt time.Time
+	probes   int
+	// misconfigured is the last credentials error, cleared by any answer.
+	misconfigured error
+}
+
+func NewCircuitBreaker(next ports.WeatherService, config Config) *CircuitBreaker {
+	defaults := DefaultConfig()
+	if config.Name == "" {
+		config.Name = defaults.Name
+	}
+	if config.WindowSize <= 0 {
+		config.WindowSize = defaults.WindowSize
+	}
+	if config.MinRequests <= 0 || config.MinRequests > config.WindowSize {
+		config.MinRequests = min(defaults.MinRequests, config.WindowSize)
+	}
+	if config.FailureRate <= 0 || config.FailureRate > 1 {
+		config.FailureRate = defaults.FailureRate
+	}
+	if config.Cooldown <= 0 {
+		config.Cooldown = defaults.Cooldown
+	}
+	if config.HalfOpenRequests <= 0 {
+		config.HalfOpenRequests = defaults.HalfOpenRequests
+	}
+
+	return &CircuitBreaker{
+		next:     next,
+		config:   config,
+		outcomes: make([]bool, config.WindowSize),
+	}
+}
+
+func (b *CircuitBreaker) GetWeather(ctx context.Context, query domain.WeatherQuery) (*domain.Weather, error) {
+	probe, err := b.allow()
+	if err != nil {
+		return nil, err
+	}
+
+	weather, err := b.next.GetWeather(ctx, query)
+	b.record(probe, err, ctx.Err() != nil)
+	return weather, err
+}
+
+func (b *CircuitBreaker) GetForecast(ctx context.Context, query domain.WeatherQuery, days int) (*domain.Forecast, error) {
+	probe, err := b.allow()
+	if err != nil {
+		return nil, err
+	}
+
+	forecast, err := b.next.GetForecast(ctx, query, days)
+	b.record(probe, err, ctx.Err() != nil)
+	return forecast, err
+}
+
+func (b *CircuitBreaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.advance(time.Now())
+	return b.state
+}
+
+func (b *CircuitBreaker) CheckHealth(ctx context.Context) domain.ComponentHealth {
+	b.mu.Lock()
+	b.advance(time.Now())
+	state, misconfigured := b.state, b.misconfigured
+	b.mu.Unlock()
+
+	health := domain.ComponentHealth{
+		Name:   b.config.Name,
+		Status: domain.HealthUp,
+		Detail: "circuit " + state.String(),
+	}
+	switch {
+	case misconfigured != nil:
+		health.Status = domain.HealthDown
+		health.Detail = "misconfigured: " + misconfigured.Error()
+	case state != Closed:
+		health.Status = domain.HealthDegraded
+	}
+	return health
+}
+
+// allow decides whether a call may go through and whether it is a half-open
+// probe.
+func (b *CircuitBreaker) allow() (bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.advance(time.Now())
+
+	switch b.state {
+	case Open:
+		return false, b.openError()
+	case HalfOpen:
+		if b.probes >= b.config.HalfOpenRequests {
+			return false, b.openError()
+		}
+		b.probes++
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+// record feeds the outcome of a call back into the circuit. Calls abandoned
+// by their caller, or refused by our own rate limit before reaching the
+// provider, say nothing about the provider and are ignored.
+func (b *CircuitBreaker) record(probe bool, err error, canceled bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if probe {
+		b.probes--
+	}
+	if canceled || stderrors.Is(err, limit.ErrBudgetExhausted) {
+		return
+	}
+
+	failed := errors.IsUpstreamFailure(err)
+
+	switch {
+	case errors.IsMisconfiguration(err):
+		if b.misconfigured == nil {
+			log.Printf("ERROR: %s provider rejected our credentials, check its API key: %v", b.config.Name, err)
+		}
+		b.misconfigured = err
+	case !failed:
+		b.misconfigured = nil
+	}
+
+	if probe {
+		if failed {
+			b.trip(time.Now())
+		} else if b.state == HalfOpen {
+			b.reset()
+		}
+		return
+	}
+
+	if b.state != Closed {
+		return
+	}
+
+	b.observe(failed)
+	if b.filled >= b.config.MinRequests && float64(b.failures)/float64(b.filled) >= b.config.FailureRate {
+		b.trip(time.Now())
+	}
+}
+
+// advance moves an open circuit to half-open once the cooldown has passed.
+func (b *CircuitBreaker) advance(now time.Time) {
+	if b.state == Open && now.Sub(b.openedAt) >= b.config.Cooldown {
+		b.state = HalfOpen
+	}
+}
+
+func (b *CircuitBreaker) observe(failed bool) {
+	if b.filled == len(b.outcomes) && b.outcomes[b.cursor] {
+		b.failures--
+	}
+	b.outcomes[b.cursor] = failed
+	if failed {
+		b.failures++
+	}
+	b.cursor = (b.cursor + 1) % len(b.outcomes)
+	if b.filled < len(b.outcomes) {
+		b.filled++
+	}
+}
+
+func (b *CircuitBreaker) trip(now time.Time) {
+	b.state = Open
+	b.openedAt = now
+}
+
+func (b *CircuitBreaker) reset() {
+	b.state = Closed
+	b.filled = 0
+	b.failures = 0
+	b.cursor = 0
+	clear(b.outcomes)
+}
+
+func (b *CircuitBreaker) openError() error {
+	return errors.NewExternalServiceError(fmt.Sprintf("%s provider unavailable: circuit open", b.config.Name))
+}
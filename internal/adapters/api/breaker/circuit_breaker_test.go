@@ -0,0 +1,213 @@
+package breaker_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/leinonen/hexagonal-architecture-go/internal/adapters/api/breaker"
+	"github.com/leinonen/hexagonal-architecture-go/internal/adapters/api/limit"
+	"github.com/leinonen/hexagonal-architecture-go/internal/domain"
+	"github.com/leinonen/hexagonal-architecture-go/internal/errors"
+)
+
+type switchableWeatherService struct {
+	failing atomic.Bool
+	calls   atomic.Int32
+}
+
+func (s *switchableWeatherService) GetWeather(ctx context.Context, query domain.WeatherQuery) (*domain.Weather, error) {
+	s.calls.Add(1)
+	if query.City == "Atlantis" {
+		return nil, errors.NewNotFoundError("city not found")
+	}
+	if query.City == "Revoked" {
+		return nil, errors.NewMisconfigurationError("weather API rejected our credentials with status: 401")
+	}
+	if s.failing.Load() {
+		return nil, errors.NewExternalServiceError("weather API returned status: 503")
+	}
+	return &domain.Weather{City: query.City}, nil
+}
+
+func (s *switchableWeatherService) GetForecast(ctx context.Context, query domain.WeatherQuery, days int) (*domain.Forecast, error) {
+	s.calls.Add(1)
+	if s.failing.Load() {
+		return nil, errors.NewExternalServiceError("weather API returned status: 503")
+	}
+	return &domain.Forecast{City: query.City}, nil
+}
+
+func testConfig() breaker.Config {
+	return breaker.Config{
+		Name:        "test",
+		WindowSize:  4,
+		MinRequests: 4,
+		FailureRate: 0.5,
+		Cooldown:    30 * time.Millisecond,
+	}
+}
+
+func TestCircuitBreaker_OpensOnFailureRate(t *testing.T) {
+	upstream := &switchableWeatherService{}
+	circuit := breaker.NewCircuitBreaker(upstream, testConfig())
+	ctx := context.Background()
+
+	circuit.GetWeather(ctx, domain.CityQuery("London"))
+	circuit.GetWeather(ctx, domain.CityQuery("London"))
+
+	upstream.failing.Store(true)
+	circuit.GetWeather(ctx, domain.CityQuery("London"))
+	if circuit.State() != breaker.Closed {
+		t.Fatalf("State() = %v before the window is full, want closed", circuit.State())
+	}
+
+	circuit.GetWeather(ctx, domain.CityQuery("London"))
+	if circuit.State() != breaker.Open {
+		t.Fatalf("State() = %v at 50%% failures, want open", circuit.State())
+	}
+
+	calls := upstream.calls.Load()
+	start := time.Now()
+	_, err := circuit.GetWeather(ctx, domain.CityQuery("London"))
+	if !errors.IsExternalService(err) {
+		t.Errorf("GetWeather() while open error = %v, want external service error", err)
+	}
+	if upstream.calls.Load() != calls {
+		t.Errorf("GetWeather() while open reached the provider")
+	}
+	if time.Since(start) > 10*time.Millisecond {
+		t.Errorf("GetWeather() while open should fail fast")
+	}
+
+	if health := circuit.CheckHealth(ctx); health.Status != domain.HealthDegraded || health.Name != "test" {
+		t.Errorf("CheckHealth() while open = %+v, want degraded", health)
+	}
+}
+
+func TestCircuitBreaker_NotFoundIsNotAFailure(t *testing.T) {
+	upstream := &switchableWeatherService{}
+	circuit := breaker.NewCircuitBreaker(upstream, testConfig())
+	ctx := context.Background()
+
+	for i := 0; i < 10; i++ {
+		circuit.GetWeather(ctx, domain.CityQuery("Atlantis"))
+	}
+
+	if circuit.State() != breaker.Closed {
+		t.Errorf("State() = %v after not found answers, want closed", circuit.State())
+	}
+}
+
+func TestCircuitBreaker_HalfOpen(t *testing.T) {
+	upstream := &switchableWeatherService{}
+	circuit := breaker.NewCircuitBreaker(upstream, testConfig())
+	ctx := context.Background()
+
+	upstream.failing.Store(true)
+	for i := 0; i < 4; i++ {
+		circuit.GetWeather(ctx, domain.CityQuery("London"))
+	}
+
+	time.Sleep(40 * time.Millisecond)
+	if circuit.State() != breaker.HalfOpen {
+		t.Fatalf("State() = %v after cooldown, want half-open", circuit.State())
+	}
+
+	circuit.GetWeather(ctx, domain.CityQuery("London"))
+	if circuit.State() != breaker.Open {
+		t.Fatalf("State() = %v after failed probe, want open", circuit.State())
+	}
+
+	time.Sleep(40 * time.Millisecond)
+	upstream.failing.Store(false)
+
+	if _, err := circuit.GetWeather(ctx, domain.CityQuery("London")); err != nil {
+		t.Fatalf("GetWeather() probe unexpected error: %v", err)
+	}
+	if circuit.State() != breaker.Closed {
+		t.Errorf("State() = %v after successful probe, want closed", circuit.State())
+	}
+	if health := circuit.CheckHealth(ctx); health.Status != domain.HealthUp {
+		t.Errorf("CheckHealth() when closed = %+v, want up", health)
+	}
+}
+
+func TestCircuitBreaker_CanceledCallsIgnored(t *testing.T) {
+	upstream := &switchableWeatherService{}
+	upstream.failing.Store(true)
+	circuit := breaker.NewCircuitBreaker(upstream, testConfig())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for i := 0; i < 10; i++ {
+		circuit.GetWeather(ctx, domain.CityQuery("London"))
+	}
+
+	if circuit.State() != breaker.Closed {
+		t.Errorf("State() = %v after cancelled calls, want closed", circuit.State())
+	}
+}
+
+func TestCircuitBreaker_LocalRateLimitIgnored(t *testing.T) {
+	upstream := &switchableWeatherService{}
+	budget := limit.NewBudget(limit.Config{Rate: 0.001, Burst: 1})
+	circuit := breaker.NewCircuitBreaker(budgeted{upstream, budget}, testConfig())
+	ctx := context.Background()
+
+	for i := 0; i < 10; i++ {
+		circuit.GetWeather(ctx, domain.CityQuery("London"))
+	}
+
+	if circuit.State() != breaker.Closed || upstream.calls.Load() != 1 {
+		t.Errorf("State() = %v after %d upstream calls, want closed after the one call the budget allowed", circuit.State(), upstream.calls.Load())
+	}
+}
+
+// budgeted spends from a budget before each call, as the provider clients do.
+type budgeted struct {
+	*switchableWeatherService
+	budget *limit.Budget
+}
+
+func (s budgeted) GetWeather(ctx context.Context, query domain.WeatherQuery) (*domain.Weather, error) {
+	if err := s.budget.Wait(ctx); err != nil {
+		return nil, err
+	}
+	return s.switchableWeatherService.GetWeather(ctx, query)
+}
+
+func TestCircuitBreaker_ForecastSharesCircuit(t *testing.T) {
+	upstream := &switchableWeatherService{}
+	upstream.failing.Store(true)
+	circuit := breaker.NewCircuitBreaker(upstream, testConfig())
+	ctx := context.Background()
+
+	for i := 0; i < 4; i++ {
+		circuit.GetForecast(ctx, domain.CityQuery("London"), 3)
+	}
+
+	if _, err := circuit.GetWeather(ctx, domain.CityQuery("London")); !errors.IsExternalService(err) || upstream.calls.Load() != 4 {
+		t.Errorf("GetWeather() after failing forecasts should fail fast, error = %v", err)
+	}
+}
+
+func TestCircuitBreaker_MisconfigurationReportedDown(t *testing.T) {
+	upstream := &switchableWeatherService{}
+	circuit := breaker.NewCircuitBreaker(upstream, testConfig())
+	ctx := context.Background()
+
+	if _, err := circuit.GetWeather(ctx, domain.CityQuery("Revoked")); !errors.IsMisconfiguration(err) {
+		t.Fatalf("GetWeather() error = %v, want misconfiguration error", err)
+	}
+	if health := circuit.CheckHealth(ctx); health.Status != domain.HealthDown {
+		t.Errorf("CheckHealth() = %+v, want down after credentials were rejected", health)
+	}
+
+	circuit.GetWeather(ctx, domain.CityQuery("London"))
+	if health := circuit.CheckHealth(ctx); health.Status != domain.HealthUp {
+		t.Errorf("CheckHealth() = %+v, want up once the provider answers again", health)
+	}
+}
@@ -0,0 +1,209 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/leinonen/hexagonal-architecture-go/internal/domain"
+	"github.com/leinonen/hexagonal-architecture-go/internal/errors"
+	"github.com/leinonen/hexagonal-architecture-go/internal/ports"
+)
+
+const (
+	DefaultTTL         = 5 * time.Minute
+	DefaultNegativeTTL = time.Minute
+	DefaultMaxEntries  = 1000
+)
+
+type Config struct {
+	TTL time.Duration
+	// NegativeTTL is how long a "city not found" answer is remembered. Zero
+	// disables negative caching.
+	NegativeTTL time.Duration
+	MaxEntries  int
+}
+
+type Stats struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+	Entries   int
+}
+
+// WeatherCache is a ports.WeatherService decorator that keeps recent answers
+// in a size-bounded LRU so identical lookups within the TTL do not reach the
+// provider.
+type WeatherCache struct {
+	next   ports.WeatherService
+	config Config
+
+	mu        sync.Mutex
+	entries   map[string]*list.Element
+	lru       *list.List
+	hits      uint64
+	misses    uint64
+	evictions uint64
+}
+
+type entry struct {
+	key       string
+	weather   *domain.Weather
+	forecast  *domain.Forecast
+	err       error
+	expiresAt time.Time
+}
+
+func NewWeatherCache(next ports.WeatherService, config Config) *WeatherCache {
+	if config.TTL <= 0 {
+		config.TTL = DefaultTTL
+	}
+	if config.NegativeTTL < 0 {
+		config.NegativeTTL = 0
+	}
+	if config.MaxEntries <= 0 {
+		config.MaxEntries = DefaultMaxEntries
+	}
+
+	return &WeatherCache{
+		next:    next,
+		config:  config,
+		entries: make(map[string]*list.Element),
+		lru:     list.New(),
+	}
+}
+
+func (c *WeatherCache) GetWeather(ctx context.Context, query domain.WeatherQuery) (*domain.Weather, error) {
+	key := "weather:" + query.Key()
+
+	if cached, ok := c.lookup(key); ok {
+		return cached.weatherResult()
+	}
+
+	weather, err := c.next.GetWeather(ctx, query)
+	if err != nil {
+		c.storeError(key, err)
+		return nil, err
+	}
+
+	stored := *weather
+	stored.FetchedAt, stored.ExpiresAt = c.lifetime(stored.FetchedAt)
+
+	cached := &entry{key: key, weather: &stored, expiresAt: stored.ExpiresAt}
+	c.store(cached)
+
+	return cached.weatherResult()
+}
+
+func (c *WeatherCache) GetForecast(ctx context.Context, query domain.WeatherQuery, days int) (*domain.Forecast, error) {
+	key := fmt.Sprintf("forecast:%d:%s", days, query.Key())
+
+	if cached, ok := c.lookup(key); ok {
+		return cached.forecastResult()
+	}
+
+	forecast, err := c.next.GetForecast(ctx, query, days)
+	if err != nil {
+		c.storeError(key, err)
+		return nil, err
+	}
+
+	stored := *forecast
+	stored.FetchedAt, stored.ExpiresAt = c.lifetime(stored.FetchedAt)
+
+	cached := &entry{key: key, forecast: &stored, expiresAt: stored.ExpiresAt}
+	c.store(cached)
+
+	return cached.forecastResult()
+}
+
+func (c *WeatherCache) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return Stats{
+		Hits:      c.hits,
+		Misses:    c.misses,
+		Evictions: c.evictions,
+		Entries:   c.lru.Len(),
+	}
+}
+
+func (c *WeatherCache) lookup(key string) (*entry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, exists := c.entries[key]
+	if !exists {
+		c.misses++
+		return nil, false
+	}
+
+	cached := element.Value.(*entry)
+	if !time.Now().Before(cached.expiresAt) {
+		c.lru.Remove(element)
+		delete(c.entries, key)
+		c.misses++
+		return nil, false
+	}
+
+	c.lru.MoveToFront(element)
+	c.hits++
+	return cached, true
+}
+
+func (c *WeatherCache) lifetime(fetchedAt time.Time) (time.Time, time.Time) {
+	if fetchedAt.IsZero() {
+		fetchedAt = time.Now()
+	}
+	return fetchedAt, fetchedAt.Add(c.config.TTL)
+}
+
+// storeError remembers "not found" answers; other errors are never cached.
+func (c *WeatherCache) storeError(key string, err error) {
+	if errors.IsNotFound(err) && c.config.NegativeTTL > 0 {
+		c.store(&entry{key: key, err: err, expiresAt: time.Now().Add(c.config.NegativeTTL)})
+	}
+}
+
+func (c *WeatherCache) store(cached *entry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, exists := c.entries[cached.key]; exists {
+		element.Value = cached
+		c.lru.MoveToFront(element)
+		return
+	}
+
+	c.entries[cached.key] = c.lru.PushFront(cached)
+
+	for c.lru.Len() > c.config.MaxEntries {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*entry).key)
+		c.evictions++
+	}
+}
+
+// weatherResult and forecastResult hand out copies so callers cannot modify
+// the cached value.
+func (e *entry) weatherResult() (*domain.Weather, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	weather := *e.weather
+	return &weather, nil
+}
+
+func (e *entry) forecastResult() (*domain.Forecast, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	forecast := *e.forecast
+	forecast.Hourly = append([]domain.HourlyForecast(nil), e.forecast.Hourly...)
+	forecast.Daily = append([]domain.DailyForecast(nil), e.forecast.Daily...)
+	return &forecast, nil
+}
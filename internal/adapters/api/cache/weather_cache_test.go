@@ -0,0 +1,178 @@
+package cache_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/leinonen/hexagonal-architecture-go/internal/adapters/api/cache"
+	"github.com/leinonen/hexagonal-architecture-go/internal/domain"
+	"github.com/leinonen/hexagonal-architecture-go/internal/errors"
+)
+
+type countingWeatherService struct {
+	mu    sync.Mutex
+	calls map[string]int
+}
+
+func newCountingWeatherService() *countingWeatherService {
+	return &countingWeatherService{calls: make(map[string]int)}
+}
+
+func (s *countingWeatherService) GetWeather(ctx context.Context, query domain.WeatherQuery) (*domain.Weather, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.calls[query.City]++
+	if query.City == "Atlantis" {
+		return nil, errors.NewNotFoundError("city not found")
+	}
+	if query.City == "Broken" {
+		return nil, errors.NewExternalServiceError("weather API returned status: 500")
+	}
+	return &domain.Weather{City: query.City, Temperature: 20}, nil
+}
+
+func (s *countingWeatherService) GetForecast(ctx context.Context, query domain.WeatherQuery, days int) (*domain.Forecast, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.calls["forecast:"+query.City]++
+	if query.City == "Atlantis" {
+		return nil, errors.NewNotFoundError("city not found")
+	}
+	return &domain.Forecast{City: query.City, Daily: make([]domain.DailyForecast, days)}, nil
+}
+
+func (s *countingWeatherService) callCount(city string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls[city]
+}
+
+func TestWeatherCache_HitsAndMisses(t *testing.T) {
+	upstream := newCountingWeatherService()
+	weatherCache := cache.NewWeatherCache(upstream, cache.Config{TTL: time.Minute})
+	ctx := context.Background()
+
+	first, err := weatherCache.GetWeather(ctx, domain.CityQuery("London"))
+	if err != nil {
+		t.Fatalf("GetWeather() unexpected error: %v", err)
+	}
+	if first.ExpiresAt.Sub(first.FetchedAt) != time.Minute {
+		t.Errorf("GetWeather() expiry = %v after fetch, want 1m", first.ExpiresAt.Sub(first.FetchedAt))
+	}
+
+	first.Temperature = -100
+
+	for _, city := range []string{"London", " london ", "LONDON"} {
+		weather, err := weatherCache.GetWeather(ctx, domain.CityQuery(city))
+		if err != nil {
+			t.Fatalf("GetWeather(%q) unexpected error: %v", city, err)
+		}
+		if weather.Temperature != 20 {
+			t.Errorf("GetWeather(%q) temperature = %v, cached value was modified", city, weather.Temperature)
+		}
+	}
+
+	if calls := upstream.callCount("London"); calls != 1 {
+		t.Errorf("upstream called %d times, want 1", calls)
+	}
+
+	stats := weatherCache.Stats()
+	if stats.Hits != 3 || stats.Misses != 1 || stats.Entries != 1 {
+		t.Errorf("Stats() = %+v, want 3 hits, 1 miss, 1 entry", stats)
+	}
+}
+
+func TestWeatherCache_Expiry(t *testing.T) {
+	upstream := newCountingWeatherService()
+	weatherCache := cache.NewWeatherCache(upstream, cache.Config{TTL: 20 * time.Millisecond})
+	ctx := context.Background()
+
+	weatherCache.GetWeather(ctx, domain.CityQuery("London"))
+	time.Sleep(40 * time.Millisecond)
+	weatherCache.GetWeather(ctx, domain.CityQuery("London"))
+
+	if calls := upstream.callCount("London"); calls != 2 {
+		t.Errorf("upstream called %d times after expiry, want 2", calls)
+	}
+}
+
+func TestWeatherCache_Errors(t *testing.T) {
+	upstream := newCountingWeatherService()
+	weatherCache := cache.NewWeatherCache(upstream, cache.Config{TTL: time.Minute, NegativeTTL: time.Minute})
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if _, err := weatherCache.GetWeather(ctx, domain.CityQuery("Atlantis")); !errors.IsNotFound(err) {
+			t.Fatalf("GetWeather() error = %v, want not found", err)
+		}
+		if _, err := weatherCache.GetWeather(ctx, domain.CityQuery("Broken")); !errors.IsExternalService(err) {
+			t.Fatalf("GetWeather() error = %v, want external service error", err)
+		}
+	}
+
+	if calls := upstream.callCount("Atlantis"); calls != 1 {
+		t.Errorf("not found answer fetched %d times, want 1", calls)
+	}
+	if calls := upstream.callCount("Broken"); calls != 3 {
+		t.Errorf("failing lookup fetched %d times, want 3 (errors must not be cached)", calls)
+	}
+}
+
+func TestWeatherCache_Eviction(t *testing.T) {
+	upstream := newCountingWeatherService()
+	weatherCache := cache.NewWeatherCache(upstream, cache.Config{TTL: time.Minute, MaxEntries: 2})
+	ctx := context.Background()
+
+	weatherCache.GetWeather(ctx, domain.CityQuery("London"))
+	weatherCache.GetWeather(ctx, domain.CityQuery("Paris"))
+	weatherCache.GetWeather(ctx, domain.CityQuery("London"))
+	weatherCache.GetWeather(ctx, domain.CityQuery("Berlin"))
+
+	weatherCache.GetWeather(ctx, domain.CityQuery("London"))
+	weatherCache.GetWeather(ctx, domain.CityQuery("Paris"))
+
+	if calls := upstream.callCount("London"); calls != 1 {
+		t.Errorf("recently used entry fetched %d times, want 1", calls)
+	}
+	if calls := upstream.callCount("Paris"); calls != 2 {
+		t.Errorf("least recently used entry fetched %d times, want 2", calls)
+	}
+
+	if stats := weatherCache.Stats(); stats.Entries != 2 || stats.Evictions != 2 {
+		t.Errorf("Stats() = %+v, want 2 entries and 2 evictions", stats)
+	}
+}
+
+func TestWeatherCache_Forecast(t *testing.T) {
+	upstream := newCountingWeatherService()
+	weatherCache := cache.NewWeatherCache(upstream, cache.Config{TTL: time.Minute})
+	ctx := context.Background()
+
+	weatherCache.GetWeather(ctx, domain.CityQuery("London"))
+	first, _ := weatherCache.GetForecast(ctx, domain.CityQuery("London"), 3)
+	first.Daily[0].MaxTemperature = 99
+
+	cached, err := weatherCache.GetForecast(ctx, domain.CityQuery("london"), 3)
+	if err != nil {
+		t.Fatalf("GetForecast() unexpected error: %v", err)
+	}
+	if len(cached.Daily) != 3 || cached.Daily[0].MaxTemperature != 0 {
+		t.Errorf("GetForecast() cached value was modified: %+v", cached.Daily)
+	}
+	if cached.ExpiresAt.IsZero() {
+		t.Errorf("GetForecast() should report when the cached forecast expires")
+	}
+
+	weatherCache.GetForecast(ctx, domain.CityQuery("London"), 5)
+
+	if calls := upstream.callCount("forecast:London"); calls != 2 {
+		t.Errorf("forecast fetched %d times, want 2 (one per day count)", calls)
+	}
+	if calls := upstream.callCount("London"); calls != 1 {
+		t.Errorf("current weather fetched %d times, want 1", calls)
+	}
+}
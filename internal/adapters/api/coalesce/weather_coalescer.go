@@ -0,0 +1,130 @@
+package coalesce
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/leinonen/hexagonal-architecture-go/internal/domain"
+	"github.com/leinonen/hexagonal-architecture-go/internal/errors"
+	"github.com/leinonen/hexagonal-architecture-go/internal/ports"
+)
+
+// WeatherCoalescer is a ports.WeatherService decorator that lets concurrent
+// lookups for the same city share a single upstream request.
+//
+// The shared request runs detached from any one caller's context. Each caller
+// stops waiting when its own context ends, and the upstream request is only
+// cancelled once every caller waiting on it has given up.
+type WeatherCoalescer struct {
+	next ports.WeatherService
+
+	mu    sync.Mutex
+	calls map[string]*call
+}
+
+type call struct {
+	done    chan struct{}
+	cancel  context.CancelFunc
+	waiters int
+
+	value any
+	err   error
+}
+
+func NewWeatherCoalescer(next ports.WeatherService) ports.WeatherService {
+	return &WeatherCoalescer{
+		next:  next,
+		calls: make(map[string]*call),
+	}
+}
+
+func (c *WeatherCoalescer) GetWeather(ctx context.Context, query domain.WeatherQuery) (*domain.Weather, error) {
+	value, err := c.do(ctx, "weather:"+query.Key(), func(ctx context.Context) (any, error) {
+		return c.next.GetWeather(ctx, query)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	weather := *value.(*domain.Weather)
+	return &weather, nil
+}
+
+func (c *WeatherCoalescer) GetForecast(ctx context.Context, query domain.WeatherQuery, days int) (*domain.Forecast, error) {
+	value, err := c.do(ctx, fmt.Sprintf("forecast:%d:%s", days, query.Key()), func(ctx context.Context) (any, error) {
+		return c.next.GetForecast(ctx, query, days)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	forecast := *value.(*domain.Forecast)
+	forecast.Hourly = append([]domain.HourlyForecast(nil), forecast.Hourly...)
+	forecast.Daily = append([]domain.DailyForecast(nil), forecast.Daily...)
+	return &forecast, nil
+}
+
+// do joins the in-flight call for key, starting one with fn if there is none,
+// and waits for its result or for ctx to end.
+func (c *WeatherCoalescer) do(ctx context.Context, key string, fn func(context.Context) (any, error)) (any, error) {
+	c.mu.Lock()
+	inflight, exists := c.calls[key]
+	if !exists {
+		callCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		inflight = &call{done: make(chan struct{}), cancel: cancel}
+		c.calls[key] = inflight
+		go c.run(callCtx, key, fn, inflight)
+	}
+	inflight.waiters++
+	c.mu.Unlock()
+
+	select {
+	case <-inflight.done:
+		return inflight.value, inflight.err
+	case <-ctx.Done():
+		c.leave(key, inflight)
+		return nil, abandonedError(ctx)
+	}
+}
+
+// abandonedError reports a caller that stopped waiting for the shared request,
+// wrapping the context's error so callers can still tell why.
+func abandonedError(ctx context.Context) error {
+	return &errors.AppError{
+		Type:    errors.ExternalService,
+		Message: "weather lookup did not finish in time",
+		Err:     ctx.Err(),
+	}
+}
+
+func (c *WeatherCoalescer) run(ctx context.Context, key string, fn func(context.Context) (any, error), inflight *call) {
+	value, err := fn(ctx)
+
+	c.mu.Lock()
+	if c.calls[key] == inflight {
+		delete(c.calls, key)
+	}
+	c.mu.Unlock()
+
+	inflight.value, inflight.err = value, err
+	inflight.cancel()
+	close(inflight.done)
+}
+
+// leave drops a caller that stopped waiting and cancels the upstream request
+// when nobody is left to receive its result.
+func (c *WeatherCoalescer) leave(key string, inflight *call) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	inflight.waiters--
+	if inflight.waiters > 0 {
+		return
+	}
+
+	if c.calls[key] == inflight {
+		delete(c.calls, key)
+	}
+	inflight.cancel()
+}
@@ -0,0 +1,182 @@
+package coalesce_test
+
+import (
+	"context"
+	stderrors "errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/leinonen/hexagonal-architecture-go/internal/adapters/api/coalesce"
+	"github.com/leinonen/hexagonal-architecture-go/internal/domain"
+	"github.com/leinonen/hexagonal-architecture-go/internal/errors"
+)
+
+// blockingWeatherService holds every lookup until release is closed.
+type blockingWeatherService struct {
+	calls    atomic.Int32
+	started  chan struct{}
+	release  chan struct{}
+	canceled chan struct{}
+}
+
+func newBlockingWeatherService() *blockingWeatherService {
+	return &blockingWeatherService{
+		started:  make(chan struct{}, 100),
+		release:  make(chan struct{}),
+		canceled: make(chan struct{}, 100),
+	}
+}
+
+func (s *blockingWeatherService) GetWeather(ctx context.Context, query domain.WeatherQuery) (*domain.Weather, error) {
+	s.calls.Add(1)
+	s.started <- struct{}{}
+
+	select {
+	case <-s.release:
+		return &domain.Weather{City: query.City, Temperature: 12}, nil
+	case <-ctx.Done():
+		s.canceled <- struct{}{}
+		return nil, ctx.Err()
+	}
+}
+
+func (s *blockingWeatherService) GetForecast(ctx context.Context, query domain.WeatherQuery, days int) (*domain.Forecast, error) {
+	s.calls.Add(1)
+	s.started <- struct{}{}
+
+	select {
+	case <-s.release:
+		return &domain.Forecast{City: query.City, Daily: make([]domain.DailyForecast, days)}, nil
+	case <-ctx.Done():
+		s.canceled <- struct{}{}
+		return nil, ctx.Err()
+	}
+}
+
+func TestWeatherCoalescer_SharesInflightRequest(t *testing.T) {
+	upstream := newBlockingWeatherService()
+	coalescer := coalesce.NewWeatherCoalescer(upstream)
+
+	const callers = 20
+	var wg sync.WaitGroup
+	errs := make(chan error, callers)
+
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			weather, err := coalescer.GetWeather(context.Background(), domain.CityQuery("London"))
+			if err == nil && weather.Temperature != 12 {
+				t.Errorf("GetWeather() temperature = %v, want 12", weather.Temperature)
+			}
+			errs <- err
+		}()
+	}
+
+	<-upstream.started
+	time.Sleep(20 * time.Millisecond)
+	close(upstream.release)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("GetWeather() unexpected error: %v", err)
+		}
+	}
+
+	if calls := upstream.calls.Load(); calls != 1 {
+		t.Errorf("upstream called %d times, want 1", calls)
+	}
+}
+
+func TestWeatherCoalescer_CallerCancellation(t *testing.T) {
+	upstream := newBlockingWeatherService()
+	coalescer := coalesce.NewWeatherCoalescer(upstream)
+
+	impatient, cancel := context.WithCancel(context.Background())
+	impatientErr := make(chan error, 1)
+	go func() {
+		_, err := coalescer.GetWeather(impatient, domain.CityQuery("London"))
+		impatientErr <- err
+	}()
+	<-upstream.started
+
+	patientResult := make(chan error, 1)
+	go func() {
+		_, err := coalescer.GetWeather(context.Background(), domain.CityQuery("London"))
+		patientResult <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	cancel()
+	if err := <-impatientErr; !errors.IsExternalService(err) || !stderrors.Is(err, context.Canceled) {
+		t.Errorf("GetWeather() cancelled caller error = %v, want an external service error wrapping context.Canceled", err)
+	}
+
+	select {
+	case <-upstream.canceled:
+		t.Fatalf("upstream request cancelled while another caller was waiting")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(upstream.release)
+	if err := <-patientResult; err != nil {
+		t.Errorf("GetWeather() remaining caller error = %v, want success", err)
+	}
+
+	if calls := upstream.calls.Load(); calls != 1 {
+		t.Errorf("upstream called %d times, want 1", calls)
+	}
+}
+
+func TestWeatherCoalescer_CancelsWhenAllCallersLeave(t *testing.T) {
+	upstream := newBlockingWeatherService()
+	coalescer := coalesce.NewWeatherCoalescer(upstream)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		coalescer.GetWeather(ctx, domain.CityQuery("London"))
+		close(done)
+	}()
+
+	<-upstream.started
+	cancel()
+	<-done
+
+	select {
+	case <-upstream.canceled:
+	case <-time.After(time.Second):
+		t.Fatalf("upstream request not cancelled after every caller left")
+	}
+}
+
+func TestWeatherCoalescer_ForecastKeyedByDays(t *testing.T) {
+	upstream := newBlockingWeatherService()
+	coalescer := coalesce.NewWeatherCoalescer(upstream)
+
+	var wg sync.WaitGroup
+	for _, days := range []int{3, 3, 5} {
+		wg.Add(1)
+		go func(days int) {
+			defer wg.Done()
+			forecast, err := coalescer.GetForecast(context.Background(), domain.CityQuery("London"), days)
+			if err != nil || len(forecast.Daily) != days {
+				t.Errorf("GetForecast(%d) = %v, %v", days, forecast, err)
+			}
+		}(days)
+	}
+
+	<-upstream.started
+	<-upstream.started
+	time.Sleep(20 * time.Millisecond)
+	close(upstream.release)
+	wg.Wait()
+
+	if calls := upstream.calls.Load(); calls != 2 {
+		t.Errorf("upstream called %d times, want 2", calls)
+	}
+}
@@ -0,0 +1,291 @@
+package api
+
+import "strings"
+
+// countryCode maps a country name as WeatherAPI reports it, such as "United
+// Kingdom", to its ISO 3166 alpha-2 code. Names it does not know give "", so
+// that an unmapped name never ends up where a code is expected.
+func countryCode(name string) string {
+	return countryCodes[strings.ToLower(strings.TrimSpace(name))]
+}
+
+// countryCodes is keyed by lower-case ISO 3166 short names, plus the other
+// spellings WeatherAPI uses.
+var countryCodes = map[string]string{
+	"afghanistan":                       "AF",
+	"albania":                           "AL",
+	"algeria":                           "DZ",
+	"american samoa":                    "AS",
+	"andorra":                           "AD",
+	"angola":                            "AO",
+	"anguilla":                          "AI",
+	"antarctica":                        "AQ",
+	"antigua and barbuda":               "AG",
+	"argentina":                         "AR",
+	"armenia":                           "AM",
+	"aruba":                             "AW",
+	"australia":                         "AU",
+	"austria":                           "AT",
+	"azerbaijan":                        "AZ",
+	"bahamas":                           "BS",
+	"bahrain":                           "BH",
+	"bangladesh":                        "BD",
+	"barbados":                          "BB",
+	"belarus":                           "BY",
+	"belgium":                           "BE",
+	"belize":                            "BZ",
+	"benin":                             "BJ",
+	"bermuda":                           "BM",
+	"bhutan":                            "BT",
+	"bolivia":                           "BO",
+	"bosnia and herzegovina":            "BA",
+	"botswana":                          "BW",
+	"brazil":                            "BR",
+	"british virgin islands":            "VG",
+	"brunei":                            "BN",
+	"brunei darussalam":                 "BN",
+	"bulgaria":                          "BG",
+	"burkina faso":                      "BF",
+	"burundi":                           "BI",
+	"cambodia":                          "KH",
+	"cameroon":                          "CM",
+	"canada":                            "CA",
+	"cape verde":                        "CV",
+	"cabo verde":                        "CV",
+	"cayman islands":                    "KY",
+	"central african republic":          "CF",
+	"chad":                              "TD",
+	"chile":                             "CL",
+	"china":                             "CN",
+	"colombia":                          "CO",
+	"comoros":                           "KM",
+	"congo":                             "CG",
+	"democratic republic of congo":      "CD",
+	"democratic republic of the congo":  "CD",
+	"cook islands":                      "CK",
+	"costa rica":                        "CR",
+	"cote d'ivoire":                     "CI",
+	"côte d'ivoire":                     "CI",
+	"ivory coast":                       "CI",
+	"croatia":                           "HR",
+	"cuba":                              "CU",
+	"curaçao":                           "CW",
+	"curacao":                           "CW",
+	"cyprus":                            "CY",
+	"czech republic":                    "CZ",
+	"czechia":                           "CZ",
+	"denmark":                           "DK",
+	"djibouti":                          "DJ",
+	"dominica":                          "DM",
+	"dominican republic":                "DO",
+	"east timor":                        "TL",
+	"timor-leste":                       "TL",
+	"ecuador":                           "EC",
+	"egypt":                             "EG",
+	"el salvador":                       "SV",
+	"equatorial guinea":                 "GQ",
+	"eritrea":                           "ER",
+	"estonia":                           "EE",
+	"eswatini":                          "SZ",
+	"swaziland":                         "SZ",
+	"ethiopia":                          "ET",
+	"falkland islands":                  "FK",
+	"faroe islands":                     "FO",
+	"fiji":                              "FJ",
+	"finland":                           "FI",
+	"france":                            "FR",
+	"french guiana":                     "GF",
+	"french polynesia":                  "PF",
+	"gabon":                             "GA",
+	"gambia":                            "GM",
+	"georgia":                           "GE",
+	"germany":                           "DE",
+	"ghana":                             "GH",
+	"gibraltar":                         "GI",
+	"greece":                            "GR",
+	"greenland":                         "GL",
+	"grenada":                           "GD",
+	"guadeloupe":                        "GP",
+	"guam":                              "GU",
+	"guatemala":                         "GT",
+	"guernsey":                          "GG",
+	"guinea":                            "GN",
+	"guinea-bissau":                     "GW",
+	"guyana":                            "GY",
+	"haiti":                             "HT",
+	"honduras":                          "HN",
+	"hong kong":                         "HK",
+	"hungary":                           "HU",
+	"iceland":                           "IS",
+	"india":                             "IN",
+	"indonesia":                         "ID",
+	"iran":                              "IR",
+	"iraq":                              "IQ",
+	"ireland":                           "IE",
+	"isle of man":                       "IM",
+	"israel":                            "IL",
+	"italy":                             "IT",
+	"jamaica":                           "JM",
+	"japan":                             "JP",
+	"jersey":                            "JE",
+	"jordan":                            "JO",
+	"kazakhstan":                        "KZ",
+	"kenya":                             "KE",
+	"kiribati":                          "KI",
+	"kosovo":                            "XK",
+	"kuwait":                            "KW",
+	"kyrgyzstan":                        "KG",
+	"laos":                              "LA",
+	"lao people's democratic republic":  "LA",
+	"latvia":                            "LV",
+	"lebanon":                           "LB",
+	"lesotho":                           "LS",
+	"liberia":                           "LR",
+	"libya":                             "LY",
+	"liechtenstein":                     "LI",
+	"lithuania":                         "LT",
+	"luxembourg":                        "LU",
+	"macao":                             "MO",
+	"macau":                             "MO",
+	"macedonia":                         "MK",
+	"north macedonia":                   "MK",
+	"madagascar":                        "MG",
+	"malawi":                            "MW",
+	"malaysia":                          "MY",
+	"maldives":                          "MV",
+	"mali":                              "ML",
+	"malta":                             "MT",
+	"marshall islands":                  "MH",
+	"martinique":                        "MQ",
+	"mauritania":                        "MR",
+	"mauritius":                         "MU",
+	"mayotte":                           "YT",
+	"mexico":                            "MX",
+	"micronesia":                        "FM",
+	"moldova":                           "MD",
+	"monaco":                            "MC",
+	"mongolia":                          "MN",
+	"montenegro":                        "ME",
+	"montserrat":                        "MS",
+	"morocco":                           "MA",
+	"mozambique":                        "MZ",
+	"myanmar":                           "MM",
+	"burma":                             "MM",
+	"namibia":                           "NA",
+	"nauru":                             "NR",
+	"nepal":                             "NP",
+	"netherlands":                       "NL",
+	"new caledonia":                     "NC",
+	"new zealand":                       "NZ",
+	"nicaragua":                         "NI",
+	"niger":                             "NE",
+	"nigeria":                           "NG",
+	"north korea":                       "KP",
+	"northern mariana islands":          "MP",
+	"norway":                            "NO",
+	"oman":                              "OM",
+	"pakistan":                          "PK",
+	"palau":                             "PW",
+	"palestine":                         "PS",
+	"palestinian territory":             "PS",
+	"panama":                            "PA",
+	"papua new guinea":                  "PG",
+	"paraguay":                          "PY",
+	"peru":                              "PE",
+	"philippines":                       "PH",
+	"poland":                            "PL",
+	"portugal":                          "PT",
+	"puerto rico":                       "PR",
+	"qatar":                             "QA",
+	"reunion":                           "RE",
+	"réunion":                           "RE",
+	"romania":                           "RO",
+	"russia":                            "RU",
+	"russian federation":                "RU",
+	"rwanda":                            "RW",
+	"saint kitts and nevis":             "KN",
+	"saint lucia":                       "LC",
+	"saint vincent and the grenadines":  "VC",
+	"samoa":                             "WS",
+	"san marino":                        "SM",
+	"sao tome and principe":             "ST",
+	"saudi arabia":                      "SA",
+	"senegal":                           "SN",
+	"serbia":                            "RS",
+	"seychelles":                        "SC",
+	"sierra leone":                      "SL",
+	"singapore":                         "SG",
+	"slovakia":                          "SK",
+	"slovenia":                          "SI",
+	"solomon islands":                   "SB",
+	"somalia":                           "SO",
+	"south africa":                      "ZA",
+	"south korea":                       "KR",
+	"korea, republic of":                "KR",
+	"south sudan":                       "SS",
+	"spain":                             "ES",
+	"sri lanka":                         "LK",
+	"sudan":                             "SD",
+	"suriname":                          "SR",
+	"sweden":                            "SE",
+	"switzerland":                       "CH",
+	"syria":                             "SY",
+	"syrian arab republic":              "SY",
+	"taiwan":                            "TW",
+	"tajikistan":                        "TJ",
+	"tanzania":                          "TZ",
+	"thailand":                          "TH",
+	"togo":                              "TG",
+	"tonga":                             "TO",
+	"trinidad and tobago":               "TT",
+	"tunisia":                           "TN",
+	"turkey":                            "TR",
+	"türkiye":                           "TR",
+	"turkmenistan":                      "TM",
+	"turks and caicos islands":          "TC",
+	"tuvalu":                            "TV",
+	"uganda":                            "UG",
+	"ukraine":                           "UA",
+	"united arab emirates":              "AE",
+	"united kingdom":                    "GB",
+	"uk":                                "GB",
+	"united states of america":          "US",
+	"united states":                     "US",
+	"usa":                               "US",
+	"usa united states of america":      "US",
+	"united states virgin islands":      "VI",
+	"uruguay":                           "UY",
+	"uzbekistan":                        "UZ",
+	"vanuatu":                           "VU",
+	"vatican city":                      "VA",
+	"holy see":                          "VA",
+	"venezuela":                         "VE",
+	"vietnam":                           "VN",
+	"viet nam":                          "VN",
+	"western sahara":                    "EH",
+	"yemen":                             "YE",
+	"zambia":                            "ZM",
+	"zimbabwe":                          "ZW",
+	"bonaire, saint eustatius and saba": "BQ",
+	"sint maarten":                      "SX",
+	"saint martin":                      "MF",
+	"saint barthelemy":                  "BL",
+	"saint pierre and miquelon":         "PM",
+	"saint helena":                      "SH",
+	"wallis and futuna":                 "WF",
+	"svalbard and jan mayen":            "SJ",
+	"norfolk island":                    "NF",
+	"christmas island":                  "CX",
+	"cocos (keeling) islands":           "CC",
+	"niue":                              "NU",
+	"tokelau":                           "TK",
+	"pitcairn islands":                  "PN",
+	"aland islands":                     "AX",
+	"åland islands":                     "AX",
+	"british indian ocean territory":    "IO",
+	"french southern territories":       "TF",
+	"heard island and mcdonald islands": "HM",
+	"south georgia and the south sandwich islands": "GS",
+	"bouvet island":                        "BV",
+	"united states minor outlying islands": "UM",
+}
@@ -0,0 +1,41 @@
+package failover
+
+import (
+	"context"
+
+	"github.com/leinonen/hexagonal-architecture-go/internal/domain"
+	"github.com/leinonen/hexagonal-architecture-go/internal/ports"
+)
+
+// NewHealthCheckers wraps the health checkers of the providers in a failover
+// chain. A provider that is down is reported as degraded while another
+// provider can still answer, so the chain only counts as down once every
+// provider is.
+func NewHealthCheckers(checkers ...ports.HealthChecker) []ports.HealthChecker {
+	wrapped := make([]ports.HealthChecker, len(checkers))
+	for i := range checkers {
+		wrapped[i] = &providerHealth{checkers: checkers, index: i}
+	}
+	return wrapped
+}
+
+type providerHealth struct {
+	checkers []ports.HealthChecker
+	index    int
+}
+
+func (p *providerHealth) CheckHealth(ctx context.Context) domain.ComponentHealth {
+	health := p.checkers[p.index].CheckHealth(ctx)
+	if health.Status != domain.HealthDown {
+		return health
+	}
+
+	for i, checker := range p.checkers {
+		if i != p.index && checker.CheckHealth(ctx).Status != domain.HealthDown {
+			health.Status = domain.HealthDegraded
+			health.Detail += "; failing over to other providers"
+			break
+		}
+	}
+	return health
+}
@@ -0,0 +1,102 @@
+package failover
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/leinonen/hexagonal-architecture-go/internal/domain"
+	"github.com/leinonen/hexagonal-architecture-go/internal/errors"
+	"github.com/leinonen/hexagonal-architecture-go/internal/ports"
+)
+
+type Provider struct {
+	Name    string
+	Service ports.WeatherService
+}
+
+// WeatherFailover is a ports.WeatherService that asks providers in priority
+// order and falls through to the next one when a provider fails. Answers such
+// as "city not found" are returned as they are, since another provider is not
+// expected to know better.
+type WeatherFailover struct {
+	providers []Provider
+}
+
+func NewWeatherFailover(providers ...Provider) ports.WeatherService {
+	return &WeatherFailover{
+		providers: providers,
+	}
+}
+
+func (f *WeatherFailover) GetWeather(ctx context.Context, query domain.WeatherQuery) (*domain.Weather, error) {
+	var weather *domain.Weather
+	provider, err := f.try(ctx, func(service ports.WeatherService) (err error) {
+		weather, err = service.GetWeather(ctx, query)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if weather.Provider == "" {
+		weather.Provider = provider
+	}
+	return weather, nil
+}
+
+func (f *WeatherFailover) GetForecast(ctx context.Context, query domain.WeatherQuery, days int) (*domain.Forecast, error) {
+	var forecast *domain.Forecast
+	provider, err := f.try(ctx, func(service ports.WeatherService) (err error) {
+		forecast, err = service.GetForecast(ctx, query, days)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if forecast.Provider == "" {
+		forecast.Provider = provider
+	}
+	return forecast, nil
+}
+
+// try calls fn with each provider in turn until one succeeds or fails with
+// something other than a provider outage, and returns the provider's name.
+func (f *WeatherFailover) try(ctx context.Context, fn func(ports.WeatherService) error) (string, error) {
+	if len(f.providers) == 0 {
+		return "", errors.NewInternalError("no weather providers configured")
+	}
+
+	failures := make([]string, 0, len(f.providers))
+	rateLimited := true
+	var retryAfter time.Duration
+	for _, provider := range f.providers {
+		err := fn(provider.Service)
+		if err == nil {
+			return provider.Name, nil
+		}
+
+		if !errors.IsUpstreamFailure(err) || ctx.Err() != nil {
+			return "", err
+		}
+
+		log.Printf("Weather provider %s failed, trying next: %v", provider.Name, err)
+		failures = append(failures, fmt.Sprintf("%s: %v", provider.Name, err))
+
+		if !errors.IsRateLimited(err) {
+			rateLimited = false
+		} else if wait, ok := errors.RetryAfter(err); ok && (retryAfter == 0 || wait < retryAfter) {
+			retryAfter = wait
+		}
+	}
+
+	// When every provider is throttling us, callers are told to back off until
+	// the first of them will take requests again.
+	if rateLimited {
+		return "", errors.NewRateLimitedError("all weather providers are rate limited: "+strings.Join(failures, "; "), retryAfter)
+	}
+	return "", errors.NewExternalServiceError("all weather providers failed: " + strings.Join(failures, "; "))
+}
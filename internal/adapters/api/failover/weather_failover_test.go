@@ -0,0 +1,208 @@
+package failover_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/leinonen/hexagonal-architecture-go/internal/adapters/api/failover"
+	"github.com/leinonen/hexagonal-architecture-go/internal/domain"
+	"github.com/leinonen/hexagonal-architecture-go/internal/errors"
+	"github.com/leinonen/hexagonal-architecture-go/internal/ports"
+)
+
+type stubProvider struct {
+	err   error
+	calls int
+}
+
+func (p *stubProvider) GetWeather(ctx context.Context, query domain.WeatherQuery) (*domain.Weather, error) {
+	p.calls++
+	if p.err != nil {
+		return nil, p.err
+	}
+	return &domain.Weather{City: query.City}, nil
+}
+
+func (p *stubProvider) GetForecast(ctx context.Context, query domain.WeatherQuery, days int) (*domain.Forecast, error) {
+	p.calls++
+	if p.err != nil {
+		return nil, p.err
+	}
+	return &domain.Forecast{City: query.City}, nil
+}
+
+func TestWeatherFailover_GetWeather(t *testing.T) {
+	outage := errors.NewExternalServiceError("weather API returned status: 503")
+	notFound := errors.NewNotFoundError("city not found")
+	misconfigured := errors.NewMisconfigurationError("weather API rejected our credentials with status: 401")
+	rateLimited := errors.NewRateLimitedError("weather API rate limit exceeded", time.Minute)
+
+	tests := []struct {
+		name         string
+		primaryErr   error
+		secondaryErr error
+		wantProvider string
+		wantErr      func(error) bool
+		wantCalls    [2]int
+	}{
+		{
+			name:         "primary answers",
+			wantProvider: "primary",
+			wantCalls:    [2]int{1, 0},
+		},
+		{
+			name:         "fails over on outage",
+			primaryErr:   outage,
+			wantProvider: "secondary",
+			wantCalls:    [2]int{1, 1},
+		},
+		{
+			name:         "fails over on rejected credentials",
+			primaryErr:   misconfigured,
+			wantProvider: "secondary",
+			wantCalls:    [2]int{1, 1},
+		},
+		{
+			name:         "fails over when rate limited",
+			primaryErr:   rateLimited,
+			wantProvider: "secondary",
+			wantCalls:    [2]int{1, 1},
+		},
+		{
+			name:         "all providers rate limited",
+			primaryErr:   rateLimited,
+			secondaryErr: errors.NewRateLimitedError("weather API quota exceeded", 0),
+			wantErr:      errors.IsRateLimited,
+			wantCalls:    [2]int{1, 1},
+		},
+		{
+			name:         "rate limited and down",
+			primaryErr:   rateLimited,
+			secondaryErr: outage,
+			wantErr:      errors.IsExternalService,
+			wantCalls:    [2]int{1, 1},
+		},
+		{
+			name:       "not found is final",
+			primaryErr: notFound,
+			wantErr:    errors.IsNotFound,
+			wantCalls:  [2]int{1, 0},
+		},
+		{
+			name:         "all providers down",
+			primaryErr:   outage,
+			secondaryErr: outage,
+			wantErr:      errors.IsExternalService,
+			wantCalls:    [2]int{1, 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			primary := &stubProvider{err: tt.primaryErr}
+			secondary := &stubProvider{err: tt.secondaryErr}
+			service := failover.NewWeatherFailover(
+				failover.Provider{Name: "primary", Service: primary},
+				failover.Provider{Name: "secondary", Service: secondary},
+			)
+
+			weather, err := service.GetWeather(context.Background(), domain.CityQuery("London"))
+
+			if tt.wantErr != nil {
+				if !tt.wantErr(err) {
+					t.Errorf("GetWeather() error = %v, want different error type", err)
+				}
+			} else if err != nil {
+				t.Fatalf("GetWeather() unexpected error: %v", err)
+			} else if weather.Provider != tt.wantProvider {
+				t.Errorf("GetWeather() provider = %v, want %v", weather.Provider, tt.wantProvider)
+			}
+
+			if primary.calls != tt.wantCalls[0] || secondary.calls != tt.wantCalls[1] {
+				t.Errorf("provider calls = [%d %d], want %v", primary.calls, secondary.calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestWeatherFailover_NoProviders(t *testing.T) {
+	if _, err := failover.NewWeatherFailover().GetWeather(context.Background(), domain.CityQuery("London")); err == nil {
+		t.Errorf("GetWeather() without providers should fail")
+	}
+}
+
+func TestWeatherFailover_GetForecast(t *testing.T) {
+	primary := &stubProvider{err: errors.NewExternalServiceError("weather API returned status: 503")}
+	secondary := &stubProvider{}
+	service := failover.NewWeatherFailover(
+		failover.Provider{Name: "primary", Service: primary},
+		failover.Provider{Name: "secondary", Service: secondary},
+	)
+
+	forecast, err := service.GetForecast(context.Background(), domain.CityQuery("London"), 3)
+	if err != nil {
+		t.Fatalf("GetForecast() unexpected error: %v", err)
+	}
+	if forecast.Provider != "secondary" {
+		t.Errorf("GetForecast() provider = %v, want secondary", forecast.Provider)
+	}
+}
+
+func TestWeatherFailover_RateLimitedRetryAfter(t *testing.T) {
+	service := failover.NewWeatherFailover(
+		failover.Provider{Name: "primary", Service: &stubProvider{err: errors.NewRateLimitedError("rate limit exceeded", time.Minute)}},
+		failover.Provider{Name: "secondary", Service: &stubProvider{err: errors.NewRateLimitedError("quota exceeded", 0)}},
+		failover.Provider{Name: "tertiary", Service: &stubProvider{err: errors.NewRateLimitedError("rate limit exceeded", 10*time.Second)}},
+	)
+
+	_, err := service.GetWeather(context.Background(), domain.CityQuery("London"))
+	if retryAfter, ok := errors.RetryAfter(err); !ok || retryAfter != 10*time.Second {
+		t.Errorf("RetryAfter() = %v, want the shortest wait of 10s", retryAfter)
+	}
+}
+
+type stubHealth domain.HealthStatus
+
+func (s stubHealth) CheckHealth(ctx context.Context) domain.ComponentHealth {
+	return domain.ComponentHealth{Name: "stub", Status: domain.HealthStatus(s)}
+}
+
+func TestNewHealthCheckers(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []domain.HealthStatus
+		want     []domain.HealthStatus
+	}{
+		{
+			name:     "one provider down",
+			statuses: []domain.HealthStatus{domain.HealthDown, domain.HealthUp},
+			want:     []domain.HealthStatus{domain.HealthDegraded, domain.HealthUp},
+		},
+		{
+			name:     "others degraded",
+			statuses: []domain.HealthStatus{domain.HealthDegraded, domain.HealthDown},
+			want:     []domain.HealthStatus{domain.HealthDegraded, domain.HealthDegraded},
+		},
+		{
+			name:     "every provider down",
+			statuses: []domain.HealthStatus{domain.HealthDown, domain.HealthDown},
+			want:     []domain.HealthStatus{domain.HealthDown, domain.HealthDown},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkers := make([]ports.HealthChecker, len(tt.statuses))
+			for i, status := range tt.statuses {
+				checkers[i] = stubHealth(status)
+			}
+
+			for i, checker := range failover.NewHealthCheckers(checkers...) {
+				if got := checker.CheckHealth(context.Background()).Status; got != tt.want[i] {
+					t.Errorf("provider %d status = %v, want %v", i, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
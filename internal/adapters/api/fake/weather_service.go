@@ -0,0 +1,204 @@
+package fake
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/rand/v2"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/leinonen/hexagonal-architecture-go/internal/domain"
+	"github.com/leinonen/hexagonal-architecture-go/internal/errors"
+	"github.com/leinonen/hexagonal-architecture-go/internal/ports"
+)
+
+const Provider = "fake"
+
+// Fixture pins the weather reported for a city. Cities without a fixture get
+// generated weather.
+type Fixture struct {
+	City        string           `json:"city"`
+	Country     string           `json:"country"`
+	Latitude    float64          `json:"latitude"`
+	Longitude   float64          `json:"longitude"`
+	Temperature float64          `json:"temperature"`
+	Condition   domain.Condition `json:"condition"`
+	Description string           `json:"description"`
+	Humidity    int              `json:"humidity"`
+	WindSpeed   float64          `json:"wind_speed"`
+	// NotFound makes lookups for the city fail as if the provider did not
+	// know it.
+	NotFound bool `json:"not_found"`
+}
+
+// LoadFixtures reads a JSON array of fixtures.
+func LoadFixtures(path string) ([]Fixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var fixtures []Fixture
+	if err := json.Unmarshal(data, &fixtures); err != nil {
+		return nil, fmt.Errorf("invalid weather fixtures in %s: %w", path, err)
+	}
+	return fixtures, nil
+}
+
+// WeatherService is an offline ports.WeatherService for development and
+// tests. It answers every lookup without network access, with values derived
+// from the query so that the same place always gets the same weather.
+type WeatherService struct {
+	fixtures map[string]Fixture
+}
+
+func NewWeatherService(fixtures ...Fixture) ports.WeatherService {
+	service := &WeatherService{fixtures: make(map[string]Fixture)}
+	for _, fixture := range fixtures {
+		service.fixtures[domain.CityQuery(fixture.City).Key()] = fixture
+	}
+	return service
+}
+
+var conditions = []struct {
+	condition   domain.Condition
+	description string
+}{
+	{domain.ConditionClear, "clear sky"},
+	{domain.ConditionPartlyCloudy, "partly cloudy"},
+	{domain.ConditionCloudy, "overcast clouds"},
+	{domain.ConditionFog, "mist"},
+	{domain.ConditionDrizzle, "light drizzle"},
+	{domain.ConditionRain, "moderate rain"},
+	{domain.ConditionSnow, "light snow"},
+	{domain.ConditionThunderstorm, "thunderstorm"},
+}
+
+func (s *WeatherService) GetWeather(ctx context.Context, query domain.WeatherQuery) (*domain.Weather, error) {
+	fixture, err := s.lookup(query)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	today := now.Truncate(24 * time.Hour)
+	rng := newRand(query.Key())
+
+	return &domain.Weather{
+		City:           fixture.City,
+		Country:        fixture.Country,
+		Coordinates:    domain.Coordinates{Latitude: fixture.Latitude, Longitude: fixture.Longitude},
+		Temperature:    fixture.Temperature,
+		FeelsLike:      round(fixture.Temperature - fixture.WindSpeed/3),
+		MinTemperature: round(fixture.Temperature - 3 - rng.Float64()*3),
+		MaxTemperature: round(fixture.Temperature + 1 + rng.Float64()*3),
+		Condition:      fixture.Condition,
+		Description:    fixture.Description,
+		Humidity:       fixture.Humidity,
+		Pressure:       float64(990 + rng.IntN(40)),
+		Visibility:     10000,
+		CloudCover:     rng.IntN(101),
+		WindSpeed:      fixture.WindSpeed,
+		WindGust:       round(fixture.WindSpeed * 1.5),
+		WindDirection:  rng.IntN(360),
+		Sunrise:        today.Add(6 * time.Hour),
+		Sunset:         today.Add(18 * time.Hour),
+		ObservedAt:     now.Truncate(time.Hour),
+		Provider:       Provider,
+		Units:          domain.UnitsMetric,
+		FetchedAt:      now,
+	}, nil
+}
+
+// GetForecast reports one entry per hour, following a daily temperature
+// curve around the current temperature.
+func (s *WeatherService) GetForecast(ctx context.Context, query domain.WeatherQuery, days int) (*domain.Forecast, error) {
+	fixture, err := s.lookup(query)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	start := now.Truncate(24 * time.Hour)
+	rng := newRand(query.Key())
+
+	hourly := make([]domain.HourlyForecast, 0, days*24)
+	for i := 0; i < days*24; i++ {
+		at := start.Add(time.Duration(i) * time.Hour)
+		description := conditions[rng.IntN(len(conditions))].description
+		if i < 24 {
+			description = fixture.Description
+		}
+
+		hourly = append(hourly, domain.HourlyForecast{
+			Time:                at,
+			Temperature:         round(fixture.Temperature + 4*math.Sin(float64(at.Hour()-9)*math.Pi/12)),
+			Description:         description,
+			Humidity:            fixture.Humidity,
+			WindSpeed:           round(fixture.WindSpeed * (0.5 + rng.Float64())),
+			PrecipitationChance: round(rng.Float64()),
+		})
+	}
+
+	return &domain.Forecast{
+		City:        fixture.City,
+		Country:     fixture.Country,
+		Coordinates: domain.Coordinates{Latitude: fixture.Latitude, Longitude: fixture.Longitude},
+		Provider:    Provider,
+		Units:       domain.UnitsMetric,
+		Hourly:      hourly,
+		Daily:       domain.SummarizeDays(hourly, time.UTC),
+		FetchedAt:   now,
+	}, nil
+}
+
+// lookup returns the fixture for a city query, or generates one.
+func (s *WeatherService) lookup(query domain.WeatherQuery) (Fixture, error) {
+	if query.Coordinates == nil {
+		if fixture, ok := s.fixtures[domain.CityQuery(query.City).Key()]; ok && (query.Country == "" || strings.EqualFold(query.Country, fixture.Country)) {
+			if fixture.NotFound {
+				return Fixture{}, errors.NewNotFoundError("city not found")
+			}
+			if fixture.Condition == "" {
+				fixture.Condition = domain.ConditionUnknown
+			}
+			return fixture, nil
+		}
+	}
+
+	rng := newRand(query.Key())
+	weather := conditions[rng.IntN(len(conditions))]
+	fixture := Fixture{
+		City:        strings.TrimSpace(query.City),
+		Country:     strings.ToUpper(query.Country),
+		Latitude:    round(rng.Float64()*180 - 90),
+		Longitude:   round(rng.Float64()*360 - 180),
+		Temperature: round(rng.Float64()*40 - 10),
+		Condition:   weather.condition,
+		Description: weather.description,
+		Humidity:    30 + rng.IntN(66),
+		WindSpeed:   round(rng.Float64() * 15),
+	}
+
+	if query.Coordinates != nil {
+		fixture.City = fmt.Sprintf("%.2f, %.2f", query.Coordinates.Latitude, query.Coordinates.Longitude)
+		fixture.Latitude = query.Coordinates.Latitude
+		fixture.Longitude = query.Coordinates.Longitude
+	}
+	return fixture, nil
+}
+
+func newRand(key string) *rand.Rand {
+	hash := fnv.New64a()
+	hash.Write([]byte(key))
+	seed := hash.Sum64()
+	return rand.New(rand.NewPCG(seed, seed>>32))
+}
+
+func round(value float64) float64 {
+	return math.Round(value*10) / 10
+}
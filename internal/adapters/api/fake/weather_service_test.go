@@ -0,0 +1,96 @@
+package fake_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/leinonen/hexagonal-architecture-go/internal/adapters/api/fake"
+	"github.com/leinonen/hexagonal-architecture-go/internal/domain"
+	"github.com/leinonen/hexagonal-architecture-go/internal/errors"
+)
+
+func TestWeatherService_Deterministic(t *testing.T) {
+	service := fake.NewWeatherService()
+	ctx := context.Background()
+
+	first, err := service.GetWeather(ctx, domain.CityQuery("Reykjavík"))
+	if err != nil {
+		t.Fatalf("GetWeather() unexpected error: %v", err)
+	}
+	second, _ := fake.NewWeatherService().GetWeather(ctx, domain.CityQuery("reykjavík "))
+	other, _ := service.GetWeather(ctx, domain.CityQuery("Lima"))
+
+	if first.City != "Reykjavík" || first.Provider != fake.Provider || first.Units != domain.UnitsMetric {
+		t.Errorf("GetWeather() = %+v", first)
+	}
+	if first.Temperature != second.Temperature || first.Humidity != second.Humidity || first.Condition != second.Condition {
+		t.Errorf("GetWeather() differs between calls: %+v and %+v", first, second)
+	}
+	if first.Temperature == other.Temperature && first.Humidity == other.Humidity && first.Coordinates == other.Coordinates {
+		t.Errorf("GetWeather() returned the same weather for different cities")
+	}
+
+	coordinates := domain.Coordinates{Latitude: 60.17, Longitude: 24.94}
+	byCoordinates, _ := service.GetWeather(ctx, domain.WeatherQuery{Coordinates: &coordinates})
+	if byCoordinates.Coordinates != coordinates {
+		t.Errorf("GetWeather() coordinates = %v, want %v", byCoordinates.Coordinates, coordinates)
+	}
+}
+
+func TestWeatherService_Fixtures(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "weather.json")
+	os.WriteFile(path, []byte(`[
+		{"city": "London", "country": "GB", "latitude": 51.51, "longitude": -0.13, "temperature": 14.5, "condition": "rain", "description": "light rain", "humidity": 80, "wind_speed": 4},
+		{"city": "Atlantis", "not_found": true}
+	]`), 0o600)
+
+	fixtures, err := fake.LoadFixtures(path)
+	if err != nil {
+		t.Fatalf("LoadFixtures() unexpected error: %v", err)
+	}
+	service := fake.NewWeatherService(fixtures...)
+	ctx := context.Background()
+
+	weather, err := service.GetWeather(ctx, domain.WeatherQuery{City: "london", Country: "gb"})
+	if err != nil {
+		t.Fatalf("GetWeather() unexpected error: %v", err)
+	}
+	if weather.City != "London" || weather.Temperature != 14.5 || weather.Condition != domain.ConditionRain || weather.Humidity != 80 {
+		t.Errorf("GetWeather() = %+v, want the London fixture", weather)
+	}
+
+	if other, _ := service.GetWeather(ctx, domain.WeatherQuery{City: "London", Country: "CA"}); other.Temperature == 14.5 && other.Country == "GB" {
+		t.Errorf("GetWeather() for London, CA returned the London, GB fixture")
+	}
+
+	if _, err := service.GetWeather(ctx, domain.CityQuery("Atlantis")); !errors.IsNotFound(err) {
+		t.Errorf("GetWeather() error = %v, want not found", err)
+	}
+	if _, err := service.GetForecast(ctx, domain.CityQuery("Atlantis"), 3); !errors.IsNotFound(err) {
+		t.Errorf("GetForecast() error = %v, want not found", err)
+	}
+
+	if _, err := fake.LoadFixtures(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Errorf("LoadFixtures() of a missing file should fail")
+	}
+}
+
+func TestWeatherService_GetForecast(t *testing.T) {
+	service := fake.NewWeatherService()
+
+	forecast, err := service.GetForecast(context.Background(), domain.CityQuery("Oslo"), 3)
+	if err != nil {
+		t.Fatalf("GetForecast() unexpected error: %v", err)
+	}
+
+	if len(forecast.Daily) != 3 || len(forecast.Hourly) != 72 {
+		t.Errorf("GetForecast() returned %d days and %d hours, want 3 and 72", len(forecast.Daily), len(forecast.Hourly))
+	}
+	for _, day := range forecast.Daily {
+		if day.MinTemperature > day.MaxTemperature {
+			t.Errorf("GetForecast() day %v min %v above max %v", day.Date, day.MinTemperature, day.MaxTemperature)
+		}
+	}
+}
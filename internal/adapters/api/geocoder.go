@@ -0,0 +1,73 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/leinonen/hexagonal-architecture-go/internal/domain"
+	"github.com/leinonen/hexagonal-architecture-go/internal/errors"
+	"github.com/leinonen/hexagonal-architecture-go/internal/ports"
+)
+
+// Geocoder resolves place names with the OpenWeatherMap geocoding API.
+type Geocoder struct {
+	baseClient
+	apiKey string
+}
+
+func NewGeocoder(apiKey string, opts ...Option) ports.Geocoder {
+	return &Geocoder{
+		baseClient: newBaseClient("https://api.openweathermap.org/geo/1.0", opts),
+		apiKey:     apiKey,
+	}
+}
+
+type geocodeAPIResponse []struct {
+	Name    string  `json:"name"`
+	State   string  `json:"state"`
+	Country string  `json:"country"`
+	Lat     float64 `json:"lat"`
+	Lon     float64 `json:"lon"`
+}
+
+func (g *Geocoder) Geocode(ctx context.Context, name, country string, limit int) ([]domain.Place, error) {
+	q := name
+	if country != "" {
+		q += "," + country
+	}
+	params := url.Values{
+		"q":     {q},
+		"limit": {strconv.Itoa(limit)},
+		"appid": {g.apiKey},
+	}
+
+	resp, err := g.get(ctx, "/direct", params)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := checkStatus(resp); err != nil {
+		return nil, err
+	}
+
+	var apiResp geocodeAPIResponse
+	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
+		return nil, errors.NewInternalError(fmt.Sprintf("failed to decode response: %v", err))
+	}
+
+	places := make([]domain.Place, len(apiResp))
+	for i, candidate := range apiResp {
+		places[i] = domain.Place{
+			Name:        candidate.Name,
+			State:       candidate.State,
+			Country:     candidate.Country,
+			Coordinates: domain.Coordinates{Latitude: candidate.Lat, Longitude: candidate.Lon},
+		}
+	}
+
+	return places, nil
+}
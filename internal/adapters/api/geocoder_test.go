@@ -0,0 +1,53 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/leinonen/hexagonal-architecture-go/internal/errors"
+)
+
+func newTestGeocoder(t *testing.T, handler http.HandlerFunc) *Geocoder {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	return NewGeocoder("test-key", WithRetryPolicy(NoRetry()), WithBaseURL(server.URL)).(*Geocoder)
+}
+
+func TestGeocoder_Geocode(t *testing.T) {
+	geocoder := newTestGeocoder(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/direct" || r.URL.Query().Get("q") != "Paris,US" || r.URL.Query().Get("limit") != "2" {
+			t.Errorf("unexpected request %s", r.URL)
+		}
+		w.Write([]byte(`[
+			{"name": "Paris", "state": "Texas", "country": "US", "lat": 33.6609, "lon": -95.5555},
+			{"name": "Paris", "state": "Tennessee", "country": "US", "lat": 36.302, "lon": -88.3267}
+		]`))
+	})
+
+	places, err := geocoder.Geocode(context.Background(), "Paris", "US", 2)
+	if err != nil {
+		t.Fatalf("Geocode() unexpected error: %v", err)
+	}
+
+	if len(places) != 2 {
+		t.Fatalf("Geocode() returned %d places, want 2", len(places))
+	}
+	if places[0].State != "Texas" || places[0].Country != "US" || places[0].Coordinates.Latitude != 33.6609 {
+		t.Errorf("Geocode() first place = %+v", places[0])
+	}
+}
+
+func TestGeocoder_GeocodeFailure(t *testing.T) {
+	geocoder := newTestGeocoder(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	})
+
+	if _, err := geocoder.Geocode(context.Background(), "Paris", "", 5); !errors.IsMisconfiguration(err) {
+		t.Errorf("Geocode() error = %v, want misconfiguration error", err)
+	}
+}
@@ -0,0 +1,44 @@
+package history
+
+import (
+	"context"
+	"log"
+
+	"github.com/leinonen/hexagonal-architecture-go/internal/domain"
+	"github.com/leinonen/hexagonal-architecture-go/internal/ports"
+)
+
+// WeatherRecorder is a ports.WeatherService decorator that stores every
+// weather answer as an observation. It belongs below the cache and the
+// coalescer, so that each provider fetch is recorded once.
+//
+// Failing to record is logged rather than returned: the caller still gets
+// the weather.
+type WeatherRecorder struct {
+	next ports.WeatherService
+	repo ports.ObservationRepository
+}
+
+func NewWeatherRecorder(next ports.WeatherService, repo ports.ObservationRepository) *WeatherRecorder {
+	return &WeatherRecorder{
+		next: next,
+		repo: repo,
+	}
+}
+
+func (r *WeatherRecorder) GetWeather(ctx context.Context, query domain.WeatherQuery) (*domain.Weather, error) {
+	weather, err := r.next.GetWeather(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.repo.Record(context.WithoutCancel(ctx), domain.NewObservation(query, weather)); err != nil {
+		log.Printf("Failed to record weather observation for %s: %v", query.Key(), err)
+	}
+
+	return weather, nil
+}
+
+func (r *WeatherRecorder) GetForecast(ctx context.Context, query domain.WeatherQuery, days int) (*domain.Forecast, error) {
+	return r.next.GetForecast(ctx, query, days)
+}
@@ -0,0 +1,71 @@
+package history_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/leinonen/hexagonal-architecture-go/internal/adapters/api/history"
+	"github.com/leinonen/hexagonal-architecture-go/internal/domain"
+	"github.com/leinonen/hexagonal-architecture-go/internal/errors"
+)
+
+type stubWeatherService struct{}
+
+func (stubWeatherService) GetWeather(ctx context.Context, query domain.WeatherQuery) (*domain.Weather, error) {
+	if query.City == "Atlantis" {
+		return nil, errors.NewNotFoundError("city not found")
+	}
+	return &domain.Weather{City: query.City, Temperature: 10, Provider: "stub"}, nil
+}
+
+func (stubWeatherService) GetForecast(ctx context.Context, query domain.WeatherQuery, days int) (*domain.Forecast, error) {
+	return &domain.Forecast{City: query.City}, nil
+}
+
+type recordingRepository struct {
+	observations []*domain.Observation
+	err          error
+}
+
+func (r *recordingRepository) Record(ctx context.Context, observation *domain.Observation) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.observations = append(r.observations, observation)
+	return nil
+}
+
+func (r *recordingRepository) List(ctx context.Context, query domain.WeatherQuery, from, to time.Time) ([]*domain.Observation, error) {
+	return r.observations, nil
+}
+
+func TestWeatherRecorder_RecordsFetchedWeather(t *testing.T) {
+	repo := &recordingRepository{}
+	recorder := history.NewWeatherRecorder(stubWeatherService{}, repo)
+	ctx := context.Background()
+
+	if _, err := recorder.GetWeather(ctx, domain.CityQuery("London")); err != nil {
+		t.Fatalf("GetWeather() unexpected error: %v", err)
+	}
+	recorder.GetWeather(ctx, domain.CityQuery("Atlantis"))
+	recorder.GetForecast(ctx, domain.CityQuery("London"), 3)
+
+	if len(repo.observations) != 1 {
+		t.Fatalf("recorded %d observations, want only the successful weather lookup", len(repo.observations))
+	}
+	observation := repo.observations[0]
+	if observation.Query.City != "London" || observation.Weather.Provider != "stub" || observation.RecordedAt.IsZero() {
+		t.Errorf("observation = %+v, want London from the stub provider with a recording time", observation)
+	}
+}
+
+func TestWeatherRecorder_IgnoresRecordingFailure(t *testing.T) {
+	repo := &recordingRepository{err: errors.NewInternalError("disk full")}
+	recorder := history.NewWeatherRecorder(stubWeatherService{}, repo)
+
+	weather, err := recorder.GetWeather(context.Background(), domain.CityQuery("London"))
+	if err != nil || weather.City != "London" {
+		t.Errorf("GetWeather() = %v, %v, want the weather despite the recording failure", weather, err)
+	}
+}
@@ -0,0 +1,171 @@
+package limit
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/leinonen/hexagonal-architecture-go/internal/errors"
+)
+
+// ErrBudgetExhausted is wrapped by the RateLimited error returned when a call
+// would exceed our own budget, as opposed to the provider throttling us.
+var ErrBudgetExhausted = stderrors.New("local rate limit")
+
+type Config struct {
+	// Name identifies the limited provider in errors.
+	Name string
+	// Rate is how many calls per second are allowed on average.
+	Rate float64
+	// Burst is how many calls may be made back to back after a quiet period.
+	Burst int
+	// MaxWait is how long a call may queue for its turn. Zero rejects calls
+	// as soon as the budget is used up.
+	MaxWait time.Duration
+}
+
+// DefaultConfig matches the OpenWeatherMap free plan of 60 calls a minute.
+func DefaultConfig() Config {
+	return Config{
+		Name:    "weather",
+		Rate:    1,
+		Burst:   10,
+		MaxWait: time.Second,
+	}
+}
+
+type Stats struct {
+	Allowed  uint64
+	Queued   uint64
+	Rejected uint64
+}
+
+// Budget is a token bucket for the requests made against one provider's
+// quota. Every client of the provider's API should spend from the same
+// budget, one token per HTTP request including retries.
+type Budget struct {
+	config Config
+
+	mu       sync.Mutex
+	tokens   float64
+	last     time.Time
+	allowed  uint64
+	queued   uint64
+	rejected uint64
+}
+
+func NewBudget(config Config) *Budget {
+	defaults := DefaultConfig()
+	if config.Name == "" {
+		config.Name = defaults.Name
+	}
+	if config.Rate <= 0 {
+		config.Rate = defaults.Rate
+	}
+	if config.Burst <= 0 {
+		config.Burst = 1
+	}
+	if config.MaxWait < 0 {
+		config.MaxWait = 0
+	}
+
+	return &Budget{
+		config: config,
+		tokens: float64(config.Burst),
+		last:   time.Now(),
+	}
+}
+
+func (b *Budget) Stats() Stats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return Stats{
+		Allowed:  b.allowed,
+		Queued:   b.queued,
+		Rejected: b.rejected,
+	}
+}
+
+// Wait takes a token, queueing for up to MaxWait when none is left. A call
+// is rejected straight away if its turn would come after MaxWait or after the
+// caller's deadline.
+func (b *Budget) Wait(ctx context.Context) error {
+	delay, err := b.reserve(ctx)
+	if err != nil || delay == 0 {
+		return err
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		b.cancel()
+		return ctx.Err()
+	}
+}
+
+// reserve takes a token, letting the bucket go negative for queued calls,
+// and returns how long the caller has to wait for it.
+func (b *Budget) reserve(ctx context.Context) (time.Duration, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	b.refill(now)
+
+	if b.tokens >= 1 {
+		b.tokens--
+		b.allowed++
+		return 0, nil
+	}
+
+	delay := b.until(1)
+	deadline, hasDeadline := ctx.Deadline()
+	if delay > b.config.MaxWait || (hasDeadline && now.Add(delay).After(deadline)) {
+		b.rejected++
+		return 0, b.budgetError(delay)
+	}
+
+	b.tokens--
+	b.allowed++
+	b.queued++
+	return delay, nil
+}
+
+// cancel gives back the token of a queued call whose caller left, which then
+// counts as neither allowed nor queued.
+func (b *Budget) cancel() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.tokens++
+	b.allowed--
+	b.queued--
+}
+
+func (b *Budget) refill(now time.Time) {
+	elapsed := now.Sub(b.last).Seconds()
+	b.last = now
+	b.tokens = min(b.tokens+elapsed*b.config.Rate, float64(b.config.Burst))
+}
+
+// until is how long it takes for the bucket to hold the given number of
+// tokens.
+func (b *Budget) until(tokens float64) time.Duration {
+	return time.Duration((tokens - b.tokens) / b.config.Rate * float64(time.Second))
+}
+
+func (b *Budget) budgetError(retryAfter time.Duration) error {
+	return &errors.AppError{
+		Type:       errors.RateLimited,
+		Message:    fmt.Sprintf("%s provider call budget exhausted", b.config.Name),
+		Err:        ErrBudgetExhausted,
+		RetryAfter: retryAfter,
+	}
+}
@@ -0,0 +1,94 @@
+package limit_test
+
+import (
+	"context"
+	stderrors "errors"
+	"testing"
+	"time"
+
+	"github.com/leinonen/hexagonal-architecture-go/internal/adapters/api/limit"
+	"github.com/leinonen/hexagonal-architecture-go/internal/errors"
+)
+
+func TestBudget_RejectsOverBudget(t *testing.T) {
+	budget := limit.NewBudget(limit.Config{Name: "test", Rate: 1, Burst: 3})
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if err := budget.Wait(ctx); err != nil {
+			t.Fatalf("Wait() call %d unexpected error: %v", i+1, err)
+		}
+	}
+
+	err := budget.Wait(ctx)
+	if !errors.IsRateLimited(err) || !stderrors.Is(err, limit.ErrBudgetExhausted) {
+		t.Fatalf("Wait() error = %v, want the local budget to be exhausted", err)
+	}
+	if retryAfter, ok := errors.RetryAfter(err); !ok || retryAfter > time.Second {
+		t.Errorf("RetryAfter() = %v, want up to 1s until the next token", retryAfter)
+	}
+
+	if stats := budget.Stats(); stats.Allowed != 3 || stats.Rejected != 1 {
+		t.Errorf("Stats() = %+v, want 3 allowed and 1 rejected", stats)
+	}
+}
+
+func TestBudget_Refills(t *testing.T) {
+	budget := limit.NewBudget(limit.Config{Rate: 50, Burst: 1})
+	ctx := context.Background()
+
+	budget.Wait(ctx)
+	time.Sleep(40 * time.Millisecond)
+
+	if err := budget.Wait(ctx); err != nil {
+		t.Errorf("Wait() error = %v after the bucket refilled", err)
+	}
+}
+
+func TestBudget_QueuesWithinMaxWait(t *testing.T) {
+	budget := limit.NewBudget(limit.Config{Rate: 20, Burst: 1, MaxWait: time.Second})
+	ctx := context.Background()
+
+	budget.Wait(ctx)
+
+	start := time.Now()
+	if err := budget.Wait(ctx); err != nil {
+		t.Fatalf("Wait() unexpected error: %v", err)
+	}
+	if waited := time.Since(start); waited < 30*time.Millisecond {
+		t.Errorf("Wait() waited %v, want about 50ms for the next token", waited)
+	}
+
+	if stats := budget.Stats(); stats.Queued != 1 {
+		t.Errorf("Stats() = %+v, want 1 queued call", stats)
+	}
+}
+
+func TestBudget_RespectsContext(t *testing.T) {
+	budget := limit.NewBudget(limit.Config{Rate: 10, Burst: 1, MaxWait: time.Second})
+
+	budget.Wait(context.Background())
+
+	short, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := budget.Wait(short); !stderrors.Is(err, limit.ErrBudgetExhausted) {
+		t.Errorf("Wait() error = %v, want rejection when the deadline comes before the next token", err)
+	}
+	if waited := time.Since(start); waited > 10*time.Millisecond {
+		t.Errorf("Wait() waited %v before rejecting, want an immediate answer", waited)
+	}
+
+	canceled, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+	if err := budget.Wait(canceled); err != context.Canceled {
+		t.Errorf("Wait() error = %v, want context.Canceled", err)
+	}
+
+	if stats := budget.Stats(); stats.Allowed != 1 || stats.Queued != 0 {
+		t.Errorf("Stats() = %+v, want the canceled call counted as neither allowed nor queued", stats)
+	}
+}
@@ -0,0 +1,224 @@
+package replay
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+type Mode int
+
+const (
+	// Replay answers requests from the cassette and never touches the
+	// network.
+	Replay Mode = iota
+	// Record sends requests upstream and saves the scrubbed exchanges to the
+	// cassette.
+	Record
+)
+
+// ModeFromEnv records when RECORD_FIXTURES is set. Tests should only use it
+// for cassettes the live API reproduces, and replay hand-written ones.
+func ModeFromEnv() Mode {
+	if os.Getenv("RECORD_FIXTURES") != "" {
+		return Record
+	}
+	return Replay
+}
+
+// SecretParams are query parameters whose values never reach a cassette.
+var SecretParams = []string{"appid", "key"}
+
+// keptHeaders are the response headers worth saving; the rest vary between
+// runs or identify the account.
+var keptHeaders = []string{"Content-Type", "Retry-After"}
+
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+type Request struct {
+	Method string `json:"method"`
+	// URL is the path and scrubbed query, without scheme and host.
+	URL string `json:"url"`
+}
+
+type Response struct {
+	Status int               `json:"status"`
+	Header map[string]string `json:"header,omitempty"`
+	Body   string            `json:"body"`
+}
+
+// Transport is an http.RoundTripper that records HTTP exchanges to a
+// cassette file or replays them from it.
+type Transport struct {
+	path string
+	mode Mode
+	next http.RoundTripper
+
+	mu           sync.Mutex
+	interactions []Interaction
+	used         []bool
+}
+
+// New opens the cassette at path. In Replay mode it must exist; in Record
+// mode next (http.DefaultTransport when nil) makes the real requests and
+// Save writes them out.
+func New(path string, mode Mode, next http.RoundTripper) (*Transport, error) {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	t := &Transport{path: path, mode: mode, next: next}
+	if mode == Record {
+		return t, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &t.interactions); err != nil {
+		return nil, fmt.Errorf("invalid cassette %s: %w", path, err)
+	}
+	t.used = make([]bool, len(t.interactions))
+	return t, nil
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.mode == Record {
+		return t.record(req)
+	}
+	return t.replay(req)
+}
+
+// replay answers with the first unused interaction matching the request, so
+// that repeated requests get their responses in recorded order. Once every
+// match has been used the last one is repeated.
+func (t *Transport) replay(req *http.Request) (*http.Response, error) {
+	key := scrubURL(req.URL)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	found := -1
+	for i, interaction := range t.interactions {
+		if interaction.Request.Method != req.Method || interaction.Request.URL != key {
+			continue
+		}
+		found = i
+		if !t.used[i] {
+			break
+		}
+	}
+	if found < 0 {
+		return nil, fmt.Errorf("replay: no interaction in %s for %s %s", t.path, req.Method, key)
+	}
+
+	t.used[found] = true
+	return t.interactions[found].Response.toHTTP(req), nil
+}
+
+func (t *Transport) record(req *http.Request) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	recorded := Response{Status: resp.StatusCode, Header: make(map[string]string), Body: scrubBody(string(body), req.URL)}
+	for _, name := range keptHeaders {
+		if value := resp.Header.Get(name); value != "" {
+			recorded.Header[name] = value
+		}
+	}
+
+	t.mu.Lock()
+	t.interactions = append(t.interactions, Interaction{
+		Request:  Request{Method: req.Method, URL: scrubURL(req.URL)},
+		Response: recorded,
+	})
+	t.mu.Unlock()
+
+	return recorded.toHTTP(req), nil
+}
+
+// Save writes recorded interactions to the cassette. It does nothing in
+// Replay mode.
+func (t *Transport) Save() error {
+	if t.mode != Record {
+		return nil
+	}
+
+	t.mu.Lock()
+	data, err := json.MarshalIndent(t.interactions, "", "  ")
+	t.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(t.path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(t.path, append(data, '\n'), 0o644)
+}
+
+func (r Response) toHTTP(req *http.Request) *http.Response {
+	header := make(http.Header)
+	for name, value := range r.Header {
+		header.Set(name, value)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", r.Status, http.StatusText(r.Status)),
+		StatusCode:    r.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader([]byte(r.Body))),
+		ContentLength: int64(len(r.Body)),
+		Request:       req,
+	}
+}
+
+// scrubURL reduces a request URL to its path and query with secrets
+// replaced, which is both what is stored and what replay matches on.
+func scrubURL(u *url.URL) string {
+	query := u.Query()
+	for _, param := range SecretParams {
+		if query.Has(param) {
+			query.Set(param, "REDACTED")
+		}
+	}
+
+	if len(query) == 0 {
+		return u.Path
+	}
+	return u.Path + "?" + query.Encode()
+}
+
+// scrubBody removes any secret the request carried from the response body,
+// for providers that echo the key back in errors.
+func scrubBody(body string, u *url.URL) string {
+	query := u.Query()
+	for _, param := range SecretParams {
+		if secret := query.Get(param); secret != "" {
+			body = strings.ReplaceAll(body, secret, "REDACTED")
+		}
+	}
+	return body
+}
@@ -0,0 +1,91 @@
+package replay_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/leinonen/hexagonal-architecture-go/internal/adapters/api/replay"
+)
+
+func TestTransport_RecordAndReplay(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "session=abc")
+		if calls == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"message":"slow down, key super-secret"}`))
+			return
+		}
+		w.Write([]byte(`{"name":"London"}`))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "cassette.json")
+
+	recorder, err := replay.New(path, replay.Record, nil)
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+	client := &http.Client{Transport: recorder}
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(server.URL + "/weather?q=London&appid=super-secret")
+		if err != nil {
+			t.Fatalf("recording request failed: %v", err)
+		}
+		resp.Body.Close()
+	}
+	if err := recorder.Save(); err != nil {
+		t.Fatalf("Save() unexpected error: %v", err)
+	}
+
+	saved, _ := os.ReadFile(path)
+	if strings.Contains(string(saved), "super-secret") {
+		t.Errorf("cassette leaks the API key:\n%s", saved)
+	}
+	if strings.Contains(string(saved), "session=abc") {
+		t.Errorf("cassette keeps volatile headers:\n%s", saved)
+	}
+
+	player, err := replay.New(path, replay.Replay, nil)
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+	client = &http.Client{Transport: player}
+
+	for _, wantStatus := range []int{http.StatusTooManyRequests, http.StatusOK, http.StatusOK} {
+		resp, err := client.Get("https://api.example.com/weather?appid=another-key&q=London")
+		if err != nil {
+			t.Fatalf("replayed request failed: %v", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if resp.StatusCode != wantStatus {
+			t.Errorf("replayed status = %d, want %d (body %s)", resp.StatusCode, wantStatus, body)
+		}
+		if resp.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("replayed Content-Type = %q", resp.Header.Get("Content-Type"))
+		}
+	}
+
+	if calls != 2 {
+		t.Errorf("upstream called %d times, want 2 (replay must not reach the network)", calls)
+	}
+
+	if _, err := client.Get("https://api.example.com/weather?appid=x&q=Paris"); err == nil {
+		t.Errorf("replaying an unrecorded request should fail")
+	}
+}
+
+func TestTransport_MissingCassette(t *testing.T) {
+	if _, err := replay.New(filepath.Join(t.TempDir(), "missing.json"), replay.Replay, nil); err == nil {
+		t.Errorf("New() should fail when the cassette does not exist")
+	}
+}
@@ -0,0 +1,139 @@
+package api
+
+import (
+	"context"
+	stderrors "errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how WeatherClient retries failed lookups. Only
+// timeouts, 429 and 502/503/504 responses are retried; every other failure is
+// returned on the first attempt.
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+	// Jitter is the fraction of each delay, between 0 and 1, that is
+	// randomised so that clients do not retry in lockstep.
+	Jitter float64
+}
+
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   200 * time.Millisecond,
+		MaxDelay:    2 * time.Second,
+		Jitter:      0.5,
+	}
+}
+
+// NoRetry makes exactly one attempt.
+func NoRetry() RetryPolicy {
+	return RetryPolicy{MaxAttempts: 1}
+}
+
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *baseClient) {
+		if policy.MaxAttempts < 1 {
+			policy.MaxAttempts = 1
+		}
+		if policy.Jitter < 0 {
+			policy.Jitter = 0
+		}
+		if policy.Jitter > 1 {
+			policy.Jitter = 1
+		}
+		c.retryPolicy = policy
+	}
+}
+
+// delay decides whether a failed attempt should be retried and how long to
+// wait first. A Retry-After longer than MaxDelay is not waited out.
+func (p RetryPolicy) delay(attempt int, resp *http.Response, err error) (time.Duration, bool) {
+	if attempt >= p.MaxAttempts {
+		return 0, false
+	}
+
+	backoff := p.backoff(attempt)
+
+	if err != nil {
+		var netErr net.Error
+		if stderrors.As(err, &netErr) && netErr.Timeout() {
+			return backoff, true
+		}
+		return 0, false
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After"))
+		if !ok {
+			return backoff, true
+		}
+		if p.MaxDelay > 0 && retryAfter > p.MaxDelay {
+			return 0, false
+		}
+		return max(retryAfter, backoff), true
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return backoff, true
+	}
+
+	return 0, false
+}
+
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.BaseDelay << (attempt - 1)
+	if delay <= 0 || (p.MaxDelay > 0 && delay > p.MaxDelay) {
+		delay = p.MaxDelay
+	}
+	if p.Jitter > 0 && delay > 0 {
+		delay -= time.Duration(rand.Float64() * p.Jitter * float64(delay))
+	}
+	return delay
+}
+
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(time.Until(at), 0), true
+	}
+	return 0, false
+}
+
+// sleep waits for d unless the context ends first or its deadline would pass
+// before the next attempt could start.
+func sleep(ctx context.Context, d time.Duration) bool {
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= d {
+		return false
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// discard drains and closes a response that is being retried so its
+// connection can be reused.
+func discard(resp *http.Response) {
+	if resp == nil {
+		return
+	}
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	resp.Body.Close()
+}
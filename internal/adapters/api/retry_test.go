@@ -0,0 +1,264 @@
+package api
+
+import (
+	"context"
+	stderrors "errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/leinonen/hexagonal-architecture-go/internal/adapters/api/limit"
+	"github.com/leinonen/hexagonal-architecture-go/internal/domain"
+	"github.com/leinonen/hexagonal-architecture-go/internal/errors"
+)
+
+const londonResponse = `{"name":"London","main":{"temp":15.5,"humidity":70},"weather":[{"description":"cloudy"}],"wind":{"speed":5.5}}`
+
+var fastRetries = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   time.Millisecond,
+	MaxDelay:    10 * time.Millisecond,
+	Jitter:      0.5,
+}
+
+func newTestClient(t *testing.T, policy RetryPolicy, handler http.HandlerFunc) *WeatherClient {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	return NewWeatherClient("test-key", WithRetryPolicy(policy), WithBaseURL(server.URL)).(*WeatherClient)
+}
+
+// failingThen answers with the given statuses in order and succeeds once they
+// run out.
+func failingThen(attempts *atomic.Int32, statuses []int, header http.Header) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		n := int(attempts.Add(1))
+		if n <= len(statuses) {
+			for key, values := range header {
+				w.Header()[key] = values
+			}
+			w.WriteHeader(statuses[n-1])
+			return
+		}
+		w.Write([]byte(londonResponse))
+	}
+}
+
+func TestWeatherClient_Retry(t *testing.T) {
+	tests := []struct {
+		name         string
+		statuses     []int
+		header       http.Header
+		wantAttempts int32
+		wantErr      func(error) bool
+	}{
+		{
+			name:         "recovers from 503",
+			statuses:     []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable},
+			wantAttempts: 3,
+		},
+		{
+			name:         "recovers from 502 and 504",
+			statuses:     []int{http.StatusBadGateway, http.StatusGatewayTimeout},
+			wantAttempts: 3,
+		},
+		{
+			name:         "gives up after max attempts",
+			statuses:     []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusServiceUnavailable},
+			wantAttempts: 3,
+			wantErr:      errors.IsExternalService,
+		},
+		{
+			name:         "429 with short Retry-After",
+			statuses:     []int{http.StatusTooManyRequests},
+			header:       http.Header{"Retry-After": {"0"}},
+			wantAttempts: 2,
+		},
+		{
+			name:         "429 with Retry-After beyond max delay",
+			statuses:     []int{http.StatusTooManyRequests},
+			header:       http.Header{"Retry-After": {"120"}},
+			wantAttempts: 1,
+			wantErr:      errors.IsRateLimited,
+		},
+		{
+			name:         "500 is not retried",
+			statuses:     []int{http.StatusInternalServerError},
+			wantAttempts: 1,
+			wantErr:      errors.IsExternalService,
+		},
+		{
+			name:         "404 is not retried",
+			statuses:     []int{http.StatusNotFound},
+			wantAttempts: 1,
+			wantErr:      errors.IsNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts atomic.Int32
+			client := newTestClient(t, fastRetries, failingThen(&attempts, tt.statuses, tt.header))
+
+			weather, err := client.GetWeather(context.Background(), domain.CityQuery("London"))
+
+			if tt.wantErr != nil {
+				if !tt.wantErr(err) {
+					t.Errorf("GetWeather() error = %v, want different error type", err)
+				}
+			} else if err != nil {
+				t.Errorf("GetWeather() unexpected error: %v", err)
+			} else if weather.City != "London" {
+				t.Errorf("GetWeather() city = %v, want London", weather.City)
+			}
+
+			if got := attempts.Load(); got != tt.wantAttempts {
+				t.Errorf("GetWeather() made %d attempts, want %d", got, tt.wantAttempts)
+			}
+		})
+	}
+}
+
+func TestWeatherClient_RetrySpendsBudget(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       int
+		burst        int
+		wantAttempts int32
+		wantErr      func(error) bool
+	}{
+		{
+			name:         "503 retried while the budget lasts",
+			status:       http.StatusServiceUnavailable,
+			burst:        2,
+			wantAttempts: 2,
+			wantErr:      errors.IsExternalService,
+		},
+		{
+			name:         "503 retried up to max attempts",
+			status:       http.StatusServiceUnavailable,
+			burst:        5,
+			wantAttempts: 3,
+			wantErr:      errors.IsExternalService,
+		},
+		{
+			name:         "429 not retried",
+			status:       http.StatusTooManyRequests,
+			burst:        5,
+			wantAttempts: 1,
+			wantErr:      errors.IsRateLimited,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				attempts.Add(1)
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			budget := limit.NewBudget(limit.Config{Rate: 0.001, Burst: tt.burst})
+			client := NewWeatherClient("test-key", WithRetryPolicy(fastRetries), WithBaseURL(server.URL), WithBudget(budget))
+
+			if _, err := client.GetWeather(context.Background(), domain.CityQuery("London")); !tt.wantErr(err) {
+				t.Errorf("GetWeather() error = %v, want different error type", err)
+			}
+
+			spent := budget.Stats().Allowed
+			if got := attempts.Load(); got != tt.wantAttempts || uint64(got) > spent {
+				t.Errorf("GetWeather() made %d attempts on %d tokens, want %d attempts and no more than the tokens spent", got, spent, tt.wantAttempts)
+			}
+		})
+	}
+
+	t.Run("exhausted budget makes no request", func(t *testing.T) {
+		var attempts atomic.Int32
+		server := httptest.NewServer(failingThen(&attempts, nil, nil))
+		defer server.Close()
+
+		budget := limit.NewBudget(limit.Config{Rate: 0.001, Burst: 1})
+		client := NewWeatherClient("test-key", WithBaseURL(server.URL), WithBudget(budget))
+		ctx := context.Background()
+
+		client.GetWeather(ctx, domain.CityQuery("London"))
+		_, err := client.GetWeather(ctx, domain.CityQuery("London"))
+		if !errors.IsRateLimited(err) || !stderrors.Is(err, limit.ErrBudgetExhausted) {
+			t.Errorf("GetWeather() error = %v, want the local budget to be exhausted", err)
+		}
+		if got := attempts.Load(); got != 1 {
+			t.Errorf("GetWeather() made %d attempts, want 1", got)
+		}
+	})
+}
+
+func TestWeatherClient_RetryOnTimeout(t *testing.T) {
+	var attempts atomic.Int32
+	client := newTestClient(t, fastRetries, func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) == 1 {
+			select {
+			case <-time.After(time.Second):
+			case <-r.Context().Done():
+			}
+			return
+		}
+		w.Write([]byte(londonResponse))
+	})
+	client.httpClient.Timeout = 50 * time.Millisecond
+
+	if _, err := client.GetWeather(context.Background(), domain.CityQuery("London")); err != nil {
+		t.Fatalf("GetWeather() unexpected error: %v", err)
+	}
+
+	if got := attempts.Load(); got != 2 {
+		t.Errorf("GetWeather() made %d attempts, want 2", got)
+	}
+}
+
+func TestWeatherClient_RetryRespectsDeadline(t *testing.T) {
+	var attempts atomic.Int32
+	policy := RetryPolicy{MaxAttempts: 5, BaseDelay: time.Second, MaxDelay: time.Second}
+	client := newTestClient(t, policy, failingThen(&attempts, []int{503, 503, 503, 503, 503}, nil))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := client.GetWeather(ctx, domain.CityQuery("London"))
+
+	if !errors.IsExternalService(err) {
+		t.Errorf("GetWeather() error = %v, want external service error", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("GetWeather() took %v, should give up before the deadline", elapsed)
+	}
+	if got := attempts.Load(); got != 1 {
+		t.Errorf("GetWeather() made %d attempts, want 1", got)
+	}
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+
+	want := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second, time.Second}
+	for i, expected := range want {
+		if got := policy.backoff(i + 1); got != expected {
+			t.Errorf("backoff(%d) = %v, want %v", i+1, got, expected)
+		}
+	}
+
+	policy.Jitter = 0.5
+	for attempt := 1; attempt <= 5; attempt++ {
+		got := policy.backoff(attempt)
+		ceiling := min(policy.BaseDelay<<(attempt-1), policy.MaxDelay)
+		if got > ceiling || got < ceiling/2 {
+			t.Errorf("backoff(%d) with jitter = %v, want within [%v, %v]", attempt, got, ceiling/2, ceiling)
+		}
+	}
+}
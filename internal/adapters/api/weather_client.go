@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
 	"time"
 
 	"github.com/leinonen/hexagonal-architecture-go/internal/domain"
@@ -12,56 +14,80 @@ import (
 	"github.com/leinonen/hexagonal-architecture-go/internal/ports"
 )
 
+const ProviderOpenWeatherMap = "openweathermap"
+
 type WeatherClient struct {
-	httpClient *http.Client
-	apiKey     string
-	baseURL    string
+	baseClient
+	apiKey string
 }
 
-func NewWeatherClient(apiKey string) ports.WeatherService {
+func NewWeatherClient(apiKey string, opts ...Option) ports.WeatherService {
 	return &WeatherClient{
-		httpClient: &http.Client{
-			Timeout: 10 * time.Second,
-		},
-		apiKey:  apiKey,
-		baseURL: "https://api.openweathermap.org/data/2.5",
+		baseClient: newBaseClient("https://api.openweathermap.org/data/2.5", opts),
+		apiKey:     apiKey,
 	}
 }
 
 type weatherAPIResponse struct {
+	Dt   int64 `json:"dt"`
 	Main struct {
-		Temp     float64 `json:"temp"`
-		Humidity int     `json:"humidity"`
+		Temp      float64 `json:"temp"`
+		FeelsLike float64 `json:"feels_like"`
+		TempMin   float64 `json:"temp_min"`
+		TempMax   float64 `json:"temp_max"`
+		Pressure  float64 `json:"pressure"`
+		Humidity  int     `json:"humidity"`
 	} `json:"main"`
 	Weather []struct {
+		ID          int    `json:"id"`
 		Description string `json:"description"`
 	} `json:"weather"`
+	Visibility float64 `json:"visibility"`
+	Clouds     struct {
+		All int `json:"all"`
+	} `json:"clouds"`
 	Wind struct {
 		Speed float64 `json:"speed"`
+		Deg   int     `json:"deg"`
+		Gust  float64 `json:"gust"`
 	} `json:"wind"`
+	Rain struct {
+		OneHour float64 `json:"1h"`
+	} `json:"rain"`
+	Snow struct {
+		OneHour float64 `json:"1h"`
+	} `json:"snow"`
+	Coord owmCoord `json:"coord"`
+	Sys   struct {
+		Country string `json:"country"`
+		Sunrise int64  `json:"sunrise"`
+		Sunset  int64  `json:"sunset"`
+	} `json:"sys"`
 	Name string `json:"name"`
 }
 
-func (c *WeatherClient) GetWeather(ctx context.Context, city string) (*domain.Weather, error) {
-	url := fmt.Sprintf("%s/weather?q=%s&appid=%s&units=metric", c.baseURL, city, c.apiKey)
+type owmCoord struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-	if err != nil {
-		return nil, errors.NewInternalError(fmt.Sprintf("failed to create request: %v", err))
-	}
+func (c owmCoord) toDomain() domain.Coordinates {
+	return domain.Coordinates{Latitude: c.Lat, Longitude: c.Lon}
+}
+
+func (c *WeatherClient) GetWeather(ctx context.Context, query domain.WeatherQuery) (*domain.Weather, error) {
+	params := owmLocation(query)
+	params.Set("appid", c.apiKey)
+	params.Set("units", "metric")
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.get(ctx, "/weather", params)
 	if err != nil {
-		return nil, errors.NewExternalServiceError(fmt.Sprintf("failed to fetch weather: %v", err))
+		return nil, err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode == http.StatusNotFound {
-		return nil, errors.NewNotFoundError("city not found")
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, errors.NewExternalServiceError(fmt.Sprintf("weather API returned status: %d", resp.StatusCode))
+	if err := checkStatus(resp); err != nil {
+		return nil, err
 	}
 
 	var apiResp weatherAPIResponse
@@ -70,15 +96,193 @@ func (c *WeatherClient) GetWeather(ctx context.Context, city string) (*domain.We
 	}
 
 	weather := &domain.Weather{
-		City:        apiResp.Name,
-		Temperature: apiResp.Main.Temp,
-		Humidity:    apiResp.Main.Humidity,
-		WindSpeed:   apiResp.Wind.Speed,
+		City:           apiResp.Name,
+		Country:        apiResp.Sys.Country,
+		Coordinates:    apiResp.Coord.toDomain(),
+		Temperature:    apiResp.Main.Temp,
+		FeelsLike:      apiResp.Main.FeelsLike,
+		MinTemperature: apiResp.Main.TempMin,
+		MaxTemperature: apiResp.Main.TempMax,
+		Condition:      domain.ConditionUnknown,
+		Humidity:       apiResp.Main.Humidity,
+		Pressure:       apiResp.Main.Pressure,
+		Visibility:     apiResp.Visibility,
+		CloudCover:     apiResp.Clouds.All,
+		WindSpeed:      apiResp.Wind.Speed,
+		WindGust:       apiResp.Wind.Gust,
+		WindDirection:  apiResp.Wind.Deg,
+		Precipitation:  apiResp.Rain.OneHour + apiResp.Snow.OneHour,
+		Sunrise:        unixTime(apiResp.Sys.Sunrise),
+		Sunset:         unixTime(apiResp.Sys.Sunset),
+		ObservedAt:     unixTime(apiResp.Dt),
+		Provider:       ProviderOpenWeatherMap,
+		Units:          domain.UnitsMetric,
+		FetchedAt:      time.Now(),
 	}
 
 	if len(apiResp.Weather) > 0 {
 		weather.Description = apiResp.Weather[0].Description
+		weather.Condition = owmCondition(apiResp.Weather[0].ID)
 	}
 
 	return weather, nil
 }
+
+type forecastAPIResponse struct {
+	List []struct {
+		Dt   int64 `json:"dt"`
+		Main struct {
+			Temp     float64 `json:"temp"`
+			Humidity int     `json:"humidity"`
+		} `json:"main"`
+		Weather []struct {
+			Description string `json:"description"`
+		} `json:"weather"`
+		Wind struct {
+			Speed float64 `json:"speed"`
+		} `json:"wind"`
+		Pop float64 `json:"pop"`
+	} `json:"list"`
+	City struct {
+		Name     string   `json:"name"`
+		Country  string   `json:"country"`
+		Coord    owmCoord `json:"coord"`
+		Timezone int      `json:"timezone"`
+	} `json:"city"`
+}
+
+// GetForecast uses the 5 day / 3 hour forecast, so hourly entries are three
+// hours apart and daily summaries are derived from them.
+func (c *WeatherClient) GetForecast(ctx context.Context, query domain.WeatherQuery, days int) (*domain.Forecast, error) {
+	params := owmLocation(query)
+	params.Set("appid", c.apiKey)
+	params.Set("units", "metric")
+	params.Set("cnt", strconv.Itoa(days*8))
+
+	resp, err := c.get(ctx, "/forecast", params)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := checkStatus(resp); err != nil {
+		return nil, err
+	}
+
+	var apiResp forecastAPIResponse
+	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
+		return nil, errors.NewInternalError(fmt.Sprintf("failed to decode response: %v", err))
+	}
+
+	hourly := make([]domain.HourlyForecast, 0, len(apiResp.List))
+	for _, item := range apiResp.List {
+		hour := domain.HourlyForecast{
+			Time:                time.Unix(item.Dt, 0).UTC(),
+			Temperature:         item.Main.Temp,
+			Humidity:            item.Main.Humidity,
+			WindSpeed:           item.Wind.Speed,
+			PrecipitationChance: item.Pop,
+		}
+		if len(item.Weather) > 0 {
+			hour.Description = item.Weather[0].Description
+		}
+		hourly = append(hourly, hour)
+	}
+
+	// cnt counts 3-hour steps from now, so it usually reaches into one more
+	// calendar day than was asked for. Drop that day from both views.
+	daily := domain.SummarizeDays(hourly, time.FixedZone(apiResp.City.Name, apiResp.City.Timezone))
+	if len(daily) > days {
+		daily = daily[:days]
+		end := daily[days-1].Date.AddDate(0, 0, 1)
+		for i, hour := range hourly {
+			if !hour.Time.Before(end) {
+				hourly = hourly[:i]
+				break
+			}
+		}
+	}
+
+	return &domain.Forecast{
+		City:        apiResp.City.Name,
+		Country:     apiResp.City.Country,
+		Coordinates: apiResp.City.Coord.toDomain(),
+		Provider:    ProviderOpenWeatherMap,
+		Units:       domain.UnitsMetric,
+		Hourly:      hourly,
+		Daily:       daily,
+		FetchedAt:   time.Now(),
+	}, nil
+}
+
+// owmCondition maps OpenWeatherMap's weather condition IDs, which are grouped
+// by hundreds, to a domain.Condition.
+func owmCondition(id int) domain.Condition {
+	switch {
+	case id >= 200 && id < 300:
+		return domain.ConditionThunderstorm
+	case id >= 300 && id < 400:
+		return domain.ConditionDrizzle
+	case id == 511:
+		return domain.ConditionSleet
+	case id >= 500 && id < 600:
+		return domain.ConditionRain
+	case id >= 611 && id <= 616:
+		return domain.ConditionSleet
+	case id >= 600 && id < 700:
+		return domain.ConditionSnow
+	case id >= 700 && id < 800:
+		return domain.ConditionFog
+	case id == 800:
+		return domain.ConditionClear
+	case id == 801 || id == 802:
+		return domain.ConditionPartlyCloudy
+	case id == 803 || id == 804:
+		return domain.ConditionCloudy
+	}
+	return domain.ConditionUnknown
+}
+
+// unixTime leaves fields the provider omitted as the zero time.
+func unixTime(seconds int64) time.Time {
+	if seconds == 0 {
+		return time.Time{}
+	}
+	return time.Unix(seconds, 0).UTC()
+}
+
+func owmLocation(query domain.WeatherQuery) url.Values {
+	params := url.Values{}
+	switch {
+	case query.Coordinates != nil:
+		params.Set("lat", formatCoordinate(query.Coordinates.Latitude))
+		params.Set("lon", formatCoordinate(query.Coordinates.Longitude))
+	case query.Country != "":
+		params.Set("q", query.City+","+query.Country)
+	default:
+		params.Set("q", query.City)
+	}
+	return params
+}
+
+func formatCoordinate(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+type owmErrorResponse struct {
+	Message string `json:"message"`
+}
+
+func checkStatus(resp *http.Response) error {
+	if resp.StatusCode == http.StatusNotFound {
+		return errors.NewNotFoundError("city not found")
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var apiErr owmErrorResponse
+		readErrorBody(resp, &apiErr)
+		return statusError(resp, apiErr.Message)
+	}
+
+	return nil
+}
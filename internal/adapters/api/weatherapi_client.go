@@ -0,0 +1,308 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/leinonen/hexagonal-architecture-go/internal/domain"
+	"github.com/leinonen/hexagonal-architecture-go/internal/errors"
+	"github.com/leinonen/hexagonal-architecture-go/internal/ports"
+)
+
+const ProviderWeatherAPI = "weatherapi"
+
+// weatherAPINoLocation is the error code WeatherAPI returns, with a 400, when
+// no location matches the query.
+const weatherAPINoLocation = 1006
+
+// weatherAPIQuotaExceeded is returned, with a 403, once the account has used
+// up its monthly calls.
+const weatherAPIQuotaExceeded = 2007
+
+// WeatherAPIClient talks to WeatherAPI.com.
+type WeatherAPIClient struct {
+	baseClient
+	apiKey string
+}
+
+func NewWeatherAPIClient(apiKey string, opts ...Option) ports.WeatherService {
+	return &WeatherAPIClient{
+		baseClient: newBaseClient("https://api.weatherapi.com/v1", opts),
+		apiKey:     apiKey,
+	}
+}
+
+type weatherAPILocation struct {
+	Name     string  `json:"name"`
+	Country  string  `json:"country"`
+	Lat      float64 `json:"lat"`
+	Lon      float64 `json:"lon"`
+	TimeZone string  `json:"tz_id"`
+}
+
+func (l weatherAPILocation) coordinates() domain.Coordinates {
+	return domain.Coordinates{Latitude: l.Lat, Longitude: l.Lon}
+}
+
+func (l weatherAPILocation) timeZone() *time.Location {
+	loc, err := time.LoadLocation(l.TimeZone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+type weatherAPICurrentResponse struct {
+	Location weatherAPILocation `json:"location"`
+	Current  struct {
+		LastUpdatedEpoch int64   `json:"last_updated_epoch"`
+		TempC            float64 `json:"temp_c"`
+		FeelsLikeC       float64 `json:"feelslike_c"`
+		Humidity         int     `json:"humidity"`
+		PressureMb       float64 `json:"pressure_mb"`
+		VisKm            float64 `json:"vis_km"`
+		Cloud            int     `json:"cloud"`
+		WindKph          float64 `json:"wind_kph"`
+		GustKph          float64 `json:"gust_kph"`
+		WindDegree       int     `json:"wind_degree"`
+		PrecipMm         float64 `json:"precip_mm"`
+		Condition        struct {
+			Text string `json:"text"`
+			Code int    `json:"code"`
+		} `json:"condition"`
+	} `json:"current"`
+	// Forecast holds today, for the daily range and sun times that
+	// current.json does not report.
+	Forecast struct {
+		ForecastDay []struct {
+			Date string `json:"date"`
+			Day  struct {
+				MaxTempC float64 `json:"maxtemp_c"`
+				MinTempC float64 `json:"mintemp_c"`
+			} `json:"day"`
+			Astro struct {
+				Sunrise string `json:"sunrise"`
+				Sunset  string `json:"sunset"`
+			} `json:"astro"`
+		} `json:"forecastday"`
+	} `json:"forecast"`
+}
+
+type weatherAPIErrorResponse struct {
+	Error struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (c *WeatherAPIClient) GetWeather(ctx context.Context, query domain.WeatherQuery) (*domain.Weather, error) {
+	resp, err := c.get(ctx, "/forecast.json", c.params(query, 1))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := checkWeatherAPIStatus(resp); err != nil {
+		return nil, err
+	}
+
+	var apiResp weatherAPICurrentResponse
+	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
+		return nil, errors.NewInternalError(fmt.Sprintf("failed to decode response: %v", err))
+	}
+
+	current := apiResp.Current
+	weather := &domain.Weather{
+		City:          apiResp.Location.Name,
+		Country:       countryCode(apiResp.Location.Country),
+		Coordinates:   apiResp.Location.coordinates(),
+		Temperature:   current.TempC,
+		FeelsLike:     current.FeelsLikeC,
+		Condition:     weatherAPICondition(current.Condition.Code),
+		Description:   current.Condition.Text,
+		Humidity:      current.Humidity,
+		Pressure:      current.PressureMb,
+		Visibility:    current.VisKm * 1000,
+		CloudCover:    current.Cloud,
+		WindSpeed:     current.WindKph / 3.6,
+		WindGust:      current.GustKph / 3.6,
+		WindDirection: current.WindDegree,
+		Precipitation: current.PrecipMm,
+		ObservedAt:    unixTime(current.LastUpdatedEpoch),
+		Provider:      ProviderWeatherAPI,
+		Units:         domain.UnitsMetric,
+		FetchedAt:     time.Now(),
+	}
+
+	if days := apiResp.Forecast.ForecastDay; len(days) > 0 {
+		loc := apiResp.Location.timeZone()
+		weather.MinTemperature = days[0].Day.MinTempC
+		weather.MaxTemperature = days[0].Day.MaxTempC
+		weather.Sunrise = weatherAPIAstroTime(days[0].Date, days[0].Astro.Sunrise, loc)
+		weather.Sunset = weatherAPIAstroTime(days[0].Date, days[0].Astro.Sunset, loc)
+	}
+
+	return weather, nil
+}
+
+type weatherAPIForecastResponse struct {
+	Location weatherAPILocation `json:"location"`
+	Forecast struct {
+		ForecastDay []struct {
+			Date string `json:"date"`
+			Day  struct {
+				MaxTempC          float64 `json:"maxtemp_c"`
+				MinTempC          float64 `json:"mintemp_c"`
+				AvgHumidity       float64 `json:"avghumidity"`
+				MaxWindKph        float64 `json:"maxwind_kph"`
+				DailyChanceOfRain int     `json:"daily_chance_of_rain"`
+				Condition         struct {
+					Text string `json:"text"`
+				} `json:"condition"`
+			} `json:"day"`
+			Hour []struct {
+				TimeEpoch    int64   `json:"time_epoch"`
+				TempC        float64 `json:"temp_c"`
+				Humidity     int     `json:"humidity"`
+				WindKph      float64 `json:"wind_kph"`
+				ChanceOfRain int     `json:"chance_of_rain"`
+				Condition    struct {
+					Text string `json:"text"`
+				} `json:"condition"`
+			} `json:"hour"`
+		} `json:"forecastday"`
+	} `json:"forecast"`
+}
+
+func (c *WeatherAPIClient) GetForecast(ctx context.Context, query domain.WeatherQuery, days int) (*domain.Forecast, error) {
+	resp, err := c.get(ctx, "/forecast.json", c.params(query, days))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := checkWeatherAPIStatus(resp); err != nil {
+		return nil, err
+	}
+
+	var apiResp weatherAPIForecastResponse
+	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
+		return nil, errors.NewInternalError(fmt.Sprintf("failed to decode response: %v", err))
+	}
+
+	loc := apiResp.Location.timeZone()
+
+	forecast := &domain.Forecast{
+		City:        apiResp.Location.Name,
+		Country:     countryCode(apiResp.Location.Country),
+		Coordinates: apiResp.Location.coordinates(),
+		Provider:    ProviderWeatherAPI,
+		Units:       domain.UnitsMetric,
+		FetchedAt:   time.Now(),
+	}
+
+	for _, day := range apiResp.Forecast.ForecastDay {
+		date, err := time.ParseInLocation("2006-01-02", day.Date, loc)
+		if err != nil {
+			return nil, errors.NewInternalError(fmt.Sprintf("failed to decode response: %v", err))
+		}
+
+		forecast.Daily = append(forecast.Daily, domain.DailyForecast{
+			Date:                date,
+			MinTemperature:      day.Day.MinTempC,
+			MaxTemperature:      day.Day.MaxTempC,
+			Description:         day.Day.Condition.Text,
+			Humidity:            int(day.Day.AvgHumidity + 0.5),
+			MaxWindSpeed:        day.Day.MaxWindKph / 3.6,
+			PrecipitationChance: float64(day.Day.DailyChanceOfRain) / 100,
+		})
+
+		for _, hour := range day.Hour {
+			forecast.Hourly = append(forecast.Hourly, domain.HourlyForecast{
+				Time:                time.Unix(hour.TimeEpoch, 0).UTC(),
+				Temperature:         hour.TempC,
+				Description:         hour.Condition.Text,
+				Humidity:            hour.Humidity,
+				WindSpeed:           hour.WindKph / 3.6,
+				PrecipitationChance: float64(hour.ChanceOfRain) / 100,
+			})
+		}
+	}
+
+	return forecast, nil
+}
+
+// params builds the query string for forecast.json. Its q parameter takes
+// either "lat,lon" or a free-text name.
+func (c *WeatherAPIClient) params(query domain.WeatherQuery, days int) url.Values {
+	location := query.City
+	switch {
+	case query.Coordinates != nil:
+		location = formatCoordinate(query.Coordinates.Latitude) + "," + formatCoordinate(query.Coordinates.Longitude)
+	case query.Country != "":
+		location += "," + query.Country
+	}
+
+	return url.Values{
+		"key":  {c.apiKey},
+		"q":    {location},
+		"days": {strconv.Itoa(days)},
+	}
+}
+
+// weatherAPIAstroTime combines a forecast date with a local sun time such as
+// "05:43 AM". Days without a sunrise or sunset give the zero time.
+func weatherAPIAstroTime(date, clock string, loc *time.Location) time.Time {
+	t, err := time.ParseInLocation("2006-01-02 03:04 PM", date+" "+clock, loc)
+	if err != nil {
+		return time.Time{}
+	}
+	return t.UTC()
+}
+
+// weatherAPICondition maps WeatherAPI's condition codes to a domain.Condition.
+func weatherAPICondition(code int) domain.Condition {
+	switch code {
+	case 1000:
+		return domain.ConditionClear
+	case 1003:
+		return domain.ConditionPartlyCloudy
+	case 1006, 1009:
+		return domain.ConditionCloudy
+	case 1030, 1135, 1147:
+		return domain.ConditionFog
+	case 1150, 1153:
+		return domain.ConditionDrizzle
+	case 1063, 1180, 1183, 1186, 1189, 1192, 1195, 1240, 1243, 1246:
+		return domain.ConditionRain
+	case 1069, 1072, 1168, 1171, 1198, 1201, 1204, 1207, 1237, 1249, 1252, 1261, 1264:
+		return domain.ConditionSleet
+	case 1066, 1114, 1117, 1210, 1213, 1216, 1219, 1222, 1225, 1255, 1258:
+		return domain.ConditionSnow
+	case 1087, 1273, 1276, 1279, 1282:
+		return domain.ConditionThunderstorm
+	}
+	return domain.ConditionUnknown
+}
+
+func checkWeatherAPIStatus(resp *http.Response) error {
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	var apiErr weatherAPIErrorResponse
+	readErrorBody(resp, &apiErr)
+
+	switch apiErr.Error.Code {
+	case weatherAPINoLocation:
+		return errors.NewNotFoundError("city not found")
+	case weatherAPIQuotaExceeded:
+		return errors.WithDetail(errors.NewRateLimitedError("weather API quota exceeded", 0), apiErr.Error.Message)
+	}
+	return statusError(resp, apiErr.Error.Message)
+}
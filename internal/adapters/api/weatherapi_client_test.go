@@ -0,0 +1,150 @@
+package api
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/leinonen/hexagonal-architecture-go/internal/domain"
+	"github.com/leinonen/hexagonal-architecture-go/internal/errors"
+)
+
+func newTestWeatherAPIClient(t *testing.T, handler http.HandlerFunc) *WeatherAPIClient {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	return NewWeatherAPIClient("test-key", WithRetryPolicy(NoRetry()), WithBaseURL(server.URL)).(*WeatherAPIClient)
+}
+
+const londonWeatherAPIResponse = `{
+	"location": {"name": "London", "country": "United Kingdom", "lat": 51.52, "lon": -0.11, "tz_id": "Europe/London"},
+	"current": {
+		"last_updated_epoch": 1717243200, "temp_c": 11.5, "feelslike_c": 9.8, "humidity": 81, "pressure_mb": 1012,
+		"vis_km": 10, "cloud": 75, "wind_kph": 18, "gust_kph": 36, "wind_degree": 250, "precip_mm": 0.4,
+		"condition": {"text": "Light rain", "code": 1183}
+	},
+	"forecast": {"forecastday": [{
+		"date": "2024-06-01",
+		"day": {"maxtemp_c": 14.2, "mintemp_c": 8.1},
+		"astro": {"sunrise": "04:46 AM", "sunset": "09:09 PM"}
+	}]}
+}`
+
+func TestWeatherAPIClient_GetWeather(t *testing.T) {
+	client := newTestWeatherAPIClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/forecast.json" || r.URL.Query().Get("key") != "test-key" || r.URL.Query().Get("days") != "1" {
+			t.Errorf("unexpected request %s", r.URL)
+		}
+		w.Write([]byte(londonWeatherAPIResponse))
+	})
+
+	weather, err := client.GetWeather(context.Background(), domain.CityQuery("London"))
+	if err != nil {
+		t.Fatalf("GetWeather() unexpected error: %v", err)
+	}
+
+	if weather.City != "London" || weather.Temperature != 11.5 || weather.Humidity != 81 || weather.Description != "Light rain" {
+		t.Errorf("GetWeather() = %+v, want mapped London weather", weather)
+	}
+	if weather.Country != "GB" || weather.Place(domain.CityQuery("London")).Validate() != nil {
+		t.Errorf("GetWeather() country = %q, want the ISO code GB for United Kingdom", weather.Country)
+	}
+	if math.Abs(weather.WindSpeed-5) > 1e-9 || math.Abs(weather.WindGust-10) > 1e-9 || weather.WindDirection != 250 {
+		t.Errorf("GetWeather() wind = %v m/s gusting %v from %v°, want 5 gusting 10 from 250°", weather.WindSpeed, weather.WindGust, weather.WindDirection)
+	}
+	if weather.FeelsLike != 9.8 || weather.MinTemperature != 8.1 || weather.MaxTemperature != 14.2 {
+		t.Errorf("GetWeather() feels like/min/max = %v/%v/%v", weather.FeelsLike, weather.MinTemperature, weather.MaxTemperature)
+	}
+	if weather.Pressure != 1012 || weather.Visibility != 10000 || weather.CloudCover != 75 || weather.Precipitation != 0.4 {
+		t.Errorf("GetWeather() = %+v, want pressure, visibility in metres, cloud cover and precipitation", weather)
+	}
+	if weather.Condition != domain.ConditionRain {
+		t.Errorf("GetWeather() condition = %v, want %v", weather.Condition, domain.ConditionRain)
+	}
+	if !weather.ObservedAt.Equal(time.Unix(1717243200, 0)) {
+		t.Errorf("GetWeather() observed at = %v", weather.ObservedAt)
+	}
+	// London is on BST (UTC+1) in June.
+	if !weather.Sunrise.Equal(time.Date(2024, 6, 1, 3, 46, 0, 0, time.UTC)) || !weather.Sunset.Equal(time.Date(2024, 6, 1, 20, 9, 0, 0, time.UTC)) {
+		t.Errorf("GetWeather() sunrise/sunset = %v/%v", weather.Sunrise, weather.Sunset)
+	}
+	if weather.Provider != ProviderWeatherAPI {
+		t.Errorf("GetWeather() provider = %v, want %v", weather.Provider, ProviderWeatherAPI)
+	}
+}
+
+func TestCountryCode(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "United Kingdom", want: "GB"},
+		{name: "United States of America", want: "US"},
+		{name: " france ", want: "FR"},
+		{name: "Atlantis", want: ""},
+		{name: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		if got := countryCode(tt.name); got != tt.want {
+			t.Errorf("countryCode(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestWeatherAPIClient_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantErr func(error) bool
+	}{
+		{
+			name:    "no matching location",
+			status:  http.StatusBadRequest,
+			body:    `{"error":{"code":1006,"message":"No matching location found."}}`,
+			wantErr: errors.IsNotFound,
+		},
+		{
+			name:    "other bad request",
+			status:  http.StatusBadRequest,
+			body:    `{"error":{"code":1003,"message":"Parameter q is missing."}}`,
+			wantErr: errors.IsExternalService,
+		},
+		{
+			name:    "invalid key",
+			status:  http.StatusUnauthorized,
+			body:    `{"error":{"code":2006,"message":"API key is invalid."}}`,
+			wantErr: errors.IsMisconfiguration,
+		},
+		{
+			name:    "quota exceeded",
+			status:  http.StatusForbidden,
+			body:    `{"error":{"code":2007,"message":"API key has exceeded calls per month quota."}}`,
+			wantErr: errors.IsRateLimited,
+		},
+		{
+			name:    "server error without body",
+			status:  http.StatusInternalServerError,
+			wantErr: errors.IsExternalService,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestWeatherAPIClient(t, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			})
+
+			if _, err := client.GetWeather(context.Background(), domain.CityQuery("Atlantis")); !tt.wantErr(err) {
+				t.Errorf("GetWeather() error = %v, want different error type", err)
+			}
+		})
+	}
+}
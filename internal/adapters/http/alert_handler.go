@@ -0,0 +1,81 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/leinonen/hexagonal-architecture-go/internal/domain"
+	"github.com/leinonen/hexagonal-architecture-go/internal/dto"
+	"github.com/leinonen/hexagonal-architecture-go/internal/errors"
+)
+
+func (h *Handler) CreateAlertRule(w http.ResponseWriter, r *http.Request) {
+	userID := r.PathValue("id")
+
+	var req dto.AlertRuleDTO
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondWithError(w, errors.NewValidationError("invalid request body"))
+		return
+	}
+	if req.Threshold == nil {
+		h.respondWithError(w, errors.NewValidationError("threshold is required"))
+		return
+	}
+
+	query, err := toWeatherQuery(req.WeatherQueryDTO)
+	if err != nil {
+		h.respondWithError(w, err)
+		return
+	}
+
+	rule, err := h.alertService.CreateAlertRule(r.Context(), userID, query, domain.AlertMetric(req.Metric), domain.Comparator(req.Comparator), *req.Threshold, domain.Units(req.Units))
+	if err != nil {
+		h.respondWithError(w, err)
+		return
+	}
+
+	response := dto.ToAlertRuleResponseDTO(rule)
+
+	h.respondWithJSON(w, http.StatusCreated, response)
+}
+
+func (h *Handler) ListAlertRules(w http.ResponseWriter, r *http.Request) {
+	userID := r.PathValue("id")
+
+	rules, err := h.alertService.ListAlertRules(r.Context(), userID)
+	if err != nil {
+		h.respondWithError(w, err)
+		return
+	}
+
+	response := dto.ToAlertRuleResponseDTOs(rules)
+
+	h.respondWithJSON(w, http.StatusOK, response)
+}
+
+func (h *Handler) GetAlertRule(w http.ResponseWriter, r *http.Request) {
+	userID := r.PathValue("id")
+	ruleID := r.PathValue("alertID")
+
+	rule, err := h.alertService.GetAlertRule(r.Context(), userID, ruleID)
+	if err != nil {
+		h.respondWithError(w, err)
+		return
+	}
+
+	response := dto.ToAlertRuleResponseDTO(rule)
+
+	h.respondWithJSON(w, http.StatusOK, response)
+}
+
+func (h *Handler) DeleteAlertRule(w http.ResponseWriter, r *http.Request) {
+	userID := r.PathValue("id")
+	ruleID := r.PathValue("alertID")
+
+	if err := h.alertService.DeleteAlertRule(r.Context(), userID, ruleID); err != nil {
+		h.respondWithError(w, err)
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusNoContent, nil)
+}
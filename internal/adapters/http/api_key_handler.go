@@ -0,0 +1,55 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/leinonen/hexagonal-architecture-go/internal/dto"
+	"github.com/leinonen/hexagonal-architecture-go/internal/errors"
+)
+
+func (h *Handler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	userID := r.PathValue("id")
+
+	var req dto.CreateAPIKeyDTO
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondWithError(w, errors.NewValidationError("invalid request body"))
+		return
+	}
+
+	key, secret, err := h.authService.IssueAPIKey(r.Context(), userID, req.Name)
+	if err != nil {
+		h.respondWithError(w, err)
+		return
+	}
+
+	response := dto.ToIssuedAPIKeyResponseDTO(key, secret)
+
+	h.respondWithJSON(w, http.StatusCreated, response)
+}
+
+func (h *Handler) ListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	userID := r.PathValue("id")
+
+	keys, err := h.authService.ListAPIKeys(r.Context(), userID)
+	if err != nil {
+		h.respondWithError(w, err)
+		return
+	}
+
+	response := dto.ToAPIKeyResponseDTOs(keys)
+
+	h.respondWithJSON(w, http.StatusOK, response)
+}
+
+func (h *Handler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	userID := r.PathValue("id")
+	keyID := r.PathValue("keyID")
+
+	if err := h.authService.RevokeAPIKey(r.Context(), userID, keyID); err != nil {
+		h.respondWithError(w, err)
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusNoContent, nil)
+}
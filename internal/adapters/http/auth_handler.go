@@ -0,0 +1,102 @@
+package http
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+
+	"github.com/leinonen/hexagonal-architecture-go/internal/domain"
+	"github.com/leinonen/hexagonal-architecture-go/internal/dto"
+	"github.com/leinonen/hexagonal-architecture-go/internal/errors"
+)
+
+func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
+	var req dto.LoginDTO
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondWithError(w, errors.NewValidationError("invalid request body"))
+		return
+	}
+
+	tokens, err := h.authService.Login(r.Context(), req.Email, req.Password, clientInfo(r))
+	if err != nil {
+		h.respondWithError(w, err)
+		return
+	}
+
+	response := dto.ToTokenResponseDTO(tokens)
+
+	w.Header().Set("Cache-Control", "no-store")
+	h.respondWithJSON(w, http.StatusOK, response)
+}
+
+func (h *Handler) Refresh(w http.ResponseWriter, r *http.Request) {
+	var req dto.RefreshTokenDTO
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondWithError(w, errors.NewValidationError("invalid request body"))
+		return
+	}
+
+	tokens, err := h.authService.Refresh(r.Context(), req.RefreshToken)
+	if err != nil {
+		h.respondWithError(w, err)
+		return
+	}
+
+	response := dto.ToTokenResponseDTO(tokens)
+
+	w.Header().Set("Cache-Control", "no-store")
+	h.respondWithJSON(w, http.StatusOK, response)
+}
+
+func (h *Handler) Logout(w http.ResponseWriter, r *http.Request) {
+	var req dto.RefreshTokenDTO
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondWithError(w, errors.NewValidationError("invalid request body"))
+		return
+	}
+
+	if err := h.authService.Logout(r.Context(), req.RefreshToken); err != nil {
+		h.respondWithError(w, err)
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusNoContent, nil)
+}
+
+func (h *Handler) ListSessions(w http.ResponseWriter, r *http.Request) {
+	userID := r.PathValue("id")
+
+	sessions, err := h.authService.ListSessions(r.Context(), userID)
+	if err != nil {
+		h.respondWithError(w, err)
+		return
+	}
+
+	response := dto.ToSessionResponseDTOs(sessions)
+
+	h.respondWithJSON(w, http.StatusOK, response)
+}
+
+func (h *Handler) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	userID := r.PathValue("id")
+	sessionID := r.PathValue("sessionID")
+
+	if err := h.authService.RevokeSession(r.Context(), userID, sessionID); err != nil {
+		h.respondWithError(w, err)
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusNoContent, nil)
+}
+
+func clientInfo(r *http.Request) domain.ClientInfo {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+
+	return domain.ClientInfo{
+		UserAgent: r.UserAgent(),
+		IPAddress: ip,
+	}
+}
@@ -3,66 +3,120 @@ package http
 import (
 	"encoding/json"
 	"log"
+	"math"
 	"net/http"
+	"strconv"
 
 	"github.com/leinonen/hexagonal-architecture-go/internal/application"
 	"github.com/leinonen/hexagonal-architecture-go/internal/errors"
 )
 
 type Handler struct {
-	userService    *application.UserService
-	weatherService *application.WeatherService
+	userService     *application.UserService
+	weatherService  *application.WeatherService
+	authService     *application.AuthService
+	healthService   *application.HealthService
+	locationService *application.LocationService
+	alertService    *application.AlertService
 }
 
-func NewHandler(userService *application.UserService, weatherService *application.WeatherService) *Handler {
+func NewHandler(userService *application.UserService, weatherService *application.WeatherService, authService *application.AuthService, healthService *application.HealthService, locationService *application.LocationService, alertService *application.AlertService) *Handler {
 	return &Handler{
-		userService:    userService,
-		weatherService: weatherService,
+		userService:     userService,
+		weatherService:  weatherService,
+		authService:     authService,
+		healthService:   healthService,
+		locationService: locationService,
+		alertService:    alertService,
 	}
 }
 
 func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("POST /api/users", h.CreateUser)
-	mux.HandleFunc("GET /api/users", h.ListUsers)
-	mux.HandleFunc("GET /api/users/{id}", h.GetUser)
-	mux.HandleFunc("PUT /api/users/{id}", h.UpdateUser)
-	mux.HandleFunc("DELETE /api/users/{id}", h.DeleteUser)
+	mux.HandleFunc("GET /api/users", h.Authenticate(h.ListUsers))
+	mux.HandleFunc("GET /api/users/{id}", h.Authenticate(h.GetUser))
+	mux.HandleFunc("PUT /api/users/{id}", h.Authenticate(h.UpdateUser))
+	mux.HandleFunc("DELETE /api/users/{id}", h.Authenticate(h.DeleteUser))
+	mux.HandleFunc("PUT /api/users/{id}/password", h.Authenticate(h.ChangePassword))
+
+	mux.HandleFunc("GET /api/users/{id}/sessions", h.Authenticate(h.ListSessions))
+	mux.HandleFunc("DELETE /api/users/{id}/sessions/{sessionID}", h.Authenticate(h.RevokeSession))
+
+	mux.HandleFunc("POST /api/auth/login", h.Login)
+	mux.HandleFunc("POST /api/auth/refresh", h.Refresh)
+	mux.HandleFunc("POST /api/auth/logout", h.Logout)
+
+	mux.HandleFunc("POST /api/users/{id}/api-keys", h.Authenticate(h.CreateAPIKey))
+	mux.HandleFunc("GET /api/users/{id}/api-keys", h.Authenticate(h.ListAPIKeys))
+	mux.HandleFunc("DELETE /api/users/{id}/api-keys/{keyID}", h.Authenticate(h.RevokeAPIKey))
+
+	mux.HandleFunc("POST /api/users/{id}/locations", h.Authenticate(h.CreateLocation))
+	mux.HandleFunc("GET /api/users/{id}/locations", h.Authenticate(h.ListLocations))
+	mux.HandleFunc("GET /api/users/{id}/locations/{locationID}", h.Authenticate(h.GetLocation))
+	mux.HandleFunc("PUT /api/users/{id}/locations/{locationID}", h.Authenticate(h.UpdateLocation))
+	mux.HandleFunc("DELETE /api/users/{id}/locations/{locationID}", h.Authenticate(h.DeleteLocation))
+
+	mux.HandleFunc("POST /api/users/{id}/alerts", h.Authenticate(h.CreateAlertRule))
+	mux.HandleFunc("GET /api/users/{id}/alerts", h.Authenticate(h.ListAlertRules))
+	mux.HandleFunc("GET /api/users/{id}/alerts/{alertID}", h.Authenticate(h.GetAlertRule))
+	mux.HandleFunc("DELETE /api/users/{id}/alerts/{alertID}", h.Authenticate(h.DeleteAlertRule))
 
 	mux.HandleFunc("GET /api/weather", h.GetWeather)
-	mux.HandleFunc("GET /api/users/{id}/weather", h.GetUserWeather)
+	mux.HandleFunc("GET /api/weather/forecast", h.GetForecast)
+	mux.HandleFunc("POST /api/weather/batch", h.GetWeatherBatch)
+	mux.HandleFunc("GET /api/weather/history", h.GetWeatherHistory)
+	mux.HandleFunc("GET /api/geocode", h.SearchPlaces)
+	mux.HandleFunc("GET /api/users/{id}/weather", h.Authenticate(h.GetUserWeather))
+	mux.HandleFunc("GET /api/users/{id}/dashboard", h.Authenticate(h.GetUserDashboard))
 
 	mux.HandleFunc("GET /health", h.Health)
+	mux.HandleFunc("GET /ready", h.Ready)
 }
 
 func (h *Handler) respondWithError(w http.ResponseWriter, err error) {
-	var status int
-	var message string
+	status, message := errorStatus(err)
+
+	switch {
+	case errors.IsUnauthorized(err):
+		w.Header().Set("WWW-Authenticate", `Bearer realm="api"`)
+	case errors.IsRateLimited(err):
+		if retryAfter, ok := errors.RetryAfter(err); ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+		}
+	}
 
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
+
+// errorStatus maps an error to the HTTP status and the message clients see.
+// Details of internal and upstream failures are logged, never returned.
+func errorStatus(err error) (int, string) {
 	switch {
 	case errors.IsNotFound(err):
-		status = http.StatusNotFound
-		message = err.Error()
+		return http.StatusNotFound, err.Error()
 	case errors.IsValidation(err):
-		status = http.StatusBadRequest
-		message = err.Error()
+		return http.StatusBadRequest, err.Error()
 	case errors.IsConflict(err):
-		status = http.StatusConflict
-		message = err.Error()
+		return http.StatusConflict, err.Error()
 	case errors.IsUnauthorized(err):
-		status = http.StatusUnauthorized
-		message = err.Error()
+		return http.StatusUnauthorized, err.Error()
+	case errors.IsForbidden(err):
+		return http.StatusForbidden, err.Error()
+	case errors.IsRateLimited(err):
+		log.Printf("External service rate limited: %v", err)
+		return http.StatusTooManyRequests, "External service rate limit exceeded"
+	case errors.IsMisconfiguration(err):
+		log.Printf("ERROR: external service misconfigured: %v", err)
+		return http.StatusServiceUnavailable, "External service unavailable"
 	case errors.IsExternalService(err):
-		status = http.StatusServiceUnavailable
-		message = "External service unavailable"
+		log.Printf("External service error: %v", err)
+		return http.StatusServiceUnavailable, "External service unavailable"
 	default:
-		status = http.StatusInternalServerError
-		message = "Internal server error"
 		log.Printf("Internal error: %v", err)
+		return http.StatusInternalServerError, "Internal server error"
 	}
-
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
-	json.NewEncoder(w).Encode(map[string]string{"error": message})
 }
 
 func (h *Handler) respondWithJSON(w http.ResponseWriter, status int, data any) {
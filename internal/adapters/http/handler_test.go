@@ -6,12 +6,18 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/leinonen/hexagonal-architecture-go/internal/adapters/api/failover"
 	httpHandler "github.com/leinonen/hexagonal-architecture-go/internal/adapters/http"
+	"github.com/leinonen/hexagonal-architecture-go/internal/adapters/repository/memory"
 	"github.com/leinonen/hexagonal-architecture-go/internal/application"
 	"github.com/leinonen/hexagonal-architecture-go/internal/domain"
+	"github.com/leinonen/hexagonal-architecture-go/internal/dto"
 	"github.com/leinonen/hexagonal-architecture-go/internal/errors"
+	"github.com/leinonen/hexagonal-architecture-go/internal/ports"
 )
 
 type mockUserRepo struct {
@@ -65,6 +71,24 @@ func (m *mockUserRepo) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
+func (m *mockUserRepo) RecordFailedLogin(ctx context.Context, id string, now time.Time) error {
+	user, exists := m.users[id]
+	if !exists {
+		return errors.NewNotFoundError("user not found")
+	}
+	user.RecordFailedLogin(now)
+	return nil
+}
+
+func (m *mockUserRepo) ResetFailedLogins(ctx context.Context, id string) error {
+	user, exists := m.users[id]
+	if !exists {
+		return errors.NewNotFoundError("user not found")
+	}
+	user.RecordSuccessfulLogin()
+	return nil
+}
+
 func (m *mockUserRepo) List(ctx context.Context, limit, offset int) ([]*domain.User, error) {
 	users := make([]*domain.User, 0, len(m.users))
 	for _, user := range m.users {
@@ -75,6 +99,7 @@ func (m *mockUserRepo) List(ctx context.Context, limit, offset int) ([]*domain.U
 
 type mockWeatherService struct {
 	weather map[string]*domain.Weather
+	errs    map[string]error
 }
 
 func newMockWeatherService() *mockWeatherService {
@@ -88,22 +113,54 @@ func newMockWeatherService() *mockWeatherService {
 				WindSpeed:   5.5,
 			},
 		},
+		errs: map[string]error{
+			"Throttled":     errors.NewRateLimitedError("weather API rate limit exceeded", 1500*time.Millisecond),
+			"Misconfigured": errors.NewMisconfigurationError("weather API rejected our credentials with status: 401"),
+			"Down":          errors.NewExternalServiceError("weather API returned status: 500"),
+		},
 	}
 }
 
-func (m *mockWeatherService) GetWeather(ctx context.Context, city string) (*domain.Weather, error) {
-	weather, exists := m.weather[city]
+func (m *mockWeatherService) GetWeather(ctx context.Context, query domain.WeatherQuery) (*domain.Weather, error) {
+	if query.Coordinates != nil {
+		return &domain.Weather{City: "Somewhere", Coordinates: *query.Coordinates}, nil
+	}
+
+	if err := m.errs[query.City]; err != nil {
+		return nil, err
+	}
+
+	weather, exists := m.weather[query.City]
 	if !exists {
 		return nil, errors.NewNotFoundError("city not found")
 	}
 	return weather, nil
 }
 
+func (m *mockWeatherService) GetForecast(ctx context.Context, query domain.WeatherQuery, days int) (*domain.Forecast, error) {
+	if _, exists := m.weather[query.City]; !exists {
+		return nil, errors.NewNotFoundError("city not found")
+	}
+
+	forecast := &domain.Forecast{City: query.City}
+	start := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < days; i++ {
+		forecast.Daily = append(forecast.Daily, domain.DailyForecast{Date: start.AddDate(0, 0, i), MinTemperature: 10, MaxTemperature: 20})
+	}
+	return forecast, nil
+}
+
+func asAdmin(req *http.Request) *http.Request {
+	principal := &domain.Principal{UserID: "admin_id", Role: domain.RoleAdmin}
+	return req.WithContext(application.WithPrincipal(req.Context(), principal))
+}
+
 func TestHandler_CreateUser(t *testing.T) {
 	userRepo := newMockUserRepo()
-	userService := application.NewUserService(userRepo)
+	userService := application.NewUserService(userRepo, nil, nil)
 	weatherService := application.NewWeatherService(newMockWeatherService(), userRepo)
-	handler := httpHandler.NewHandler(userService, weatherService)
+	authService := application.NewAuthService(memory.NewAPIKeyRepository(), memory.NewSessionRepository(), userRepo, nil, nil, nil)
+	handler := httpHandler.NewHandler(userService, weatherService, authService, application.NewHealthService(), nil, nil)
 
 	tests := []struct {
 		name       string
@@ -169,9 +226,10 @@ func TestHandler_GetUser(t *testing.T) {
 	testUser.ID = "test_id"
 	userRepo.users["test_id"] = testUser
 
-	userService := application.NewUserService(userRepo)
+	userService := application.NewUserService(userRepo, nil, nil)
 	weatherService := application.NewWeatherService(newMockWeatherService(), userRepo)
-	handler := httpHandler.NewHandler(userService, weatherService)
+	authService := application.NewAuthService(memory.NewAPIKeyRepository(), memory.NewSessionRepository(), userRepo, nil, nil, nil)
+	handler := httpHandler.NewHandler(userService, weatherService, authService, application.NewHealthService(), nil, nil)
 
 	tests := []struct {
 		name       string
@@ -192,7 +250,7 @@ func TestHandler_GetUser(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			req := httptest.NewRequest("GET", "/api/users/"+tt.userID, nil)
+			req := asAdmin(httptest.NewRequest("GET", "/api/users/"+tt.userID, nil))
 			req.SetPathValue("id", tt.userID)
 
 			w := httptest.NewRecorder()
@@ -220,9 +278,10 @@ func TestHandler_UpdateUser(t *testing.T) {
 	testUser.ID = "test_id"
 	userRepo.users["test_id"] = testUser
 
-	userService := application.NewUserService(userRepo)
+	userService := application.NewUserService(userRepo, nil, nil)
 	weatherService := application.NewWeatherService(newMockWeatherService(), userRepo)
-	handler := httpHandler.NewHandler(userService, weatherService)
+	authService := application.NewAuthService(memory.NewAPIKeyRepository(), memory.NewSessionRepository(), userRepo, nil, nil, nil)
+	handler := httpHandler.NewHandler(userService, weatherService, authService, application.NewHealthService(), nil, nil)
 
 	tests := []struct {
 		name       string
@@ -254,12 +313,28 @@ func TestHandler_UpdateUser(t *testing.T) {
 			},
 			wantStatus: http.StatusNotFound,
 		},
+		{
+			name:   "promote to admin",
+			userID: "test_id",
+			body: map[string]string{
+				"role": "admin",
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:   "invalid role",
+			userID: "test_id",
+			body: map[string]string{
+				"role": "superuser",
+			},
+			wantStatus: http.StatusBadRequest,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			body, _ := json.Marshal(tt.body)
-			req := httptest.NewRequest("PUT", "/api/users/"+tt.userID, bytes.NewReader(body))
+			req := asAdmin(httptest.NewRequest("PUT", "/api/users/"+tt.userID, bytes.NewReader(body)))
 			req.Header.Set("Content-Type", "application/json")
 			req.SetPathValue("id", tt.userID)
 
@@ -279,9 +354,10 @@ func TestHandler_DeleteUser(t *testing.T) {
 	testUser.ID = "test_id"
 	userRepo.users["test_id"] = testUser
 
-	userService := application.NewUserService(userRepo)
+	userService := application.NewUserService(userRepo, nil, nil)
 	weatherService := application.NewWeatherService(newMockWeatherService(), userRepo)
-	handler := httpHandler.NewHandler(userService, weatherService)
+	authService := application.NewAuthService(memory.NewAPIKeyRepository(), memory.NewSessionRepository(), userRepo, nil, nil, nil)
+	handler := httpHandler.NewHandler(userService, weatherService, authService, application.NewHealthService(), nil, nil)
 
 	tests := []struct {
 		name       string
@@ -302,7 +378,7 @@ func TestHandler_DeleteUser(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			req := httptest.NewRequest("DELETE", "/api/users/"+tt.userID, nil)
+			req := asAdmin(httptest.NewRequest("DELETE", "/api/users/"+tt.userID, nil))
 			req.SetPathValue("id", tt.userID)
 
 			w := httptest.NewRecorder()
@@ -323,11 +399,12 @@ func TestHandler_ListUsers(t *testing.T) {
 		userRepo.users[user.ID] = user
 	}
 
-	userService := application.NewUserService(userRepo)
+	userService := application.NewUserService(userRepo, nil, nil)
 	weatherService := application.NewWeatherService(newMockWeatherService(), userRepo)
-	handler := httpHandler.NewHandler(userService, weatherService)
+	authService := application.NewAuthService(memory.NewAPIKeyRepository(), memory.NewSessionRepository(), userRepo, nil, nil, nil)
+	handler := httpHandler.NewHandler(userService, weatherService, authService, application.NewHealthService(), nil, nil)
 
-	req := httptest.NewRequest("GET", "/api/users?limit=10&offset=0", nil)
+	req := asAdmin(httptest.NewRequest("GET", "/api/users?limit=10&offset=0", nil))
 	w := httptest.NewRecorder()
 
 	handler.ListUsers(w, req)
@@ -346,9 +423,10 @@ func TestHandler_ListUsers(t *testing.T) {
 
 func TestHandler_GetWeather(t *testing.T) {
 	userRepo := newMockUserRepo()
-	userService := application.NewUserService(userRepo)
+	userService := application.NewUserService(userRepo, nil, nil)
 	weatherService := application.NewWeatherService(newMockWeatherService(), userRepo)
-	handler := httpHandler.NewHandler(userService, weatherService)
+	authService := application.NewAuthService(memory.NewAPIKeyRepository(), memory.NewSessionRepository(), userRepo, nil, nil, nil)
+	handler := httpHandler.NewHandler(userService, weatherService, authService, application.NewHealthService(), nil, nil)
 
 	tests := []struct {
 		name       string
@@ -395,30 +473,497 @@ func TestHandler_GetWeather(t *testing.T) {
 				if response["city"] != tt.city {
 					t.Errorf("GetWeather() city = %v, want %v", response["city"], tt.city)
 				}
+				if response["version"] != float64(dto.WeatherResponseVersion) {
+					t.Errorf("GetWeather() version = %v, want %v", response["version"], dto.WeatherResponseVersion)
+				}
 			}
 		})
 	}
 }
 
-func TestHandler_Health(t *testing.T) {
+func TestHandler_GetWeatherUpstreamErrors(t *testing.T) {
 	userRepo := newMockUserRepo()
-	userService := application.NewUserService(userRepo)
+	userService := application.NewUserService(userRepo, nil, nil)
 	weatherService := application.NewWeatherService(newMockWeatherService(), userRepo)
-	handler := httpHandler.NewHandler(userService, weatherService)
+	authService := application.NewAuthService(memory.NewAPIKeyRepository(), memory.NewSessionRepository(), userRepo, nil, nil, nil)
+	handler := httpHandler.NewHandler(userService, weatherService, authService, application.NewHealthService(), nil, nil)
+
+	tests := []struct {
+		city           string
+		wantStatus     int
+		wantRetryAfter string
+	}{
+		{city: "Throttled", wantStatus: http.StatusTooManyRequests, wantRetryAfter: "2"},
+		{city: "Misconfigured", wantStatus: http.StatusServiceUnavailable},
+		{city: "Down", wantStatus: http.StatusServiceUnavailable},
+	}
 
-	req := httptest.NewRequest("GET", "/health", nil)
+	for _, tt := range tests {
+		t.Run(tt.city, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/weather?city="+tt.city, nil)
+			w := httptest.NewRecorder()
+
+			handler.GetWeather(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("GetWeather() status = %v, want %v", w.Code, tt.wantStatus)
+			}
+			if got := w.Header().Get("Retry-After"); got != tt.wantRetryAfter {
+				t.Errorf("GetWeather() Retry-After = %q, want %q", got, tt.wantRetryAfter)
+			}
+			if strings.Contains(w.Body.String(), "401") {
+				t.Errorf("GetWeather() leaked upstream details: %s", w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandler_GetWeatherBatch(t *testing.T) {
+	userRepo := newMockUserRepo()
+	userService := application.NewUserService(userRepo, nil, nil)
+	weatherService := application.NewWeatherService(newMockWeatherService(), userRepo)
+	authService := application.NewAuthService(memory.NewAPIKeyRepository(), memory.NewSessionRepository(), userRepo, nil, nil, nil)
+	handler := httpHandler.NewHandler(userService, weatherService, authService, application.NewHealthService(), nil, nil)
+
+	t.Run("per-place results", func(t *testing.T) {
+		body := `{"places":[{"city":"London"},{"city":"NonExisting"},{"latitude":51.5,"longitude":-0.12},{"city":"Throttled"}]}`
+		req := httptest.NewRequest("POST", "/api/weather/batch?units=imperial", strings.NewReader(body))
+		w := httptest.NewRecorder()
+
+		handler.GetWeatherBatch(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("GetWeatherBatch() status = %v, want %v", w.Code, http.StatusOK)
+		}
+
+		var response dto.BatchWeatherResponseDTO
+		json.NewDecoder(w.Body).Decode(&response)
+
+		wantStatuses := []int{http.StatusOK, http.StatusNotFound, http.StatusOK, http.StatusTooManyRequests}
+		if len(response.Results) != len(wantStatuses) {
+			t.Fatalf("GetWeatherBatch() returned %d results, want %d", len(response.Results), len(wantStatuses))
+		}
+		for i, result := range response.Results {
+			if result.Status != wantStatuses[i] {
+				t.Errorf("result %d status = %v, want %v", i, result.Status, wantStatuses[i])
+			}
+			if (result.Weather != nil) != (wantStatuses[i] == http.StatusOK) || (result.Error != "") == (wantStatuses[i] == http.StatusOK) {
+				t.Errorf("result %d = %+v, want either weather or an error", i, result)
+			}
+		}
+		if london := response.Results[0]; london.Query.City != "London" || london.Weather.Units != "imperial" {
+			t.Errorf("result for London = %+v, want imperial weather", london)
+		}
+	})
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{name: "malformed body", body: `{"places":`},
+		{name: "no places", body: `{"places":[]}`},
+		{name: "latitude without longitude", body: `{"places":[{"latitude":51.5}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/api/weather/batch", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			handler.GetWeatherBatch(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("GetWeatherBatch() status = %v, want %v", w.Code, http.StatusBadRequest)
+			}
+		})
+	}
+}
+
+func TestHandler_MalformedBody(t *testing.T) {
+	userRepo := newMockUserRepo()
+	userService := application.NewUserService(userRepo, nil, nil)
+	weatherService := application.NewWeatherService(newMockWeatherService(), userRepo)
+	authService := application.NewAuthService(memory.NewAPIKeyRepository(), memory.NewSessionRepository(), userRepo, nil, nil, nil)
+	handler := httpHandler.NewHandler(userService, weatherService, authService, application.NewHealthService(), nil, nil)
+
+	tests := []struct {
+		name   string
+		handle http.HandlerFunc
+	}{
+		{name: "login", handle: handler.Login},
+		{name: "refresh", handle: handler.Refresh},
+		{name: "logout", handle: handler.Logout},
+		{name: "create api key", handle: handler.CreateAPIKey},
+		{name: "change password", handle: handler.ChangePassword},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/", strings.NewReader(`{"email":`))
+			w := httptest.NewRecorder()
+
+			tt.handle(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("status = %v, want %v", w.Code, http.StatusBadRequest)
+			}
+		})
+	}
+}
+
+func TestHandler_GetWeatherByCoordinates(t *testing.T) {
+	userRepo := newMockUserRepo()
+	userService := application.NewUserService(userRepo, nil, nil)
+	weatherService := application.NewWeatherService(newMockWeatherService(), userRepo)
+	authService := application.NewAuthService(memory.NewAPIKeyRepository(), memory.NewSessionRepository(), userRepo, nil, nil, nil)
+	handler := httpHandler.NewHandler(userService, weatherService, authService, application.NewHealthService(), nil, nil)
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+	}{
+		{name: "valid coordinates", query: "lat=48.8566&lon=2.3522", wantStatus: http.StatusOK},
+		{name: "city with country", query: "city=London&country=GB", wantStatus: http.StatusOK},
+		{name: "latitude out of range", query: "lat=91&lon=0", wantStatus: http.StatusBadRequest},
+		{name: "longitude out of range", query: "lat=0&lon=-180.5", wantStatus: http.StatusBadRequest},
+		{name: "not a number", query: "lat=north&lon=0", wantStatus: http.StatusBadRequest},
+		{name: "NaN", query: "lat=NaN&lon=0", wantStatus: http.StatusBadRequest},
+		{name: "missing longitude", query: "lat=10", wantStatus: http.StatusBadRequest},
+		{name: "city and coordinates", query: "city=London&lat=51.5&lon=0", wantStatus: http.StatusBadRequest},
+		{name: "invalid country code", query: "city=Paris&country=France", wantStatus: http.StatusBadRequest},
+		{name: "imperial units", query: "city=London&units=imperial", wantStatus: http.StatusOK},
+		{name: "unknown units", query: "city=London&units=kelvin", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/weather?"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			handler.GetWeather(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("GetWeather() status = %v, want %v", w.Code, tt.wantStatus)
+			}
+
+			if tt.name == "valid coordinates" {
+				var response map[string]interface{}
+				json.NewDecoder(w.Body).Decode(&response)
+
+				if response["latitude"] != 48.8566 || response["longitude"] != 2.3522 {
+					t.Errorf("GetWeather() coordinates = %v,%v, want 48.8566,2.3522", response["latitude"], response["longitude"])
+				}
+			}
+		})
+	}
+}
+
+func TestHandler_GetForecast(t *testing.T) {
+	userRepo := newMockUserRepo()
+	userService := application.NewUserService(userRepo, nil, nil)
+	weatherService := application.NewWeatherService(newMockWeatherService(), userRepo)
+	authService := application.NewAuthService(memory.NewAPIKeyRepository(), memory.NewSessionRepository(), userRepo, nil, nil, nil)
+	handler := httpHandler.NewHandler(userService, weatherService, authService, application.NewHealthService(), nil, nil)
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantDays   int
+	}{
+		{name: "explicit days", query: "city=London&days=2", wantStatus: http.StatusOK, wantDays: 2},
+		{name: "default days", query: "city=London", wantStatus: http.StatusOK, wantDays: domain.DefaultForecastDays},
+		{name: "too many days", query: "city=London&days=9", wantStatus: http.StatusBadRequest},
+		{name: "days not a number", query: "city=London&days=abc", wantStatus: http.StatusBadRequest},
+		{name: "missing city", query: "days=2", wantStatus: http.StatusBadRequest},
+		{name: "unknown city", query: "city=NonExisting", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/weather/forecast?"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			handler.GetForecast(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("GetForecast() status = %v, want %v", w.Code, tt.wantStatus)
+			}
+
+			if tt.wantStatus == http.StatusOK {
+				var response struct {
+					City  string `json:"city"`
+					Daily []struct {
+						Date string `json:"date"`
+					} `json:"daily"`
+				}
+				json.NewDecoder(w.Body).Decode(&response)
+
+				if response.City != "London" || len(response.Daily) != tt.wantDays {
+					t.Errorf("GetForecast() = %+v, want %d days for London", response, tt.wantDays)
+				}
+				if response.Daily[0].Date != "2024-06-01" {
+					t.Errorf("GetForecast() first date = %v, want 2024-06-01", response.Daily[0].Date)
+				}
+			}
+		})
+	}
+}
+
+func TestHandler_GetWeatherCacheHeaders(t *testing.T) {
+	userRepo := newMockUserRepo()
+	weatherClient := newMockWeatherService()
+	userService := application.NewUserService(userRepo, nil, nil)
+	weatherService := application.NewWeatherService(weatherClient, userRepo)
+	authService := application.NewAuthService(memory.NewAPIKeyRepository(), memory.NewSessionRepository(), userRepo, nil, nil, nil)
+	handler := httpHandler.NewHandler(userService, weatherService, authService, application.NewHealthService(), nil, nil)
+
+	req := httptest.NewRequest("GET", "/api/weather?city=London", nil)
 	w := httptest.NewRecorder()
+	handler.GetWeather(w, req)
 
-	handler.Health(w, req)
+	if w.Header().Get("Cache-Control") != "" {
+		t.Errorf("GetWeather() uncached response Cache-Control = %v, want none", w.Header().Get("Cache-Control"))
+	}
 
-	if w.Code != http.StatusOK {
-		t.Errorf("Health() status = %v, want %v", w.Code, http.StatusOK)
+	fetchedAt := time.Now().Add(-90 * time.Second)
+	cached := *weatherClient.weather["London"]
+	cached.FetchedAt = fetchedAt
+	cached.ExpiresAt = fetchedAt.Add(5 * time.Minute)
+	weatherClient.weather["London"] = &cached
+
+	w = httptest.NewRecorder()
+	handler.GetWeather(w, req)
+
+	if got := w.Header().Get("Cache-Control"); got != "public, max-age=300" {
+		t.Errorf("GetWeather() Cache-Control = %v, want public, max-age=300", got)
+	}
+	if got := w.Header().Get("Age"); got != "90" {
+		t.Errorf("GetWeather() Age = %v, want 90", got)
 	}
+}
 
-	var response map[string]string
-	json.NewDecoder(w.Body).Decode(&response)
+type stubHealthChecker domain.ComponentHealth
+
+func (c stubHealthChecker) CheckHealth(ctx context.Context) domain.ComponentHealth {
+	return domain.ComponentHealth(c)
+}
+
+func TestHandler_Health(t *testing.T) {
+	userRepo := newMockUserRepo()
+	userService := application.NewUserService(userRepo, nil, nil)
+	weatherService := application.NewWeatherService(newMockWeatherService(), userRepo)
+	authService := application.NewAuthService(memory.NewAPIKeyRepository(), memory.NewSessionRepository(), userRepo, nil, nil, nil)
+
+	tests := []struct {
+		name       string
+		checkers   []ports.HealthChecker
+		wantStatus string
+	}{
+		{
+			name:       "no components",
+			wantStatus: "healthy",
+		},
+		{
+			name:       "all components up",
+			checkers:   []ports.HealthChecker{stubHealthChecker{Name: "weather", Status: domain.HealthUp}},
+			wantStatus: "healthy",
+		},
+		{
+			name: "open circuit",
+			checkers: []ports.HealthChecker{
+				stubHealthChecker{Name: "weather", Status: domain.HealthUp},
+				stubHealthChecker{Name: "backup", Status: domain.HealthDegraded, Detail: "circuit open"},
+			},
+			wantStatus: "degraded",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := httpHandler.NewHandler(userService, weatherService, authService, application.NewHealthService(tt.checkers...), nil, nil)
+
+			req := httptest.NewRequest("GET", "/health", nil)
+			w := httptest.NewRecorder()
+
+			handler.Health(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Errorf("Health() status = %v, want %v", w.Code, http.StatusOK)
+			}
+
+			var response struct {
+				Status     string              `json:"status"`
+				Components []map[string]string `json:"components"`
+			}
+			json.NewDecoder(w.Body).Decode(&response)
+
+			if response.Status != tt.wantStatus {
+				t.Errorf("Health() status = %v, want %v", response.Status, tt.wantStatus)
+			}
+			if len(response.Components) != len(tt.checkers) {
+				t.Errorf("Health() returned %d components, want %d", len(response.Components), len(tt.checkers))
+			}
+		})
+	}
+}
+
+func TestHandler_Authenticate(t *testing.T) {
+	userRepo := newMockUserRepo()
+	testUser, _ := domain.NewUser("test@example.com", "Test User")
+	testUser.ID = "test_id"
+	userRepo.users["test_id"] = testUser
+
+	userService := application.NewUserService(userRepo, nil, nil)
+	weatherService := application.NewWeatherService(newMockWeatherService(), userRepo)
+	authService := application.NewAuthService(memory.NewAPIKeyRepository(), memory.NewSessionRepository(), userRepo, nil, nil, nil)
+	handler := httpHandler.NewHandler(userService, weatherService, authService, application.NewHealthService(), nil, nil)
+
+	ctx := application.WithPrincipal(context.Background(), &domain.Principal{UserID: "test_id", Role: domain.RoleUser})
+	_, apiKey, err := authService.IssueAPIKey(ctx, "test_id", "default")
+	if err != nil {
+		t.Fatalf("IssueAPIKey() unexpected error: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	handler.RegisterRoutes(mux)
+
+	tests := []struct {
+		name       string
+		path       string
+		headers    map[string]string
+		wantStatus int
+	}{
+		{
+			name:       "bearer token",
+			path:       "/api/users/test_id",
+			headers:    map[string]string{"Authorization": "Bearer " + apiKey},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "x-api-key header",
+			path:       "/api/users/test_id",
+			headers:    map[string]string{"X-API-Key": apiKey},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "missing credentials",
+			path:       "/api/users/test_id",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "unsupported scheme",
+			path:       "/api/users/test_id",
+			headers:    map[string]string{"Authorization": "Basic " + apiKey},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "invalid key",
+			path:       "/api/users/test_id",
+			headers:    map[string]string{"Authorization": "Bearer hak_invalid"},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "user weather",
+			path:       "/api/users/test_id/weather?city=London",
+			headers:    map[string]string{"Authorization": "Bearer " + apiKey},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "weather of another user",
+			path:       "/api/users/other_id/weather?city=London",
+			headers:    map[string]string{"Authorization": "Bearer " + apiKey},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "another user's profile",
+			path:       "/api/users/other_id",
+			headers:    map[string]string{"Authorization": "Bearer " + apiKey},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "list users as non-admin",
+			path:       "/api/users",
+			headers:    map[string]string{"Authorization": "Bearer " + apiKey},
+			wantStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.path, nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
 
-	if response["status"] != "healthy" {
-		t.Errorf("Health() status = %v, want healthy", response["status"])
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Authenticate() status = %v, want %v", w.Code, tt.wantStatus)
+			}
+
+			if w.Code == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") == "" {
+				t.Errorf("Authenticate() missing WWW-Authenticate header")
+			}
+		})
+	}
+}
+
+func TestHandler_Ready(t *testing.T) {
+	userRepo := newMockUserRepo()
+	userService := application.NewUserService(userRepo, nil, nil)
+	weatherService := application.NewWeatherService(newMockWeatherService(), userRepo)
+	authService := application.NewAuthService(memory.NewAPIKeyRepository(), memory.NewSessionRepository(), userRepo, nil, nil, nil)
+
+	tests := []struct {
+		name     string
+		checkers []ports.HealthChecker
+		wantCode int
+	}{
+		{
+			name:     "degraded is still ready",
+			checkers: []ports.HealthChecker{stubHealthChecker{Name: "weather", Status: domain.HealthDegraded, Detail: "circuit open"}},
+			wantCode: http.StatusOK,
+		},
+		{
+			name: "misconfigured provider",
+			checkers: []ports.HealthChecker{
+				stubHealthChecker{Name: "weather", Status: domain.HealthDown, Detail: "misconfigured: invalid API key"},
+			},
+			wantCode: http.StatusServiceUnavailable,
+		},
+		{
+			name: "one failover provider misconfigured",
+			checkers: failover.NewHealthCheckers(
+				stubHealthChecker{Name: "openweathermap", Status: domain.HealthDown, Detail: "misconfigured: invalid API key"},
+				stubHealthChecker{Name: "weatherapi", Status: domain.HealthUp},
+			),
+			wantCode: http.StatusOK,
+		},
+		{
+			name: "every failover provider misconfigured",
+			checkers: failover.NewHealthCheckers(
+				stubHealthChecker{Name: "openweathermap", Status: domain.HealthDown, Detail: "misconfigured: invalid API key"},
+				stubHealthChecker{Name: "weatherapi", Status: domain.HealthDown, Detail: "misconfigured: invalid API key"},
+			),
+			wantCode: http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := httpHandler.NewHandler(userService, weatherService, authService, application.NewHealthService(tt.checkers...), nil, nil)
+
+			req := httptest.NewRequest("GET", "/ready", nil)
+			w := httptest.NewRecorder()
+
+			handler.Ready(w, req)
+
+			if w.Code != tt.wantCode {
+				t.Errorf("Ready() status = %v, want %v", w.Code, tt.wantCode)
+			}
+		})
 	}
 }
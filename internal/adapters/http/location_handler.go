@@ -0,0 +1,103 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/leinonen/hexagonal-architecture-go/internal/dto"
+	"github.com/leinonen/hexagonal-architecture-go/internal/errors"
+)
+
+func (h *Handler) CreateLocation(w http.ResponseWriter, r *http.Request) {
+	userID := r.PathValue("id")
+
+	var req dto.LocationDTO
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondWithError(w, errors.NewValidationError("invalid request body"))
+		return
+	}
+
+	query, err := toWeatherQuery(req.WeatherQueryDTO)
+	if err != nil {
+		h.respondWithError(w, err)
+		return
+	}
+
+	location, err := h.locationService.CreateLocation(r.Context(), userID, req.Name, query, req.Primary)
+	if err != nil {
+		h.respondWithError(w, err)
+		return
+	}
+
+	response := dto.ToLocationResponseDTO(location)
+
+	h.respondWithJSON(w, http.StatusCreated, response)
+}
+
+func (h *Handler) ListLocations(w http.ResponseWriter, r *http.Request) {
+	userID := r.PathValue("id")
+
+	locations, err := h.locationService.ListLocations(r.Context(), userID)
+	if err != nil {
+		h.respondWithError(w, err)
+		return
+	}
+
+	response := dto.ToLocationResponseDTOs(locations)
+
+	h.respondWithJSON(w, http.StatusOK, response)
+}
+
+func (h *Handler) GetLocation(w http.ResponseWriter, r *http.Request) {
+	userID := r.PathValue("id")
+	locationID := r.PathValue("locationID")
+
+	location, err := h.locationService.GetLocation(r.Context(), userID, locationID)
+	if err != nil {
+		h.respondWithError(w, err)
+		return
+	}
+
+	response := dto.ToLocationResponseDTO(location)
+
+	h.respondWithJSON(w, http.StatusOK, response)
+}
+
+func (h *Handler) UpdateLocation(w http.ResponseWriter, r *http.Request) {
+	userID := r.PathValue("id")
+	locationID := r.PathValue("locationID")
+
+	var req dto.LocationDTO
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondWithError(w, errors.NewValidationError("invalid request body"))
+		return
+	}
+
+	query, err := toWeatherQuery(req.WeatherQueryDTO)
+	if err != nil {
+		h.respondWithError(w, err)
+		return
+	}
+
+	location, err := h.locationService.UpdateLocation(r.Context(), userID, locationID, req.Name, query, req.Primary)
+	if err != nil {
+		h.respondWithError(w, err)
+		return
+	}
+
+	response := dto.ToLocationResponseDTO(location)
+
+	h.respondWithJSON(w, http.StatusOK, response)
+}
+
+func (h *Handler) DeleteLocation(w http.ResponseWriter, r *http.Request) {
+	userID := r.PathValue("id")
+	locationID := r.PathValue("locationID")
+
+	if err := h.locationService.DeleteLocation(r.Context(), userID, locationID); err != nil {
+		h.respondWithError(w, err)
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusNoContent, nil)
+}
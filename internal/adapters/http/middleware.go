@@ -0,0 +1,42 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/leinonen/hexagonal-architecture-go/internal/application"
+	"github.com/leinonen/hexagonal-architecture-go/internal/errors"
+)
+
+// Authenticate resolves the request credentials (an API key or a JWT) to a
+// principal and stores it in the request context. Credentials are read from an
+// `Authorization: Bearer` header or, failing that, from `X-API-Key`.
+func (h *Handler) Authenticate(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		credential := credentialFromRequest(r)
+		if credential == "" {
+			h.respondWithError(w, errors.NewUnauthorizedError("missing credentials"))
+			return
+		}
+
+		principal, err := h.authService.Authenticate(r.Context(), credential)
+		if err != nil {
+			h.respondWithError(w, err)
+			return
+		}
+
+		next(w, r.WithContext(application.WithPrincipal(r.Context(), principal)))
+	}
+}
+
+func credentialFromRequest(r *http.Request) string {
+	if header := r.Header.Get("Authorization"); header != "" {
+		scheme, token, found := strings.Cut(header, " ")
+		if found && strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(token)
+		}
+		return ""
+	}
+
+	return strings.TrimSpace(r.Header.Get("X-API-Key"))
+}
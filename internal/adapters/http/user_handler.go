@@ -5,7 +5,10 @@ import (
 	"net/http"
 	"strconv"
 
+	"github.com/leinonen/hexagonal-architecture-go/internal/application"
+	"github.com/leinonen/hexagonal-architecture-go/internal/domain"
 	"github.com/leinonen/hexagonal-architecture-go/internal/dto"
+	"github.com/leinonen/hexagonal-architecture-go/internal/errors"
 )
 
 func (h *Handler) CreateUser(w http.ResponseWriter, r *http.Request) {
@@ -15,13 +18,16 @@ func (h *Handler) CreateUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	user, err := h.userService.CreateUser(r.Context(), req.Email, req.Name)
+	user, apiKey, err := h.authService.Register(r.Context(), req.Email, req.Name, req.Password)
 	if err != nil {
 		h.respondWithError(w, err)
 		return
 	}
 
-	response := dto.ToUserResponseDTO(user)
+	response := &dto.CreateUserResponseDTO{
+		UserResponseDTO: dto.ToUserResponseDTO(user),
+		APIKey:          apiKey,
+	}
 
 	h.respondWithJSON(w, http.StatusCreated, response)
 }
@@ -49,7 +55,12 @@ func (h *Handler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	user, err := h.userService.UpdateUser(r.Context(), id, req.Email, req.Name)
+	user, err := h.userService.UpdateUser(r.Context(), id, application.UserUpdate{
+		Email: req.Email,
+		Name:  req.Name,
+		Role:  domain.Role(req.Role),
+		Units: domain.Units(req.Units),
+	})
 	if err != nil {
 		h.respondWithError(w, err)
 		return
@@ -60,6 +71,23 @@ func (h *Handler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 	h.respondWithJSON(w, http.StatusOK, response)
 }
 
+func (h *Handler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	var req dto.ChangePasswordDTO
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondWithError(w, errors.NewValidationError("invalid request body"))
+		return
+	}
+
+	if err := h.userService.ChangePassword(r.Context(), id, req.CurrentPassword, req.NewPassword); err != nil {
+		h.respondWithError(w, err)
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusNoContent, nil)
+}
+
 func (h *Handler) DeleteUser(w http.ResponseWriter, r *http.Request) {
 	id := r.PathValue("id")
 
@@ -1,20 +1,26 @@
 package http
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
 	"net/http"
+	"strconv"
+	"time"
 
+	"github.com/leinonen/hexagonal-architecture-go/internal/domain"
 	"github.com/leinonen/hexagonal-architecture-go/internal/dto"
 	"github.com/leinonen/hexagonal-architecture-go/internal/errors"
 )
 
 func (h *Handler) GetWeather(w http.ResponseWriter, r *http.Request) {
-	city := r.URL.Query().Get("city")
-	if city == "" {
-		h.respondWithError(w, errors.NewValidationError("city parameter is required"))
+	query, err := weatherQuery(r)
+	if err != nil {
+		h.respondWithError(w, err)
 		return
 	}
 
-	weather, err := h.weatherService.GetWeather(r.Context(), city)
+	weather, err := h.weatherService.GetWeather(r.Context(), query, weatherUnits(r))
 	if err != nil {
 		h.respondWithError(w, err)
 		return
@@ -22,19 +28,190 @@ func (h *Handler) GetWeather(w http.ResponseWriter, r *http.Request) {
 
 	response := dto.ToWeatherResponseDTO(weather)
 
+	setCacheHeaders(w, weather.FetchedAt, weather.ExpiresAt, "public")
+
 	h.respondWithJSON(w, http.StatusOK, response)
 }
 
-func (h *Handler) GetUserWeather(w http.ResponseWriter, r *http.Request) {
+// batchTimeout keeps a batch within the server's write timeout; places not
+// looked up by then are reported as failed rather than failing the batch.
+const batchTimeout = 10 * time.Second
+
+func (h *Handler) GetWeatherBatch(w http.ResponseWriter, r *http.Request) {
+	var req dto.BatchWeatherRequestDTO
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondWithError(w, errors.NewValidationError("invalid request body"))
+		return
+	}
+
+	queries := make([]domain.WeatherQuery, len(req.Places))
+	for i, place := range req.Places {
+		query, err := toWeatherQuery(place)
+		if err != nil {
+			h.respondWithError(w, err)
+			return
+		}
+		queries[i] = query
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), batchTimeout)
+	defer cancel()
+
+	results, err := h.weatherService.GetWeatherMany(ctx, queries, weatherUnits(r))
+	if err != nil {
+		h.respondWithError(w, err)
+		return
+	}
+
+	response := dto.BatchWeatherResponseDTO{Results: make([]dto.BatchWeatherResultDTO, len(results))}
+	for i, result := range results {
+		item := dto.BatchWeatherResultDTO{Query: req.Places[i], Status: http.StatusOK}
+		if result.Err != nil {
+			item.Status, item.Error = errorStatus(result.Err)
+		} else {
+			item.Weather = dto.ToWeatherResponseDTO(result.Weather)
+		}
+		response.Results[i] = item
+	}
+
+	h.respondWithJSON(w, http.StatusOK, response)
+}
+
+func (h *Handler) GetUserDashboard(w http.ResponseWriter, r *http.Request) {
 	userID := r.PathValue("id")
-	city := r.URL.Query().Get("city")
 
-	if city == "" {
-		h.respondWithError(w, errors.NewValidationError("city parameter is required"))
+	ctx, cancel := context.WithTimeout(r.Context(), batchTimeout)
+	defer cancel()
+
+	dashboard, err := h.weatherService.GetDashboard(ctx, userID, weatherUnits(r))
+	if err != nil {
+		h.respondWithError(w, err)
+		return
+	}
+
+	response := dto.DashboardResponseDTO{Locations: make([]dto.DashboardItemDTO, len(dashboard))}
+	for i, entry := range dashboard {
+		item := dto.DashboardItemDTO{Location: dto.ToLocationResponseDTO(entry.Location), Status: http.StatusOK}
+		if entry.Err != nil {
+			item.Status, item.Error = errorStatus(entry.Err)
+		} else {
+			item.Weather = dto.ToWeatherResponseDTO(entry.Weather)
+		}
+		response.Locations[i] = item
+	}
+
+	h.respondWithJSON(w, http.StatusOK, response)
+}
+
+// toWeatherQuery converts a place from a request body. Like weatherQuery it
+// leaves range checks to the service.
+func toWeatherQuery(place dto.WeatherQueryDTO) (domain.WeatherQuery, error) {
+	if (place.Latitude == nil) != (place.Longitude == nil) {
+		return domain.WeatherQuery{}, errors.NewValidationError("latitude and longitude must be given together")
+	}
+
+	query := domain.WeatherQuery{City: place.City, Country: place.Country}
+	if place.Latitude != nil {
+		query.Coordinates = &domain.Coordinates{Latitude: *place.Latitude, Longitude: *place.Longitude}
+	}
+	return query, nil
+}
+
+func (h *Handler) GetForecast(w http.ResponseWriter, r *http.Request) {
+	query, err := weatherQuery(r)
+	if err != nil {
+		h.respondWithError(w, err)
+		return
+	}
+
+	days := 0
+	if value := r.URL.Query().Get("days"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			h.respondWithError(w, errors.NewValidationError("days must be a number"))
+			return
+		}
+		days = parsed
+	}
+
+	forecast, err := h.weatherService.GetForecast(r.Context(), query, days, weatherUnits(r))
+	if err != nil {
+		h.respondWithError(w, err)
+		return
+	}
+
+	response := dto.ToForecastResponseDTO(forecast)
+
+	setCacheHeaders(w, forecast.FetchedAt, forecast.ExpiresAt, "public")
+	h.respondWithJSON(w, http.StatusOK, response)
+}
+
+func (h *Handler) GetWeatherHistory(w http.ResponseWriter, r *http.Request) {
+	query, err := weatherQuery(r)
+	if err != nil {
+		h.respondWithError(w, err)
+		return
+	}
+
+	params := r.URL.Query()
+	from, err := timeParam(params.Get("from"), "from")
+	if err != nil {
+		h.respondWithError(w, err)
+		return
+	}
+	to, err := timeParam(params.Get("to"), "to")
+	if err != nil {
+		h.respondWithError(w, err)
+		return
+	}
+
+	var interval time.Duration
+	if value := params.Get("interval"); value != "" {
+		if interval, err = time.ParseDuration(value); err != nil {
+			h.respondWithError(w, errors.NewValidationError("interval must be a duration such as 15m or 1h"))
+			return
+		}
+	}
+
+	history, err := h.weatherService.GetHistory(r.Context(), query, from, to, interval, weatherUnits(r))
+	if err != nil {
+		h.respondWithError(w, err)
 		return
 	}
 
-	weather, err := h.weatherService.GetWeatherForUser(r.Context(), userID, city)
+	response := dto.ToHistoryResponseDTO(history)
+
+	h.respondWithJSON(w, http.StatusOK, response)
+}
+
+// timeParam parses an optional RFC 3339 time; an empty value is the zero
+// time, leaving the default to the service.
+func timeParam(value, name string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, errors.NewValidationError(fmt.Sprintf("%s must be an RFC 3339 time such as 2024-01-15T06:00:00Z", name))
+	}
+	return parsed, nil
+}
+
+func (h *Handler) GetUserWeather(w http.ResponseWriter, r *http.Request) {
+	userID := r.PathValue("id")
+
+	// Without a place the service falls back to the user's primary location.
+	var query domain.WeatherQuery
+	params := r.URL.Query()
+	if params.Has("city") || params.Has("country") || params.Has("lat") || params.Has("lon") {
+		var err error
+		if query, err = weatherQuery(r); err != nil {
+			h.respondWithError(w, err)
+			return
+		}
+	}
+
+	weather, err := h.weatherService.GetWeatherForUser(r.Context(), userID, query, weatherUnits(r))
 	if err != nil {
 		h.respondWithError(w, err)
 		return
@@ -42,11 +219,119 @@ func (h *Handler) GetUserWeather(w http.ResponseWriter, r *http.Request) {
 
 	response := dto.ToWeatherResponseDTO(weather)
 
+	setCacheHeaders(w, weather.FetchedAt, weather.ExpiresAt, "private")
+
 	h.respondWithJSON(w, http.StatusOK, response)
 }
 
+func (h *Handler) SearchPlaces(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("q")
+	if name == "" {
+		h.respondWithError(w, errors.NewValidationError("q parameter is required"))
+		return
+	}
+
+	limit := 0
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			h.respondWithError(w, errors.NewValidationError("limit must be a number"))
+			return
+		}
+		limit = parsed
+	}
+
+	places, err := h.weatherService.SearchPlaces(r.Context(), name, r.URL.Query().Get("country"), limit)
+	if err != nil {
+		h.respondWithError(w, err)
+		return
+	}
+
+	response := make([]*dto.PlaceResponseDTO, len(places))
+	for i := range places {
+		response[i] = dto.ToPlaceResponseDTO(&places[i])
+	}
+
+	h.respondWithJSON(w, http.StatusOK, response)
+}
+
+// weatherQuery reads either city (with an optional country code) or lat and
+// lon from the query string. Range checks are left to the service.
+func weatherQuery(r *http.Request) (domain.WeatherQuery, error) {
+	params := r.URL.Query()
+
+	lat, lon := params.Get("lat"), params.Get("lon")
+	if lat == "" && lon == "" {
+		if params.Get("city") == "" {
+			return domain.WeatherQuery{}, errors.NewValidationError("city or lat and lon parameters are required")
+		}
+		return domain.WeatherQuery{City: params.Get("city"), Country: params.Get("country")}, nil
+	}
+	if lat == "" || lon == "" {
+		return domain.WeatherQuery{}, errors.NewValidationError("lat and lon must be given together")
+	}
+
+	latitude, err := strconv.ParseFloat(lat, 64)
+	if err != nil {
+		return domain.WeatherQuery{}, errors.NewValidationError("lat must be a number")
+	}
+	longitude, err := strconv.ParseFloat(lon, 64)
+	if err != nil {
+		return domain.WeatherQuery{}, errors.NewValidationError("lon must be a number")
+	}
+
+	return domain.WeatherQuery{
+		City:        params.Get("city"),
+		Country:     params.Get("country"),
+		Coordinates: &domain.Coordinates{Latitude: latitude, Longitude: longitude},
+	}, nil
+}
+
+// weatherUnits reads the units query parameter; an empty value leaves the
+// choice to the service.
+func weatherUnits(r *http.Request) domain.Units {
+	return domain.Units(r.URL.Query().Get("units"))
+}
+
+// setCacheHeaders tells HTTP caches how long a cached weather answer stays
+// fresh. max-age is the full cache lifetime and Age how much of it has passed,
+// matching how shared caches compute freshness.
+func setCacheHeaders(w http.ResponseWriter, fetchedAt, expiresAt time.Time, scope string) {
+	if expiresAt.IsZero() || fetchedAt.IsZero() {
+		return
+	}
+
+	maxAge := int(expiresAt.Sub(fetchedAt).Seconds())
+	age := int(time.Since(fetchedAt).Seconds())
+	if age < 0 {
+		age = 0
+	}
+	if age > maxAge {
+		age = maxAge
+	}
+
+	w.Header().Set("Cache-Control", fmt.Sprintf("%s, max-age=%d", scope, maxAge))
+	w.Header().Set("Age", strconv.Itoa(age))
+}
+
 func (h *Handler) Health(w http.ResponseWriter, r *http.Request) {
-	h.respondWithJSON(w, http.StatusOK, map[string]string{
-		"status": "healthy",
-	})
+	status, components := h.healthService.Check(r.Context())
+
+	response := dto.ToHealthResponseDTO(status, components)
+
+	h.respondWithJSON(w, http.StatusOK, response)
+}
+
+// Ready reports whether the service can answer weather lookups, failing with
+// 503 while any component is down, such as the only provider rejecting our API
+// key. A failover provider is only down once every provider is.
+func (h *Handler) Ready(w http.ResponseWriter, r *http.Request) {
+	status, components := h.healthService.Check(r.Context())
+
+	code := http.StatusOK
+	if status == domain.HealthDown {
+		code = http.StatusServiceUnavailable
+	}
+
+	h.respondWithJSON(w, code, dto.ToHealthResponseDTO(status, components))
 }
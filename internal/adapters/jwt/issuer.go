@@ -0,0 +1,63 @@
+package jwt
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/leinonen/hexagonal-architecture-go/internal/domain"
+	"github.com/leinonen/hexagonal-architecture-go/internal/errors"
+	"github.com/leinonen/hexagonal-architecture-go/internal/ports"
+)
+
+type Issuer struct {
+	issuer   string
+	audience string
+	key      SigningKey
+}
+
+func NewIssuer(issuer, audience string, key SigningKey) ports.TokenIssuer {
+	return &Issuer{
+		issuer:   issuer,
+		audience: audience,
+		key:      key,
+	}
+}
+
+type accessClaims struct {
+	Claims
+	ID string `json:"jti"`
+}
+
+func (i *Issuer) Issue(ctx context.Context, principal *domain.Principal, ttl time.Duration) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(ttl)
+
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", time.Time{}, errors.NewInternalError("failed to generate token id")
+	}
+
+	claims := accessClaims{
+		Claims: Claims{
+			Subject:   principal.UserID,
+			Issuer:    i.issuer,
+			ExpiresAt: expiresAt.Unix(),
+			NotBefore: now.Unix(),
+			IssuedAt:  now.Unix(),
+			Role:      string(principal.Role),
+		},
+		ID: hex.EncodeToString(id),
+	}
+	if i.audience != "" {
+		claims.Audience = Audience{i.audience}
+	}
+
+	token, err := Mint(claims, i.key)
+	if err != nil {
+		return "", time.Time{}, errors.NewInternalError("failed to sign token")
+	}
+
+	return token, expiresAt, nil
+}
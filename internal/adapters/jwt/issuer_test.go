@@ -0,0 +1,42 @@
+package jwt_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/leinonen/hexagonal-architecture-go/internal/adapters/jwt"
+	"github.com/leinonen/hexagonal-architecture-go/internal/domain"
+)
+
+func TestIssuer_RoundTrip(t *testing.T) {
+	key, err := jwt.GenerateHMACKey("local")
+	if err != nil {
+		t.Fatalf("GenerateHMACKey() unexpected error: %v", err)
+	}
+
+	issuer := jwt.NewIssuer("hex-api", "hex-clients", key)
+	verifier, _ := jwt.NewVerifier(jwt.Config{
+		Issuer:   "hex-api",
+		Audience: "hex-clients",
+		Keys:     []jwt.Key{key.VerificationKey()},
+	})
+
+	token, expiresAt, err := issuer.Issue(context.Background(), &domain.Principal{UserID: "user_1", Role: domain.RoleAdmin}, time.Minute)
+	if err != nil {
+		t.Fatalf("Issue() unexpected error: %v", err)
+	}
+
+	if time.Until(expiresAt) > time.Minute || time.Until(expiresAt) <= 0 {
+		t.Errorf("Issue() expiresAt = %v, want within a minute", expiresAt)
+	}
+
+	principal, err := verifier.Verify(context.Background(), token)
+	if err != nil {
+		t.Fatalf("Verify() unexpected error: %v", err)
+	}
+
+	if principal.UserID != "user_1" || principal.Role != domain.RoleAdmin {
+		t.Errorf("Verify() principal = %+v, want admin user_1", principal)
+	}
+}
@@ -0,0 +1,192 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+)
+
+const (
+	HS256 = "HS256"
+	RS256 = "RS256"
+	ES256 = "ES256"
+)
+
+// Key is a verification key bound to a single algorithm, so a token can never
+// choose how its signature is checked.
+type Key struct {
+	ID        string
+	Algorithm string
+	Secret    []byte
+	Public    any
+}
+
+func LoadHMACKeyFile(path, kid string) (Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Key{}, fmt.Errorf("read hmac key: %w", err)
+	}
+
+	secret := []byte(strings.TrimSpace(string(data)))
+	if len(secret) < 32 {
+		return Key{}, fmt.Errorf("hmac key %s is shorter than 32 bytes", path)
+	}
+
+	return Key{ID: kid, Algorithm: HS256, Secret: secret}, nil
+}
+
+// LoadPublicKeyFile reads a PEM encoded public key or certificate. RSA keys are
+// used for RS256 and P-256 keys for ES256.
+func LoadPublicKeyFile(path, kid string) (Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Key{}, fmt.Errorf("read public key: %w", err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return Key{}, fmt.Errorf("public key %s is not PEM encoded", path)
+	}
+
+	var public any
+	switch block.Type {
+	case "CERTIFICATE":
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return Key{}, fmt.Errorf("parse certificate: %w", err)
+		}
+		public = cert.PublicKey
+	case "RSA PUBLIC KEY":
+		public, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		public, err = x509.ParsePKIXPublicKey(block.Bytes)
+	}
+	if err != nil {
+		return Key{}, fmt.Errorf("parse public key: %w", err)
+	}
+
+	return newPublicKey(kid, public)
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	K   string `json:"k"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// LoadJWKSFile reads a JSON Web Key Set from disk. Keys marked for encryption
+// or using unsupported types are skipped.
+func LoadJWKSFile(path string) ([]Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read jwks: %w", err)
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("parse jwks: %w", err)
+	}
+
+	keys := make([]Key, 0, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		key, err := k.toKey()
+		if err != nil {
+			return nil, fmt.Errorf("jwks key %q: %w", k.Kid, err)
+		}
+		if key.Algorithm == "" {
+			continue
+		}
+		if k.Alg != "" && k.Alg != key.Algorithm {
+			continue
+		}
+		keys = append(keys, key)
+	}
+
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("jwks %s contains no usable signing keys", path)
+	}
+
+	return keys, nil
+}
+
+func (k jwk) toKey() (Key, error) {
+	switch k.Kty {
+	case "oct":
+		secret, err := base64.RawURLEncoding.DecodeString(k.K)
+		if err != nil {
+			return Key{}, err
+		}
+		return Key{ID: k.Kid, Algorithm: HS256, Secret: secret}, nil
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return Key{}, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return Key{}, err
+		}
+		return newPublicKey(k.Kid, &rsa.PublicKey{N: n, E: int(e.Int64())})
+	case "EC":
+		if k.Crv != "P-256" {
+			return Key{}, nil
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return Key{}, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return Key{}, err
+		}
+		return newPublicKey(k.Kid, &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y})
+	default:
+		return Key{}, nil
+	}
+}
+
+func newPublicKey(kid string, public any) (Key, error) {
+	switch pub := public.(type) {
+	case *rsa.PublicKey:
+		if pub.N.BitLen() < 2048 {
+			return Key{}, fmt.Errorf("rsa key must be at least 2048 bits")
+		}
+		return Key{ID: kid, Algorithm: RS256, Public: pub}, nil
+	case *ecdsa.PublicKey:
+		if pub.Curve != elliptic.P256() {
+			return Key{}, fmt.Errorf("ecdsa key must use curve P-256")
+		}
+		return Key{ID: kid, Algorithm: ES256, Public: pub}, nil
+	default:
+		return Key{}, fmt.Errorf("unsupported public key type %T", public)
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,167 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+type header struct {
+	Algorithm string `json:"alg"`
+	Type      string `json:"typ,omitempty"`
+	KeyID     string `json:"kid,omitempty"`
+}
+
+type Claims struct {
+	Subject   string   `json:"sub,omitempty"`
+	Issuer    string   `json:"iss,omitempty"`
+	Audience  Audience `json:"aud,omitempty"`
+	ExpiresAt int64    `json:"exp,omitempty"`
+	NotBefore int64    `json:"nbf,omitempty"`
+	IssuedAt  int64    `json:"iat,omitempty"`
+	Role      string   `json:"role,omitempty"`
+}
+
+// Audience accepts both the single string and the array form of "aud".
+type Audience []string
+
+func (a *Audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = Audience{single}
+		return nil
+	}
+
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return err
+	}
+	*a = many
+	return nil
+}
+
+func (a Audience) Contains(audience string) bool {
+	for _, aud := range a {
+		if aud == audience {
+			return true
+		}
+	}
+	return false
+}
+
+// SigningKey holds the private half used by Mint. HS256 keys use Secret, RS256
+// and ES256 keys use Private.
+type SigningKey struct {
+	ID        string
+	Algorithm string
+	Secret    []byte
+	Private   crypto.Signer
+}
+
+// GenerateHMACKey creates a random HS256 signing key.
+func GenerateHMACKey(kid string) (SigningKey, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return SigningKey{}, err
+	}
+	return SigningKey{ID: kid, Algorithm: HS256, Secret: secret}, nil
+}
+
+// VerificationKey returns the key that verifies tokens signed with k. It is
+// only defined for HS256, whose secret is symmetric.
+func (k SigningKey) VerificationKey() Key {
+	return Key{ID: k.ID, Algorithm: k.Algorithm, Secret: k.Secret}
+}
+
+// Mint signs claims into a compact JWS. It exists for issuing short-lived
+// tokens and for producing fixtures in tests.
+func Mint(claims any, key SigningKey) (string, error) {
+	head, err := json.Marshal(header{Algorithm: key.Algorithm, Type: "JWT", KeyID: key.ID})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := encodeSegment(head) + "." + encodeSegment(payload)
+	signature, err := sign(signingInput, key)
+	if err != nil {
+		return "", err
+	}
+
+	return signingInput + "." + encodeSegment(signature), nil
+}
+
+func sign(input string, key SigningKey) ([]byte, error) {
+	digest := sha256.Sum256([]byte(input))
+
+	switch key.Algorithm {
+	case HS256:
+		mac := hmac.New(sha256.New, key.Secret)
+		mac.Write([]byte(input))
+		return mac.Sum(nil), nil
+	case RS256:
+		priv, ok := key.Private.(*rsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("RS256 requires an RSA private key")
+		}
+		return rsa.SignPKCS1v15(rand.Reader, priv, crypto.SHA256, digest[:])
+	case ES256:
+		priv, ok := key.Private.(*ecdsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("ES256 requires an ECDSA private key")
+		}
+		r, s, err := ecdsa.Sign(rand.Reader, priv, digest[:])
+		if err != nil {
+			return nil, err
+		}
+		signature := make([]byte, 64)
+		r.FillBytes(signature[:32])
+		s.FillBytes(signature[32:])
+		return signature, nil
+	default:
+		return nil, fmt.Errorf("unsupported algorithm %q", key.Algorithm)
+	}
+}
+
+func verifySignature(input string, signature []byte, key Key) bool {
+	digest := sha256.Sum256([]byte(input))
+
+	switch key.Algorithm {
+	case HS256:
+		mac := hmac.New(sha256.New, key.Secret)
+		mac.Write([]byte(input))
+		return hmac.Equal(signature, mac.Sum(nil))
+	case RS256:
+		pub, ok := key.Public.(*rsa.PublicKey)
+		return ok && rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], signature) == nil
+	case ES256:
+		pub, ok := key.Public.(*ecdsa.PublicKey)
+		if !ok || len(signature) != 64 {
+			return false
+		}
+		r := new(big.Int).SetBytes(signature[:32])
+		s := new(big.Int).SetBytes(signature[32:])
+		return ecdsa.Verify(pub, digest[:], r, s)
+	default:
+		return false
+	}
+}
+
+func encodeSegment(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeSegment(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+}
@@ -0,0 +1,140 @@
+package jwt
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/leinonen/hexagonal-architecture-go/internal/domain"
+	"github.com/leinonen/hexagonal-architecture-go/internal/errors"
+	"github.com/leinonen/hexagonal-architecture-go/internal/ports"
+)
+
+type Config struct {
+	Issuer   string
+	Audience string
+	Leeway   time.Duration
+	Keys     []Key
+}
+
+type Verifier struct {
+	issuer   string
+	audience string
+	leeway   time.Duration
+	keys     []Key
+}
+
+func NewVerifier(cfg Config) (ports.TokenVerifier, error) {
+	if len(cfg.Keys) == 0 {
+		return nil, fmt.Errorf("jwt verifier requires at least one key")
+	}
+
+	return &Verifier{
+		issuer:   cfg.Issuer,
+		audience: cfg.Audience,
+		leeway:   cfg.Leeway,
+		keys:     cfg.Keys,
+	}, nil
+}
+
+// Verify checks the token's signature and claims. The principal carries the
+// role as claimed; callers that can should take it from the stored user.
+func (v *Verifier) Verify(ctx context.Context, token string) (*domain.Principal, error) {
+	claims, err := v.parse(token)
+	if err != nil {
+		return nil, err
+	}
+
+	role := domain.Role(claims.Role)
+	if !role.Valid() {
+		role = domain.RoleUser
+	}
+
+	return &domain.Principal{
+		UserID: claims.Subject,
+		Role:   role,
+		Method: domain.AuthMethodJWT,
+	}, nil
+}
+
+func (v *Verifier) parse(token string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.NewUnauthorizedError("malformed token")
+	}
+
+	rawHeader, err := decodeSegment(parts[0])
+	if err != nil {
+		return nil, errors.NewUnauthorizedError("malformed token header")
+	}
+	var head header
+	if err := json.Unmarshal(rawHeader, &head); err != nil {
+		return nil, errors.NewUnauthorizedError("malformed token header")
+	}
+
+	signature, err := decodeSegment(parts[2])
+	if err != nil {
+		return nil, errors.NewUnauthorizedError("malformed token signature")
+	}
+
+	if !v.verify(parts[0]+"."+parts[1], signature, head) {
+		return nil, errors.NewUnauthorizedError("invalid token signature")
+	}
+
+	rawClaims, err := decodeSegment(parts[1])
+	if err != nil {
+		return nil, errors.NewUnauthorizedError("malformed token claims")
+	}
+	var claims Claims
+	if err := json.Unmarshal(rawClaims, &claims); err != nil {
+		return nil, errors.NewUnauthorizedError("malformed token claims")
+	}
+
+	if err := v.validate(&claims); err != nil {
+		return nil, err
+	}
+
+	return &claims, nil
+}
+
+func (v *Verifier) verify(input string, signature []byte, head header) bool {
+	for _, key := range v.keys {
+		if key.Algorithm != head.Algorithm {
+			continue
+		}
+		if head.KeyID != "" && key.ID != "" && key.ID != head.KeyID {
+			continue
+		}
+		if verifySignature(input, signature, key) {
+			return true
+		}
+	}
+	return false
+}
+
+func (v *Verifier) validate(claims *Claims) error {
+	now := time.Now()
+
+	if claims.Subject == "" {
+		return errors.NewUnauthorizedError("token has no subject")
+	}
+	if claims.ExpiresAt == 0 {
+		return errors.NewUnauthorizedError("token has no expiry")
+	}
+	if now.After(time.Unix(claims.ExpiresAt, 0).Add(v.leeway)) {
+		return errors.NewUnauthorizedError("token expired")
+	}
+	if claims.NotBefore != 0 && now.Before(time.Unix(claims.NotBefore, 0).Add(-v.leeway)) {
+		return errors.NewUnauthorizedError("token not yet valid")
+	}
+	if v.issuer != "" && claims.Issuer != v.issuer {
+		return errors.NewUnauthorizedError("token issuer not accepted")
+	}
+	if v.audience != "" && !claims.Audience.Contains(v.audience) {
+		return errors.NewUnauthorizedError("token audience not accepted")
+	}
+
+	return nil
+}
@@ -0,0 +1,283 @@
+package jwt_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/leinonen/hexagonal-architecture-go/internal/adapters/jwt"
+	"github.com/leinonen/hexagonal-architecture-go/internal/domain"
+	"github.com/leinonen/hexagonal-architecture-go/internal/errors"
+)
+
+var testSecret = []byte("0123456789abcdef0123456789abcdef")
+
+func validClaims() jwt.Claims {
+	now := time.Now()
+	return jwt.Claims{
+		Subject:   "user_1",
+		Issuer:    "https://issuer.test",
+		Audience:  jwt.Audience{"hex-api"},
+		IssuedAt:  now.Unix(),
+		NotBefore: now.Add(-time.Minute).Unix(),
+		ExpiresAt: now.Add(time.Hour).Unix(),
+	}
+}
+
+func mint(t *testing.T, claims any, key jwt.SigningKey) string {
+	t.Helper()
+	token, err := jwt.Mint(claims, key)
+	if err != nil {
+		t.Fatalf("Mint() unexpected error: %v", err)
+	}
+	return token
+}
+
+func TestVerifier_HS256Claims(t *testing.T) {
+	verifier, err := jwt.NewVerifier(jwt.Config{
+		Issuer:   "https://issuer.test",
+		Audience: "hex-api",
+		Keys:     []jwt.Key{{Algorithm: jwt.HS256, Secret: testSecret}},
+	})
+	if err != nil {
+		t.Fatalf("NewVerifier() unexpected error: %v", err)
+	}
+
+	signingKey := jwt.SigningKey{Algorithm: jwt.HS256, Secret: testSecret}
+
+	tests := []struct {
+		name    string
+		mutate  func(*jwt.Claims)
+		key     jwt.SigningKey
+		wantErr bool
+	}{
+		{
+			name:   "valid token",
+			mutate: func(c *jwt.Claims) {},
+			key:    signingKey,
+		},
+		{
+			name:    "expired",
+			mutate:  func(c *jwt.Claims) { c.ExpiresAt = time.Now().Add(-time.Hour).Unix() },
+			key:     signingKey,
+			wantErr: true,
+		},
+		{
+			name:    "missing expiry",
+			mutate:  func(c *jwt.Claims) { c.ExpiresAt = 0 },
+			key:     signingKey,
+			wantErr: true,
+		},
+		{
+			name:    "not yet valid",
+			mutate:  func(c *jwt.Claims) { c.NotBefore = time.Now().Add(time.Hour).Unix() },
+			key:     signingKey,
+			wantErr: true,
+		},
+		{
+			name:    "wrong issuer",
+			mutate:  func(c *jwt.Claims) { c.Issuer = "https://evil.test" },
+			key:     signingKey,
+			wantErr: true,
+		},
+		{
+			name:    "wrong audience",
+			mutate:  func(c *jwt.Claims) { c.Audience = jwt.Audience{"other-api"} },
+			key:     signingKey,
+			wantErr: true,
+		},
+		{
+			name:   "audience among many",
+			mutate: func(c *jwt.Claims) { c.Audience = jwt.Audience{"other-api", "hex-api"} },
+			key:    signingKey,
+		},
+		{
+			name:    "missing subject",
+			mutate:  func(c *jwt.Claims) { c.Subject = "" },
+			key:     signingKey,
+			wantErr: true,
+		},
+		{
+			name:    "wrong secret",
+			mutate:  func(c *jwt.Claims) {},
+			key:     jwt.SigningKey{Algorithm: jwt.HS256, Secret: []byte("another-secret-another-secret-!!")},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := validClaims()
+			tt.mutate(&claims)
+
+			principal, err := verifier.Verify(context.Background(), mint(t, claims, tt.key))
+
+			if tt.wantErr {
+				if !errors.IsUnauthorized(err) {
+					t.Errorf("Verify() error = %v, want unauthorized", err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Verify() unexpected error: %v", err)
+			}
+
+			if principal.UserID != "user_1" || principal.Method != domain.AuthMethodJWT {
+				t.Errorf("Verify() principal = %+v, want user_1 via jwt", principal)
+			}
+		})
+	}
+}
+
+func TestVerifier_RejectsAlgorithmConfusion(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	verifier, _ := jwt.NewVerifier(jwt.Config{
+		Keys: []jwt.Key{{Algorithm: jwt.RS256, Public: &rsaKey.PublicKey}},
+	})
+
+	pubDER, _ := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	forged := mint(t, validClaims(), jwt.SigningKey{Algorithm: jwt.HS256, Secret: pubDER})
+
+	if _, err := verifier.Verify(context.Background(), forged); !errors.IsUnauthorized(err) {
+		t.Errorf("Verify() HS256 token against RS256 key error = %v, want unauthorized", err)
+	}
+
+	head := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none","typ":"JWT"}`))
+	payload, _ := json.Marshal(validClaims())
+	unsigned := head + "." + base64.RawURLEncoding.EncodeToString(payload) + "."
+
+	if _, err := verifier.Verify(context.Background(), unsigned); !errors.IsUnauthorized(err) {
+		t.Errorf("Verify() alg=none error = %v, want unauthorized", err)
+	}
+}
+
+func TestVerifier_PublicKeyFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	rsaPath := writePublicKey(t, dir, "rsa.pem", &rsaKey.PublicKey)
+	ecPath := writePublicKey(t, dir, "ec.pem", &ecKey.PublicKey)
+
+	rsaVerifyKey, err := jwt.LoadPublicKeyFile(rsaPath, "rsa-1")
+	if err != nil {
+		t.Fatalf("LoadPublicKeyFile() rsa unexpected error: %v", err)
+	}
+	ecVerifyKey, err := jwt.LoadPublicKeyFile(ecPath, "ec-1")
+	if err != nil {
+		t.Fatalf("LoadPublicKeyFile() ec unexpected error: %v", err)
+	}
+
+	verifier, _ := jwt.NewVerifier(jwt.Config{Keys: []jwt.Key{rsaVerifyKey, ecVerifyKey}})
+
+	tests := []struct {
+		name string
+		key  jwt.SigningKey
+	}{
+		{name: "RS256", key: jwt.SigningKey{ID: "rsa-1", Algorithm: jwt.RS256, Private: rsaKey}},
+		{name: "ES256", key: jwt.SigningKey{ID: "ec-1", Algorithm: jwt.ES256, Private: ecKey}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := mint(t, validClaims(), tt.key)
+			if _, err := verifier.Verify(context.Background(), token); err != nil {
+				t.Errorf("Verify() unexpected error: %v", err)
+			}
+
+			tampered := token[:strings.LastIndex(token, ".")] + ".AAAA"
+			if _, err := verifier.Verify(context.Background(), tampered); !errors.IsUnauthorized(err) {
+				t.Errorf("Verify() tampered signature error = %v, want unauthorized", err)
+			}
+		})
+	}
+}
+
+func TestLoadJWKSFile(t *testing.T) {
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+
+	enc := base64.RawURLEncoding.EncodeToString
+	set := map[string]any{
+		"keys": []map[string]string{
+			{"kty": "EC", "kid": "ec-1", "crv": "P-256", "x": enc(ecKey.X.Bytes()), "y": enc(ecKey.Y.Bytes())},
+			{"kty": "RSA", "kid": "rsa-1", "alg": "RS256", "n": enc(rsaKey.N.Bytes()), "e": "AQAB"},
+			{"kty": "oct", "kid": "hmac-1", "k": enc(testSecret)},
+			{"kty": "RSA", "kid": "enc-1", "use": "enc", "n": enc(rsaKey.N.Bytes()), "e": "AQAB"},
+		},
+	}
+	data, _ := json.Marshal(set)
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	os.WriteFile(path, data, 0o600)
+
+	keys, err := jwt.LoadJWKSFile(path)
+	if err != nil {
+		t.Fatalf("LoadJWKSFile() unexpected error: %v", err)
+	}
+
+	if len(keys) != 3 {
+		t.Fatalf("LoadJWKSFile() returned %d keys, want 3", len(keys))
+	}
+
+	verifier, _ := jwt.NewVerifier(jwt.Config{Keys: keys})
+
+	for _, key := range []jwt.SigningKey{
+		{ID: "ec-1", Algorithm: jwt.ES256, Private: ecKey},
+		{ID: "rsa-1", Algorithm: jwt.RS256, Private: rsaKey},
+		{ID: "hmac-1", Algorithm: jwt.HS256, Secret: testSecret},
+	} {
+		if _, err := verifier.Verify(context.Background(), mint(t, validClaims(), key)); err != nil {
+			t.Errorf("Verify() with %s key unexpected error: %v", key.ID, err)
+		}
+	}
+}
+
+func TestLoadHMACKeyFile(t *testing.T) {
+	dir := t.TempDir()
+
+	short := filepath.Join(dir, "short")
+	os.WriteFile(short, []byte("too-short"), 0o600)
+	if _, err := jwt.LoadHMACKeyFile(short, ""); err == nil {
+		t.Errorf("LoadHMACKeyFile() short secret expected error, got nil")
+	}
+
+	good := filepath.Join(dir, "good")
+	os.WriteFile(good, append(testSecret, '\n'), 0o600)
+	key, err := jwt.LoadHMACKeyFile(good, "")
+	if err != nil {
+		t.Fatalf("LoadHMACKeyFile() unexpected error: %v", err)
+	}
+
+	if string(key.Secret) != string(testSecret) {
+		t.Errorf("LoadHMACKeyFile() did not trim trailing newline")
+	}
+}
+
+func writePublicKey(t *testing.T, dir, name string, public any) string {
+	t.Helper()
+
+	der, err := x509.MarshalPKIXPublicKey(public)
+	if err != nil {
+		t.Fatalf("MarshalPKIXPublicKey() unexpected error: %v", err)
+	}
+
+	path := filepath.Join(dir, name)
+	data := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("WriteFile() unexpected error: %v", err)
+	}
+	return path
+}
@@ -0,0 +1,41 @@
+package password
+
+import (
+	"errors"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/leinonen/hexagonal-architecture-go/internal/ports"
+)
+
+type BcryptHasher struct {
+	cost int
+}
+
+// NewBcryptHasher returns a hasher using the given bcrypt cost. A cost of zero
+// selects bcrypt.DefaultCost.
+func NewBcryptHasher(cost int) ports.PasswordHasher {
+	if cost == 0 {
+		cost = bcrypt.DefaultCost
+	}
+	return &BcryptHasher{cost: cost}
+}
+
+func (h *BcryptHasher) Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), h.cost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+func (h *BcryptHasher) Verify(hash, password string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,36 @@
+package password_test
+
+import (
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/leinonen/hexagonal-architecture-go/internal/adapters/password"
+)
+
+func TestBcryptHasher(t *testing.T) {
+	hasher := password.NewBcryptHasher(bcrypt.MinCost)
+
+	hash, err := hasher.Hash("correcthorse42")
+	if err != nil {
+		t.Fatalf("Hash() unexpected error: %v", err)
+	}
+
+	if hash == "correcthorse42" {
+		t.Fatalf("Hash() returned the plaintext password")
+	}
+
+	ok, err := hasher.Verify(hash, "correcthorse42")
+	if err != nil || !ok {
+		t.Errorf("Verify() correct password = %v, %v, want true", ok, err)
+	}
+
+	ok, err = hasher.Verify(hash, "wronghorse42")
+	if err != nil || ok {
+		t.Errorf("Verify() wrong password = %v, %v, want false", ok, err)
+	}
+
+	if _, err := hasher.Verify("not-a-hash", "correcthorse42"); err == nil {
+		t.Errorf("Verify() malformed hash expected error, got nil")
+	}
+}
@@ -0,0 +1,119 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/leinonen/hexagonal-architecture-go/internal/domain"
+	"github.com/leinonen/hexagonal-architecture-go/internal/errors"
+	"github.com/leinonen/hexagonal-architecture-go/internal/ports"
+)
+
+// AlertRuleRepository keeps rules in the order they were created, which is
+// the order ListByUser and List return them in.
+type AlertRuleRepository struct {
+	mu     sync.RWMutex
+	rules  []*domain.AlertRule
+	nextID int
+}
+
+func NewAlertRuleRepository() ports.AlertRuleRepository {
+	return &AlertRuleRepository{}
+}
+
+func (r *AlertRuleRepository) Create(ctx context.Context, rule *domain.AlertRule) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.nextID++
+	rule.ID = fmt.Sprintf("alert_%d", r.nextID)
+	stored := *rule
+	r.rules = append(r.rules, &stored)
+
+	return nil
+}
+
+func (r *AlertRuleRepository) GetByID(ctx context.Context, id string) (*domain.AlertRule, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if i := r.index(id); i >= 0 {
+		rule := *r.rules[i]
+		return &rule, nil
+	}
+
+	return nil, errors.NewNotFoundError("alert rule not found")
+}
+
+func (r *AlertRuleRepository) ListByUser(ctx context.Context, userID string) ([]*domain.AlertRule, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	rules := make([]*domain.AlertRule, 0)
+	for _, stored := range r.rules {
+		if stored.UserID == userID {
+			rule := *stored
+			rules = append(rules, &rule)
+		}
+	}
+
+	return rules, nil
+}
+
+func (r *AlertRuleRepository) List(ctx context.Context) ([]*domain.AlertRule, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	rules := make([]*domain.AlertRule, len(r.rules))
+	for i, stored := range r.rules {
+		rule := *stored
+		rules[i] = &rule
+	}
+
+	return rules, nil
+}
+
+func (r *AlertRuleRepository) Update(ctx context.Context, rule *domain.AlertRule) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i := r.index(rule.ID)
+	if i < 0 {
+		return errors.NewNotFoundError("alert rule not found")
+	}
+
+	stored := *rule
+	r.rules[i] = &stored
+	return nil
+}
+
+func (r *AlertRuleRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i := r.index(id)
+	if i < 0 {
+		return errors.NewNotFoundError("alert rule not found")
+	}
+
+	r.rules = slices.Delete(r.rules, i, i+1)
+	return nil
+}
+
+func (r *AlertRuleRepository) DeleteByUser(ctx context.Context, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.rules = slices.DeleteFunc(r.rules, func(rule *domain.AlertRule) bool {
+		return rule.UserID == userID
+	})
+	return nil
+}
+
+func (r *AlertRuleRepository) index(id string) int {
+	return slices.IndexFunc(r.rules, func(rule *domain.AlertRule) bool {
+		return rule.ID == id
+	})
+}
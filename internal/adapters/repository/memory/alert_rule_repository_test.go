@@ -0,0 +1,60 @@
+package memory_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/leinonen/hexagonal-architecture-go/internal/adapters/repository/memory"
+	"github.com/leinonen/hexagonal-architecture-go/internal/domain"
+	"github.com/leinonen/hexagonal-architecture-go/internal/errors"
+)
+
+func TestAlertRuleRepository_CRUD(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewAlertRuleRepository()
+
+	for _, userID := range []string{"user_1", "user_2", "user_1"} {
+		rule, _ := domain.NewAlertRule(userID, domain.CityQuery("London"), domain.MetricTemperature, domain.ComparatorBelow, 0, "")
+		if err := repo.Create(ctx, rule); err != nil {
+			t.Fatalf("Create() unexpected error: %v", err)
+		}
+		if rule.ID == "" {
+			t.Errorf("Create() should assign an ID to the rule")
+		}
+	}
+
+	rules, err := repo.ListByUser(ctx, "user_1")
+	if err != nil {
+		t.Fatalf("ListByUser() unexpected error: %v", err)
+	}
+	if len(rules) != 2 {
+		t.Fatalf("ListByUser() returned %d rules, want the user's 2", len(rules))
+	}
+
+	all, err := repo.List(ctx)
+	if err != nil || len(all) != 3 || all[1].UserID != "user_2" {
+		t.Fatalf("List() = %v, %v, want every rule in the order created", all, err)
+	}
+
+	rule := rules[0]
+	rule.Triggered = true
+	if err := repo.Update(ctx, rule); err != nil {
+		t.Fatalf("Update() unexpected error: %v", err)
+	}
+	rule.Threshold = 10
+
+	stored, err := repo.GetByID(ctx, rule.ID)
+	if err != nil || !stored.Triggered || stored.Threshold != 0 {
+		t.Errorf("GetByID() = %+v, %v, want the saved update only", stored, err)
+	}
+
+	if err := repo.Delete(ctx, rule.ID); err != nil {
+		t.Fatalf("Delete() unexpected error: %v", err)
+	}
+	if _, err := repo.GetByID(ctx, rule.ID); !errors.IsNotFound(err) {
+		t.Errorf("GetByID() after delete error = %v, want not found", err)
+	}
+	if err := repo.Update(ctx, rule); !errors.IsNotFound(err) {
+		t.Errorf("Update() of deleted rule error = %v, want not found", err)
+	}
+}
@@ -0,0 +1,96 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/leinonen/hexagonal-architecture-go/internal/domain"
+	"github.com/leinonen/hexagonal-architecture-go/internal/errors"
+	"github.com/leinonen/hexagonal-architecture-go/internal/ports"
+)
+
+type APIKeyRepository struct {
+	mu     sync.RWMutex
+	keys   map[string]*domain.APIKey
+	nextID int
+}
+
+func NewAPIKeyRepository() ports.APIKeyRepository {
+	return &APIKeyRepository{
+		keys: make(map[string]*domain.APIKey),
+	}
+}
+
+func (r *APIKeyRepository) Create(ctx context.Context, key *domain.APIKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, k := range r.keys {
+		if k.Hash == key.Hash {
+			return errors.NewConflictError("api key already exists")
+		}
+	}
+
+	r.nextID++
+	key.ID = fmt.Sprintf("key_%d", r.nextID)
+	r.keys[key.ID] = key
+
+	return nil
+}
+
+func (r *APIKeyRepository) GetByHash(ctx context.Context, hash string) (*domain.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, key := range r.keys {
+		if key.Hash == hash {
+			return key, nil
+		}
+	}
+
+	return nil, errors.NewNotFoundError("api key not found")
+}
+
+func (r *APIKeyRepository) ListByUser(ctx context.Context, userID string) ([]*domain.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	keys := make([]*domain.APIKey, 0)
+	for _, key := range r.keys {
+		if key.UserID == userID {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+	})
+
+	return keys, nil
+}
+
+func (r *APIKeyRepository) Update(ctx context.Context, key *domain.APIKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.keys[key.ID]; !exists {
+		return errors.NewNotFoundError("api key not found")
+	}
+
+	r.keys[key.ID] = key
+	return nil
+}
+
+func (r *APIKeyRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.keys[id]; !exists {
+		return errors.NewNotFoundError("api key not found")
+	}
+
+	delete(r.keys, id)
+	return nil
+}
@@ -0,0 +1,82 @@
+package memory_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/leinonen/hexagonal-architecture-go/internal/adapters/repository/memory"
+	"github.com/leinonen/hexagonal-architecture-go/internal/domain"
+	"github.com/leinonen/hexagonal-architecture-go/internal/errors"
+)
+
+func TestAPIKeyRepository_CreateAndGetByHash(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewAPIKeyRepository()
+
+	key, _ := domain.NewAPIKey("user_1", "laptop", "hak_abcdefgh", "hash_1")
+
+	if err := repo.Create(ctx, key); err != nil {
+		t.Fatalf("Create() unexpected error: %v", err)
+	}
+
+	if key.ID == "" {
+		t.Errorf("Create() should assign an ID to the key")
+	}
+
+	duplicate, _ := domain.NewAPIKey("user_2", "other", "hak_abcdefgh", "hash_1")
+	if err := repo.Create(ctx, duplicate); !errors.IsConflict(err) {
+		t.Errorf("Create() duplicate hash should return conflict error")
+	}
+
+	retrieved, err := repo.GetByHash(ctx, "hash_1")
+	if err != nil {
+		t.Fatalf("GetByHash() unexpected error: %v", err)
+	}
+
+	if retrieved.ID != key.ID {
+		t.Errorf("GetByHash() returned wrong key")
+	}
+
+	if _, err := repo.GetByHash(ctx, "missing"); !errors.IsNotFound(err) {
+		t.Errorf("GetByHash() unknown hash should return not found error")
+	}
+}
+
+func TestAPIKeyRepository_ListByUser(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewAPIKeyRepository()
+
+	for i, userID := range []string{"user_1", "user_1", "user_2"} {
+		key, _ := domain.NewAPIKey(userID, "key", "hak_", string(rune('a'+i)))
+		repo.Create(ctx, key)
+	}
+
+	keys, err := repo.ListByUser(ctx, "user_1")
+	if err != nil {
+		t.Fatalf("ListByUser() unexpected error: %v", err)
+	}
+
+	if len(keys) != 2 {
+		t.Errorf("ListByUser() returned %d keys, want 2", len(keys))
+	}
+}
+
+func TestAPIKeyRepository_Delete(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewAPIKeyRepository()
+
+	key, _ := domain.NewAPIKey("user_1", "laptop", "hak_", "hash_1")
+	repo.Create(ctx, key)
+
+	if err := repo.Delete(ctx, key.ID); err != nil {
+		t.Fatalf("Delete() unexpected error: %v", err)
+	}
+
+	if _, err := repo.GetByHash(ctx, "hash_1"); !errors.IsNotFound(err) {
+		t.Errorf("Delete() key should not exist after deletion")
+	}
+
+	if err := repo.Delete(ctx, key.ID); !errors.IsNotFound(err) {
+		t.Errorf("Delete() non-existing key should return not found error")
+	}
+}
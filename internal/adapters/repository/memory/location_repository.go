@@ -0,0 +1,106 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/leinonen/hexagonal-architecture-go/internal/domain"
+	"github.com/leinonen/hexagonal-architecture-go/internal/errors"
+	"github.com/leinonen/hexagonal-architecture-go/internal/ports"
+)
+
+// LocationRepository keeps locations in the order they were saved, which is
+// the order ListByUser returns them in.
+type LocationRepository struct {
+	mu        sync.RWMutex
+	locations []*domain.Location
+	nextID    int
+}
+
+func NewLocationRepository() ports.LocationRepository {
+	return &LocationRepository{}
+}
+
+func (r *LocationRepository) Create(ctx context.Context, location *domain.Location) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.nextID++
+	location.ID = fmt.Sprintf("loc_%d", r.nextID)
+	stored := *location
+	r.locations = append(r.locations, &stored)
+
+	return nil
+}
+
+func (r *LocationRepository) GetByID(ctx context.Context, id string) (*domain.Location, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if i := r.index(id); i >= 0 {
+		location := *r.locations[i]
+		return &location, nil
+	}
+
+	return nil, errors.NewNotFoundError("location not found")
+}
+
+func (r *LocationRepository) ListByUser(ctx context.Context, userID string) ([]*domain.Location, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	locations := make([]*domain.Location, 0)
+	for _, stored := range r.locations {
+		if stored.UserID == userID {
+			location := *stored
+			locations = append(locations, &location)
+		}
+	}
+
+	return locations, nil
+}
+
+func (r *LocationRepository) Update(ctx context.Context, location *domain.Location) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i := r.index(location.ID)
+	if i < 0 {
+		return errors.NewNotFoundError("location not found")
+	}
+
+	stored := *location
+	r.locations[i] = &stored
+	return nil
+}
+
+func (r *LocationRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i := r.index(id)
+	if i < 0 {
+		return errors.NewNotFoundError("location not found")
+	}
+
+	r.locations = slices.Delete(r.locations, i, i+1)
+	return nil
+}
+
+func (r *LocationRepository) DeleteByUser(ctx context.Context, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.locations = slices.DeleteFunc(r.locations, func(location *domain.Location) bool {
+		return location.UserID == userID
+	})
+	return nil
+}
+
+func (r *LocationRepository) index(id string) int {
+	return slices.IndexFunc(r.locations, func(location *domain.Location) bool {
+		return location.ID == id
+	})
+}
@@ -0,0 +1,60 @@
+package memory_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/leinonen/hexagonal-architecture-go/internal/adapters/repository/memory"
+	"github.com/leinonen/hexagonal-architecture-go/internal/domain"
+	"github.com/leinonen/hexagonal-architecture-go/internal/errors"
+)
+
+func TestLocationRepository_CRUD(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewLocationRepository()
+
+	for _, city := range []string{"London", "Paris", "Berlin"} {
+		location, _ := domain.NewLocation("user_1", "", domain.CityQuery(city))
+		if err := repo.Create(ctx, location); err != nil {
+			t.Fatalf("Create() unexpected error: %v", err)
+		}
+		if location.ID == "" {
+			t.Errorf("Create() should assign an ID to the location")
+		}
+	}
+	other, _ := domain.NewLocation("user_2", "", domain.CityQuery("Oslo"))
+	repo.Create(ctx, other)
+
+	locations, err := repo.ListByUser(ctx, "user_1")
+	if err != nil {
+		t.Fatalf("ListByUser() unexpected error: %v", err)
+	}
+	if len(locations) != 3 || locations[0].Name != "London" || locations[2].Name != "Berlin" {
+		t.Fatalf("ListByUser() = %v, want the user's three locations in the order saved", locations)
+	}
+
+	paris := locations[1]
+	paris.Primary = true
+	if err := repo.Update(ctx, paris); err != nil {
+		t.Fatalf("Update() unexpected error: %v", err)
+	}
+	paris.Name = "changed without saving"
+
+	stored, err := repo.GetByID(ctx, paris.ID)
+	if err != nil || !stored.Primary || stored.Name != "Paris" {
+		t.Errorf("GetByID() = %+v, %v, want the saved update only", stored, err)
+	}
+
+	if err := repo.Delete(ctx, paris.ID); err != nil {
+		t.Fatalf("Delete() unexpected error: %v", err)
+	}
+	if _, err := repo.GetByID(ctx, paris.ID); !errors.IsNotFound(err) {
+		t.Errorf("GetByID() after delete error = %v, want not found", err)
+	}
+	if err := repo.Delete(ctx, paris.ID); !errors.IsNotFound(err) {
+		t.Errorf("Delete() twice error = %v, want not found", err)
+	}
+	if err := repo.Update(ctx, paris); !errors.IsNotFound(err) {
+		t.Errorf("Update() of deleted location error = %v, want not found", err)
+	}
+}
@@ -0,0 +1,138 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/leinonen/hexagonal-architecture-go/internal/domain"
+	"github.com/leinonen/hexagonal-architecture-go/internal/ports"
+)
+
+// DefaultObservationRetention is how long observations are kept by default.
+const DefaultObservationRetention = 30 * 24 * time.Hour
+
+// maxObservationSweepInterval bounds how long expired observations of places
+// no longer looked up are kept past their retention.
+const maxObservationSweepInterval = time.Hour
+
+// ObservationRepository keeps each place's observations in time order and
+// forgets those older than the retention period. A reading the provider
+// reports again unchanged, such as one served twice within its update
+// interval, is kept once. The lookups that fetched a place's weather are kept
+// as aliases of the place, so its history can be listed by any of them.
+//
+// Expired observations are dropped from the place being written, and every
+// place is swept on writes at least an hour, or a retention period, apart.
+// Places left without observations are forgotten along with their aliases.
+type ObservationRepository struct {
+	retention time.Duration
+
+	mu           sync.RWMutex
+	observations map[string][]*domain.Observation
+	aliases      map[string]string
+	nextID       int
+	sweptAt      time.Time
+}
+
+func NewObservationRepository(retention time.Duration) ports.ObservationRepository {
+	if retention <= 0 {
+		retention = DefaultObservationRetention
+	}
+
+	return &ObservationRepository{
+		retention:    retention,
+		observations: make(map[string][]*domain.Observation),
+		aliases:      make(map[string]string),
+	}
+}
+
+func (r *ObservationRepository) Record(ctx context.Context, observation *domain.Observation) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := observation.Query.Key()
+	if !observation.Lookup.IsZero() && observation.Lookup.Key() != key {
+		r.aliases[observation.Lookup.Key()] = key
+	}
+	observations := r.observations[key]
+
+	if n := len(observations); n > 0 {
+		last := observations[n-1]
+		if !observation.Weather.ObservedAt.IsZero() && last.Weather.ObservedAt.Equal(observation.Weather.ObservedAt) && last.Weather.Provider == observation.Weather.Provider {
+			observation.ID = last.ID
+			return nil
+		}
+	}
+
+	r.nextID++
+	observation.ID = fmt.Sprintf("obs_%d", r.nextID)
+	stored := *observation
+
+	// Providers may answer out of order, so keep the slice sorted.
+	at := stored.Time()
+	i := sort.Search(len(observations), func(i int) bool {
+		return observations[i].Time().After(at)
+	})
+	observations = append(observations, nil)
+	copy(observations[i+1:], observations[i:])
+	observations[i] = &stored
+
+	now := time.Now()
+	cutoff := now.Add(-r.retention)
+	r.observations[key] = observations
+	r.expire(key, cutoff)
+
+	if now.Sub(r.sweptAt) >= min(r.retention, maxObservationSweepInterval) {
+		r.sweptAt = now
+		for key := range r.observations {
+			r.expire(key, cutoff)
+		}
+	}
+
+	return nil
+}
+
+// expire drops the observations of a place made before cutoff, forgetting
+// the place once none are left.
+func (r *ObservationRepository) expire(key string, cutoff time.Time) {
+	observations := r.observations[key]
+	expired := sort.Search(len(observations), func(i int) bool {
+		return !observations[i].Time().Before(cutoff)
+	})
+	if expired < len(observations) {
+		r.observations[key] = observations[expired:]
+		return
+	}
+
+	delete(r.observations, key)
+	for alias, place := range r.aliases {
+		if place == key {
+			delete(r.aliases, alias)
+		}
+	}
+}
+
+func (r *ObservationRepository) List(ctx context.Context, query domain.WeatherQuery, from, to time.Time) ([]*domain.Observation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	key := query.Key()
+	if place, ok := r.aliases[key]; ok {
+		key = place
+	}
+
+	observations := make([]*domain.Observation, 0)
+	for _, stored := range r.observations[key] {
+		at := stored.Time()
+		if at.Before(from) || !at.Before(to) {
+			continue
+		}
+		observation := *stored
+		observations = append(observations, &observation)
+	}
+
+	return observations, nil
+}
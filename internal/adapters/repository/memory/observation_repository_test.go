@@ -0,0 +1,109 @@
+package memory_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/leinonen/hexagonal-architecture-go/internal/adapters/repository/memory"
+	"github.com/leinonen/hexagonal-architecture-go/internal/domain"
+)
+
+func TestObservationRepository_RecordAndList(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewObservationRepository(24 * time.Hour)
+	now := time.Now().Truncate(time.Second)
+
+	record := func(query domain.WeatherQuery, observedAt time.Time, temperature float64) *domain.Observation {
+		observation := domain.NewObservation(query, &domain.Weather{Temperature: temperature, Provider: "fake", ObservedAt: observedAt})
+		if err := repo.Record(ctx, observation); err != nil {
+			t.Fatalf("Record() unexpected error: %v", err)
+		}
+		return observation
+	}
+
+	record(domain.CityQuery("London"), now.Add(-time.Hour), 1)
+	record(domain.CityQuery("london "), now.Add(-3*time.Hour), 3)
+	record(domain.CityQuery("London"), now.Add(-2*time.Hour), 2)
+	record(domain.CityQuery("Paris"), now.Add(-time.Hour), 20)
+	record(domain.CityQuery("London"), now.Add(-48*time.Hour), 48)
+
+	first := record(domain.CityQuery("London"), now, 0)
+	repeated := record(domain.CityQuery("London"), now, 0)
+	if repeated.ID != first.ID {
+		t.Errorf("Record() of an unchanged reading ID = %s, want the first recording's %s", repeated.ID, first.ID)
+	}
+
+	observations, err := repo.List(ctx, domain.CityQuery("LONDON"), now.Add(-72*time.Hour), now.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("List() unexpected error: %v", err)
+	}
+
+	var temperatures []float64
+	for _, observation := range observations {
+		temperatures = append(temperatures, observation.Weather.Temperature)
+	}
+	want := []float64{3, 2, 1, 0}
+	if len(temperatures) != len(want) {
+		t.Fatalf("List() temperatures = %v, want %v oldest first, without expired or repeated readings", temperatures, want)
+	}
+	for i := range want {
+		if temperatures[i] != want[i] {
+			t.Fatalf("List() temperatures = %v, want %v oldest first, without expired or repeated readings", temperatures, want)
+		}
+	}
+
+	observations, _ = repo.List(ctx, domain.CityQuery("London"), now.Add(-2*time.Hour), now)
+	if len(observations) != 2 {
+		t.Errorf("List() in [-2h, now) returned %d observations, want 2", len(observations))
+	}
+}
+
+func TestObservationRepository_ListByLookup(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewObservationRepository(0)
+	now := time.Now()
+	london := &domain.Weather{City: "London", Country: "GB", ObservedAt: now.Add(-time.Hour)}
+
+	repo.Record(ctx, domain.NewObservation(domain.CityQuery("london"), london))
+	repo.Record(ctx, domain.NewObservation(domain.WeatherQuery{Coordinates: &domain.Coordinates{Latitude: 51.51, Longitude: -0.13}}, london))
+
+	tests := []struct {
+		name  string
+		query domain.WeatherQuery
+		want  int
+	}{
+		{name: "resolved place", query: domain.WeatherQuery{City: "London", Country: "gb"}, want: 1},
+		{name: "city lookup", query: domain.CityQuery("London"), want: 1},
+		{name: "coordinate lookup", query: domain.WeatherQuery{Coordinates: &domain.Coordinates{Latitude: 51.51, Longitude: -0.13}}, want: 1},
+		{name: "place never looked up", query: domain.CityQuery("Paris"), want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			observations, err := repo.List(ctx, tt.query, now.Add(-2*time.Hour), now)
+			if err != nil || len(observations) != tt.want {
+				t.Errorf("List() = %d observations, %v, want %d", len(observations), err, tt.want)
+			}
+		})
+	}
+}
+
+func TestObservationRepository_ExpiresEveryPlace(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewObservationRepository(50 * time.Millisecond)
+
+	repo.Record(ctx, domain.NewObservation(domain.CityQuery("paris"), &domain.Weather{City: "Paris", Country: "FR"}))
+	time.Sleep(60 * time.Millisecond)
+	repo.Record(ctx, domain.NewObservation(domain.CityQuery("London"), &domain.Weather{City: "London", Country: "GB"}))
+
+	now := time.Now()
+	for _, query := range []domain.WeatherQuery{domain.CityQuery("paris"), {City: "Paris", Country: "FR"}} {
+		if observations, _ := repo.List(ctx, query, now.Add(-time.Hour), now); len(observations) != 0 {
+			t.Errorf("List(%s) = %d observations, want the expired Paris readings gone", query.Key(), len(observations))
+		}
+	}
+	if observations, _ := repo.List(ctx, domain.CityQuery("London"), now.Add(-time.Hour), now); len(observations) != 1 {
+		t.Errorf("List(London) = %d observations, want 1", len(observations))
+	}
+}
@@ -0,0 +1,80 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/leinonen/hexagonal-architecture-go/internal/domain"
+	"github.com/leinonen/hexagonal-architecture-go/internal/errors"
+	"github.com/leinonen/hexagonal-architecture-go/internal/ports"
+)
+
+type SessionRepository struct {
+	mu       sync.RWMutex
+	sessions map[string]*domain.Session
+	nextID   int
+}
+
+func NewSessionRepository() ports.SessionRepository {
+	return &SessionRepository{
+		sessions: make(map[string]*domain.Session),
+	}
+}
+
+func (r *SessionRepository) Create(ctx context.Context, session *domain.Session) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.nextID++
+	session.ID = fmt.Sprintf("session_%d", r.nextID)
+	r.sessions[session.ID] = session
+
+	return nil
+}
+
+func (r *SessionRepository) GetByID(ctx context.Context, id string) (*domain.Session, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	session, exists := r.sessions[id]
+	if !exists {
+		return nil, errors.NewNotFoundError("session not found")
+	}
+
+	copied := *session
+	copied.RotatedTokenHashes = append([]string(nil), session.RotatedTokenHashes...)
+	return &copied, nil
+}
+
+func (r *SessionRepository) Update(ctx context.Context, session *domain.Session) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.sessions[session.ID]; !exists {
+		return errors.NewNotFoundError("session not found")
+	}
+
+	r.sessions[session.ID] = session
+	return nil
+}
+
+func (r *SessionRepository) ListByUser(ctx context.Context, userID string) ([]*domain.Session, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	sessions := make([]*domain.Session, 0)
+	for _, session := range r.sessions {
+		if session.UserID == userID {
+			copied := *session
+			sessions = append(sessions, &copied)
+		}
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].CreatedAt.Before(sessions[j].CreatedAt)
+	})
+
+	return sessions, nil
+}
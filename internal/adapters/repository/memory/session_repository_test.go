@@ -0,0 +1,71 @@
+package memory_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/leinonen/hexagonal-architecture-go/internal/adapters/repository/memory"
+	"github.com/leinonen/hexagonal-architecture-go/internal/domain"
+	"github.com/leinonen/hexagonal-architecture-go/internal/errors"
+)
+
+func TestSessionRepository_CreateGetUpdate(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewSessionRepository()
+
+	session, _ := domain.NewSession("user_1", "hash_1", domain.ClientInfo{UserAgent: "curl"})
+	if err := repo.Create(ctx, session); err != nil {
+		t.Fatalf("Create() unexpected error: %v", err)
+	}
+
+	if session.ID == "" {
+		t.Fatalf("Create() should assign an ID to the session")
+	}
+
+	retrieved, err := repo.GetByID(ctx, session.ID)
+	if err != nil {
+		t.Fatalf("GetByID() unexpected error: %v", err)
+	}
+
+	retrieved.Rotate("hash_2", time.Now())
+	if stored, _ := repo.GetByID(ctx, session.ID); stored.RefreshTokenHash != "hash_1" {
+		t.Errorf("GetByID() should return a copy, stored hash = %v", stored.RefreshTokenHash)
+	}
+
+	if err := repo.Update(ctx, retrieved); err != nil {
+		t.Fatalf("Update() unexpected error: %v", err)
+	}
+
+	stored, _ := repo.GetByID(ctx, session.ID)
+	if stored.RefreshTokenHash != "hash_2" || !stored.WasRotatedFrom("hash_1") {
+		t.Errorf("Update() did not persist the rotation")
+	}
+
+	if _, err := repo.GetByID(ctx, "missing"); !errors.IsNotFound(err) {
+		t.Errorf("GetByID() unknown ID should return not found error")
+	}
+
+	if err := repo.Update(ctx, &domain.Session{ID: "missing"}); !errors.IsNotFound(err) {
+		t.Errorf("Update() unknown ID should return not found error")
+	}
+}
+
+func TestSessionRepository_ListByUser(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewSessionRepository()
+
+	for _, userID := range []string{"user_1", "user_1", "user_2"} {
+		session, _ := domain.NewSession(userID, "hash", domain.ClientInfo{})
+		repo.Create(ctx, session)
+	}
+
+	sessions, err := repo.ListByUser(ctx, "user_1")
+	if err != nil {
+		t.Fatalf("ListByUser() unexpected error: %v", err)
+	}
+
+	if len(sessions) != 2 {
+		t.Errorf("ListByUser() returned %d sessions, want 2", len(sessions))
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/leinonen/hexagonal-architecture-go/internal/domain"
 	"github.com/leinonen/hexagonal-architecture-go/internal/errors"
@@ -76,6 +77,36 @@ func (r *UserRepository) Update(ctx context.Context, user *domain.User) error {
 	return nil
 }
 
+func (r *UserRepository) RecordFailedLogin(ctx context.Context, id string, now time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, exists := r.users[id]
+	if !exists {
+		return errors.NewNotFoundError("user not found")
+	}
+
+	updated := *user
+	updated.RecordFailedLogin(now)
+	r.users[id] = &updated
+	return nil
+}
+
+func (r *UserRepository) ResetFailedLogins(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, exists := r.users[id]
+	if !exists {
+		return errors.NewNotFoundError("user not found")
+	}
+
+	updated := *user
+	updated.RecordSuccessfulLogin()
+	r.users[id] = &updated
+	return nil
+}
+
 func (r *UserRepository) Delete(ctx context.Context, id string) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
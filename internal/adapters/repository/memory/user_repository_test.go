@@ -2,7 +2,9 @@ package memory_test
 
 import (
 	"context"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/leinonen/hexagonal-architecture-go/internal/adapters/repository/memory"
 	"github.com/leinonen/hexagonal-architecture-go/internal/domain"
@@ -222,6 +224,41 @@ func TestUserRepository_List(t *testing.T) {
 	}
 }
 
+func TestUserRepository_RecordFailedLogin(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewUserRepository()
+
+	user := &domain.User{Email: "test@example.com", Name: "Test User"}
+	repo.Create(ctx, user)
+
+	now := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < domain.MaxFailedLogins; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			repo.RecordFailedLogin(ctx, user.ID, now)
+		}()
+	}
+	wg.Wait()
+
+	stored, _ := repo.GetByID(ctx, user.ID)
+	if !stored.IsLocked(now) {
+		t.Errorf("RecordFailedLogin() %d concurrent failures did not lock the account", domain.MaxFailedLogins)
+	}
+
+	if err := repo.ResetFailedLogins(ctx, user.ID); err != nil {
+		t.Fatalf("ResetFailedLogins() unexpected error: %v", err)
+	}
+	if stored, _ := repo.GetByID(ctx, user.ID); stored.IsLocked(now) || stored.FailedLogins != 0 {
+		t.Errorf("ResetFailedLogins() left %d failures, locked until %v", stored.FailedLogins, stored.LockedUntil)
+	}
+
+	if err := repo.RecordFailedLogin(ctx, "non_existing", now); !errors.IsNotFound(err) {
+		t.Errorf("RecordFailedLogin() unknown user error = %v, want not found", err)
+	}
+}
+
 func TestUserRepository_Concurrency(t *testing.T) {
 	ctx := context.Background()
 	repo := memory.NewUserRepository()
@@ -0,0 +1,194 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/leinonen/hexagonal-architecture-go/internal/domain"
+	"github.com/leinonen/hexagonal-architecture-go/internal/dto"
+	"github.com/leinonen/hexagonal-architecture-go/internal/errors"
+)
+
+const (
+	// EventAlert is the event of every alert delivery.
+	EventAlert = "weather.alert"
+
+	// SignatureHeader carries "sha256=" and the hex HMAC-SHA256 of the
+	// timestamp, a dot and the body, keyed with the shared secret.
+	SignatureHeader = "X-Webhook-Signature"
+	// TimestampHeader carries the Unix time the delivery was signed at, so
+	// that receivers can reject replays.
+	TimestampHeader = "X-Webhook-Timestamp"
+	// DeliveryHeader identifies an alert. Retries of the same alert carry the
+	// same ID, so that receivers can drop duplicates.
+	DeliveryHeader = "X-Webhook-Delivery"
+)
+
+type Config struct {
+	URL    string
+	Secret []byte
+	// MaxAttempts is how many times a delivery is tried. Only network
+	// errors, 429 and 5xx responses are retried.
+	MaxAttempts int
+	// BaseDelay is the wait before the first retry; it doubles on each one.
+	BaseDelay time.Duration
+	// Timeout bounds each attempt.
+	Timeout time.Duration
+}
+
+func DefaultConfig() Config {
+	return Config{
+		MaxAttempts: 4,
+		BaseDelay:   time.Second,
+		Timeout:     10 * time.Second,
+	}
+}
+
+// Notifier is a ports.Notifier that POSTs alerts as signed JSON to a
+// webhook.
+type Notifier struct {
+	config Config
+	client *http.Client
+}
+
+func NewNotifier(config Config) *Notifier {
+	defaults := DefaultConfig()
+	if config.MaxAttempts < 1 {
+		config.MaxAttempts = defaults.MaxAttempts
+	}
+	if config.BaseDelay <= 0 {
+		config.BaseDelay = defaults.BaseDelay
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = defaults.Timeout
+	}
+
+	return &Notifier{
+		config: config,
+		client: &http.Client{Timeout: config.Timeout},
+	}
+}
+
+// AlertPayload is the JSON body of an alert delivery.
+type AlertPayload struct {
+	Event       string                  `json:"event"`
+	RuleID      string                  `json:"rule_id"`
+	UserID      string                  `json:"user_id"`
+	Place       dto.WeatherQueryDTO     `json:"place"`
+	Metric      string                  `json:"metric"`
+	Comparator  string                  `json:"comparator"`
+	Threshold   float64                 `json:"threshold"`
+	Value       float64                 `json:"value"`
+	Units       string                  `json:"units"`
+	TriggeredAt string                  `json:"triggered_at"`
+	Weather     *dto.WeatherResponseDTO `json:"weather"`
+}
+
+func (n *Notifier) Notify(ctx context.Context, alert domain.Alert) error {
+	body, err := json.Marshal(toAlertPayload(alert))
+	if err != nil {
+		return errors.NewInternalError(fmt.Sprintf("failed to encode alert: %v", err))
+	}
+	delivery := fmt.Sprintf("%s-%d", alert.Rule.ID, alert.TriggeredAt.Unix())
+
+	var lastErr error
+	for attempt := 1; attempt <= n.config.MaxAttempts; attempt++ {
+		if attempt > 1 {
+			timer := time.NewTimer(n.config.BaseDelay << (attempt - 2))
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				return ctx.Err()
+			}
+		}
+
+		retry, err := n.post(ctx, delivery, body)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if !retry {
+			break
+		}
+	}
+
+	return lastErr
+}
+
+// post makes one delivery attempt and reports whether a failure is worth
+// retrying.
+func (n *Notifier) post(ctx context.Context, delivery string, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.config.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, errors.NewInternalError(fmt.Sprintf("invalid webhook URL: %v", err))
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(TimestampHeader, timestamp)
+	req.Header.Set(SignatureHeader, Sign(n.config.Secret, timestamp, body))
+	req.Header.Set(DeliveryHeader, delivery)
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, errors.NewExternalServiceError(fmt.Sprintf("webhook delivery failed: %v", err))
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4<<10))
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, errors.NewExternalServiceError(fmt.Sprintf("webhook returned status: %d", resp.StatusCode))
+}
+
+// Sign computes the SignatureHeader value for a delivery.
+func Sign(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks a delivery's signature in constant time. Receivers should
+// also reject timestamps too far from their own clock.
+func Verify(secret []byte, timestamp string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
+
+func toAlertPayload(alert domain.Alert) AlertPayload {
+	rule := alert.Rule
+
+	place := dto.WeatherQueryDTO{City: rule.Query.City, Country: rule.Query.Country}
+	if coordinates := rule.Query.Coordinates; coordinates != nil {
+		latitude, longitude := coordinates.Latitude, coordinates.Longitude
+		place.Latitude, place.Longitude = &latitude, &longitude
+	}
+
+	return AlertPayload{
+		Event:       EventAlert,
+		RuleID:      rule.ID,
+		UserID:      rule.UserID,
+		Place:       place,
+		Metric:      string(rule.Metric),
+		Comparator:  string(rule.Comparator),
+		Threshold:   rule.Threshold,
+		Value:       alert.Value,
+		Units:       string(rule.Units),
+		TriggeredAt: alert.TriggeredAt.UTC().Format(time.RFC3339),
+		Weather:     dto.ToWeatherResponseDTO(alert.Weather),
+	}
+}
@@ -0,0 +1,158 @@
+package webhook_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/leinonen/hexagonal-architecture-go/internal/adapters/webhook"
+	"github.com/leinonen/hexagonal-architecture-go/internal/domain"
+	"github.com/leinonen/hexagonal-architecture-go/internal/errors"
+)
+
+var testSecret = []byte("webhook-secret")
+
+// receiver records deliveries, answering with the given statuses in turn and
+// 204 once they run out.
+type receiver struct {
+	statuses []int
+
+	mu         sync.Mutex
+	deliveries []delivery
+}
+
+type delivery struct {
+	header http.Header
+	body   []byte
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	rc.deliveries = append(rc.deliveries, delivery{header: r.Header, body: body})
+	if len(rc.statuses) > 0 {
+		status := rc.statuses[0]
+		rc.statuses = rc.statuses[1:]
+		w.WriteHeader(status)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func freezingAlert() domain.Alert {
+	rule, _ := domain.NewAlertRule("user_1", domain.CityQuery("London"), domain.MetricTemperature, domain.ComparatorBelow, 0, domain.UnitsMetric)
+	rule.ID = "alert_1"
+
+	return domain.Alert{
+		Rule:        *rule,
+		Weather:     &domain.Weather{City: "London", Country: "GB", Temperature: -2, Units: domain.UnitsMetric},
+		Value:       -2,
+		TriggeredAt: time.Date(2024, 1, 15, 6, 0, 0, 0, time.UTC),
+	}
+}
+
+func newTestNotifier(url string) *webhook.Notifier {
+	return webhook.NewNotifier(webhook.Config{URL: url, Secret: testSecret, MaxAttempts: 3, BaseDelay: time.Millisecond})
+}
+
+func TestNotifier_SignsDelivery(t *testing.T) {
+	rc := &receiver{}
+	server := httptest.NewServer(rc)
+	defer server.Close()
+
+	if err := newTestNotifier(server.URL).Notify(context.Background(), freezingAlert()); err != nil {
+		t.Fatalf("Notify() unexpected error: %v", err)
+	}
+
+	if len(rc.deliveries) != 1 {
+		t.Fatalf("receiver got %d deliveries, want 1", len(rc.deliveries))
+	}
+	got := rc.deliveries[0]
+
+	timestamp := got.header.Get(webhook.TimestampHeader)
+	if !webhook.Verify(testSecret, timestamp, got.body, got.header.Get(webhook.SignatureHeader)) {
+		t.Errorf("signature %q does not verify", got.header.Get(webhook.SignatureHeader))
+	}
+	if webhook.Verify([]byte("other-secret"), timestamp, got.body, got.header.Get(webhook.SignatureHeader)) {
+		t.Errorf("signature verifies with the wrong secret")
+	}
+	if got.header.Get(webhook.DeliveryHeader) == "" || got.header.Get("Content-Type") != "application/json" {
+		t.Errorf("headers = %v, want a delivery ID and a JSON content type", got.header)
+	}
+
+	var payload webhook.AlertPayload
+	if err := json.Unmarshal(got.body, &payload); err != nil {
+		t.Fatalf("payload is not JSON: %v", err)
+	}
+	if payload.Event != webhook.EventAlert || payload.RuleID != "alert_1" || payload.UserID != "user_1" || payload.Place.City != "London" {
+		t.Errorf("payload = %+v, want the London rule of user_1", payload)
+	}
+	if payload.Metric != "temperature" || payload.Comparator != "lt" || payload.Value != -2 || payload.Weather == nil || payload.TriggeredAt != "2024-01-15T06:00:00Z" {
+		t.Errorf("payload = %+v, want -2 °C below 0 with the weather", payload)
+	}
+}
+
+func TestNotifier_Retries(t *testing.T) {
+	tests := []struct {
+		name         string
+		statuses     []int
+		wantErr      bool
+		wantAttempts int
+	}{
+		{name: "recovers", statuses: []int{http.StatusServiceUnavailable, http.StatusTooManyRequests}, wantAttempts: 3},
+		{name: "gives up", statuses: []int{500, 502, 503}, wantErr: true, wantAttempts: 3},
+		{name: "rejected", statuses: []int{http.StatusBadRequest}, wantErr: true, wantAttempts: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rc := &receiver{statuses: tt.statuses}
+			server := httptest.NewServer(rc)
+			defer server.Close()
+
+			err := newTestNotifier(server.URL).Notify(context.Background(), freezingAlert())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Notify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.IsExternalService(err) {
+				t.Errorf("Notify() error = %v, want external service error", err)
+			}
+
+			if len(rc.deliveries) != tt.wantAttempts {
+				t.Fatalf("receiver got %d deliveries, want %d", len(rc.deliveries), tt.wantAttempts)
+			}
+			first := rc.deliveries[0].header.Get(webhook.DeliveryHeader)
+			for _, retry := range rc.deliveries[1:] {
+				if retry.header.Get(webhook.DeliveryHeader) != first {
+					t.Errorf("retry delivery ID = %q, want the first attempt's %q", retry.header.Get(webhook.DeliveryHeader), first)
+				}
+			}
+		})
+	}
+}
+
+func TestNotifier_StopsWhenContextEnds(t *testing.T) {
+	rc := &receiver{statuses: []int{503, 503, 503}}
+	server := httptest.NewServer(rc)
+	defer server.Close()
+
+	notifier := webhook.NewNotifier(webhook.Config{URL: server.URL, Secret: testSecret, MaxAttempts: 3, BaseDelay: time.Hour})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if err := notifier.Notify(ctx, freezingAlert()); err != context.DeadlineExceeded {
+		t.Errorf("Notify() error = %v, want context.DeadlineExceeded", err)
+	}
+	if len(rc.deliveries) != 1 {
+		t.Errorf("receiver got %d deliveries, want 1", len(rc.deliveries))
+	}
+}
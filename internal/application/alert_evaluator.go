@@ -0,0 +1,168 @@
+package application
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/leinonen/hexagonal-architecture-go/internal/domain"
+	"github.com/leinonen/hexagonal-architecture-go/internal/errors"
+	"github.com/leinonen/hexagonal-architecture-go/internal/ports"
+)
+
+const (
+	DefaultAlertInterval = 10 * time.Minute
+
+	// AlertDeliveryTimeout bounds one alert's delivery, retries included.
+	AlertDeliveryTimeout = 30 * time.Second
+	// AlertDeliveryConcurrency is how many alerts are delivered at once.
+	AlertDeliveryConcurrency = 8
+)
+
+// AlertEvaluator periodically checks every alert rule against the current
+// weather and notifies users whose conditions have started to hold.
+type AlertEvaluator struct {
+	ruleRepo      ports.AlertRuleRepository
+	weatherClient ports.WeatherService
+	notifier      ports.Notifier
+	interval      time.Duration
+}
+
+func NewAlertEvaluator(ruleRepo ports.AlertRuleRepository, weatherClient ports.WeatherService, notifier ports.Notifier, interval time.Duration) *AlertEvaluator {
+	if interval <= 0 {
+		interval = DefaultAlertInterval
+	}
+
+	return &AlertEvaluator{
+		ruleRepo:      ruleRepo,
+		weatherClient: weatherClient,
+		notifier:      notifier,
+		interval:      interval,
+	}
+}
+
+// Run evaluates the rules straight away and then every interval until ctx
+// ends.
+func (e *AlertEvaluator) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := e.Evaluate(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("Alert evaluation: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Evaluate checks every rule once, looking up each place once however many
+// rules watch it. Alerts are delivered concurrently, each within
+// AlertDeliveryTimeout, so a slow receiver does not hold up the other rules.
+// A rule whose lookup or notification fails keeps its previous state, so it
+// is tried again on the next pass. The failures are returned together.
+func (e *AlertEvaluator) Evaluate(ctx context.Context) error {
+	rules, err := e.ruleRepo.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	var (
+		mu       sync.Mutex
+		failures []error
+		wg       sync.WaitGroup
+	)
+	fail := func(rule *domain.AlertRule, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		failures = append(failures, fmt.Errorf("rule %s: %w", rule.ID, err))
+	}
+
+	slots := make(chan struct{}, AlertDeliveryConcurrency)
+	lookups := make(map[string]*domain.Weather)
+
+	for _, rule := range rules {
+		if ctx.Err() != nil {
+			break
+		}
+
+		key := rule.Query.Key()
+		weather, ok := lookups[key]
+		if !ok {
+			weather, err = e.weatherClient.GetWeather(ctx, rule.Query)
+			if err != nil {
+				fail(rule, err)
+				continue
+			}
+			lookups[key] = weather
+		}
+
+		wasTriggered := rule.Triggered
+		now := time.Now()
+		value, fire := rule.Observe(weather, now)
+		if !fire {
+			if rule.Triggered != wasTriggered {
+				if err := e.save(ctx, rule); err != nil {
+					fail(rule, err)
+				}
+			}
+			continue
+		}
+
+		alert := domain.Alert{
+			Rule:        *rule,
+			Weather:     weather.InUnits(rule.Units),
+			Value:       value,
+			TriggeredAt: now,
+		}
+
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+
+			if err := e.deliver(ctx, alert); err != nil {
+				fail(rule, err)
+				return
+			}
+			if err := e.save(ctx, rule); err != nil {
+				fail(rule, err)
+			}
+		}()
+	}
+
+	wg.Wait()
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return stderrors.Join(failures...)
+}
+
+func (e *AlertEvaluator) deliver(ctx context.Context, alert domain.Alert) error {
+	ctx, cancel := context.WithTimeout(ctx, AlertDeliveryTimeout)
+	defer cancel()
+
+	return e.notifier.Notify(ctx, alert)
+}
+
+func (e *AlertEvaluator) save(ctx context.Context, rule *domain.AlertRule) error {
+	// The rule may have been deleted since it was listed.
+	if err := e.ruleRepo.Update(ctx, rule); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package application_test
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/leinonen/hexagonal-architecture-go/internal/application"
+	"github.com/leinonen/hexagonal-architecture-go/internal/domain"
+	"github.com/leinonen/hexagonal-architecture-go/internal/errors"
+)
+
+// thermometer reports the temperature set for each city, counting lookups.
+type thermometer struct {
+	temperatures map[string]float64
+	lookups      int
+}
+
+func (c *thermometer) GetWeather(ctx context.Context, query domain.WeatherQuery) (*domain.Weather, error) {
+	c.lookups++
+	temperature, ok := c.temperatures[query.City]
+	if !ok {
+		return nil, errors.NewNotFoundError("city not found")
+	}
+	return &domain.Weather{City: query.City, Temperature: temperature, Units: domain.UnitsMetric}, nil
+}
+
+func (c *thermometer) GetForecast(ctx context.Context, query domain.WeatherQuery, days int) (*domain.Forecast, error) {
+	return nil, errors.NewInternalError("not implemented")
+}
+
+type recordingNotifier struct {
+	mu     sync.Mutex
+	alerts []domain.Alert
+	err    error
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, alert domain.Alert) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.err != nil {
+		return n.err
+	}
+	n.alerts = append(n.alerts, alert)
+	return nil
+}
+
+func TestAlertEvaluator_Evaluate(t *testing.T) {
+	service, ruleRepo := newAlertFixture(t)
+	weather := &thermometer{temperatures: map[string]float64{"London": 5, "Oslo": -3}}
+	notifier := &recordingNotifier{}
+	evaluator := application.NewAlertEvaluator(ruleRepo, weather, notifier, 0)
+	ctx := context.Background()
+
+	freezing, _ := service.CreateAlertRule(asUser("user_1"), "user_1", domain.CityQuery("London"), domain.MetricTemperature, domain.ComparatorBelow, 32, "")
+	service.CreateAlertRule(asUser("user_2"), "user_2", domain.CityQuery("London"), domain.MetricTemperature, domain.ComparatorAbove, 20, "")
+	service.CreateAlertRule(asUser("user_2"), "user_2", domain.CityQuery("Oslo"), domain.MetricTemperature, domain.ComparatorBelow, 0, "")
+
+	if err := evaluator.Evaluate(ctx); err != nil {
+		t.Fatalf("Evaluate() unexpected error: %v", err)
+	}
+	if weather.lookups != 2 {
+		t.Errorf("weather looked up %d times, want once per place", weather.lookups)
+	}
+	if len(notifier.alerts) != 1 || notifier.alerts[0].Rule.Query.City != "Oslo" || notifier.alerts[0].Value != -3 {
+		t.Fatalf("alerts = %+v, want Oslo at -3 °C", notifier.alerts)
+	}
+
+	if err := evaluator.Evaluate(ctx); err != nil {
+		t.Fatalf("Evaluate() unexpected error: %v", err)
+	}
+	if len(notifier.alerts) != 1 {
+		t.Errorf("got %d alerts after a second pass, want no repeat while Oslo stays cold", len(notifier.alerts))
+	}
+
+	weather.temperatures["London"] = -1
+	notifier.err = errors.NewExternalServiceError("webhook returned status: 503")
+	if err := evaluator.Evaluate(ctx); err == nil || !strings.Contains(err.Error(), "503") {
+		t.Errorf("Evaluate() error = %v, want the delivery failure", err)
+	}
+	if rule, _ := ruleRepo.GetByID(ctx, freezing.ID); rule.Triggered {
+		t.Errorf("rule triggered although its alert was not delivered")
+	}
+
+	notifier.err = nil
+	evaluator.Evaluate(ctx)
+	if len(notifier.alerts) != 2 {
+		t.Fatalf("got %d alerts, want the undelivered London alert sent on the next pass", len(notifier.alerts))
+	}
+	alert := notifier.alerts[1]
+	if alert.Rule.ID != freezing.ID || alert.Weather.Units != domain.UnitsImperial || alert.Value != alert.Weather.Temperature {
+		t.Errorf("alert = %+v, want the London rule with weather in its imperial units", alert)
+	}
+	if rule, _ := ruleRepo.GetByID(ctx, freezing.ID); !rule.Triggered || rule.TriggeredAt.IsZero() {
+		t.Errorf("rule = %+v, want it saved as triggered", rule)
+	}
+}
+
+func TestAlertEvaluator_LookupFailure(t *testing.T) {
+	service, ruleRepo := newAlertFixture(t)
+	weather := &thermometer{temperatures: map[string]float64{"Oslo": -3}}
+	notifier := &recordingNotifier{}
+	evaluator := application.NewAlertEvaluator(ruleRepo, weather, notifier, 0)
+
+	service.CreateAlertRule(asUser("user_2"), "user_2", domain.CityQuery("Atlantis"), domain.MetricTemperature, domain.ComparatorBelow, 0, "")
+	service.CreateAlertRule(asUser("user_2"), "user_2", domain.CityQuery("Oslo"), domain.MetricTemperature, domain.ComparatorBelow, 0, "")
+
+	if err := evaluator.Evaluate(context.Background()); err == nil || !strings.Contains(err.Error(), "city not found") {
+		t.Errorf("Evaluate() error = %v, want the failed lookup", err)
+	}
+	if len(notifier.alerts) != 1 {
+		t.Errorf("got %d alerts, want the Oslo rule checked despite the failed lookup", len(notifier.alerts))
+	}
+}
+
+// stalledNotifier holds back deliveries to one city until the context ends.
+type stalledNotifier struct {
+	recordingNotifier
+	city string
+}
+
+func (n *stalledNotifier) Notify(ctx context.Context, alert domain.Alert) error {
+	if alert.Rule.Query.City == n.city {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	return n.recordingNotifier.Notify(ctx, alert)
+}
+
+func TestAlertEvaluator_SlowDelivery(t *testing.T) {
+	service, ruleRepo := newAlertFixture(t)
+	weather := &thermometer{temperatures: map[string]float64{"London": -1, "Oslo": -3}}
+	notifier := &stalledNotifier{city: "London"}
+	evaluator := application.NewAlertEvaluator(ruleRepo, weather, notifier, 0)
+
+	service.CreateAlertRule(asUser("user_1"), "user_1", domain.CityQuery("London"), domain.MetricTemperature, domain.ComparatorBelow, 0, domain.UnitsMetric)
+	oslo, _ := service.CreateAlertRule(asUser("user_2"), "user_2", domain.CityQuery("Oslo"), domain.MetricTemperature, domain.ComparatorBelow, 0, "")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- evaluator.Evaluate(ctx) }()
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		if rule, _ := ruleRepo.GetByID(context.Background(), oslo.ID); rule.Triggered {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Oslo alert was held up by the stalled London delivery")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("Evaluate() error = %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,115 @@
+package application
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/leinonen/hexagonal-architecture-go/internal/domain"
+	"github.com/leinonen/hexagonal-architecture-go/internal/errors"
+	"github.com/leinonen/hexagonal-architecture-go/internal/ports"
+)
+
+// AlertService manages users' alert rules. The rules are checked by an
+// AlertEvaluator.
+type AlertService struct {
+	ruleRepo ports.AlertRuleRepository
+	userRepo ports.UserRepository
+}
+
+func NewAlertService(ruleRepo ports.AlertRuleRepository, userRepo ports.UserRepository) *AlertService {
+	return &AlertService{
+		ruleRepo: ruleRepo,
+		userRepo: userRepo,
+	}
+}
+
+// CreateAlertRule takes the threshold in the given units, falling back to the
+// user's preferred units and then metric.
+func (s *AlertService) CreateAlertRule(ctx context.Context, userID string, query domain.WeatherQuery, metric domain.AlertMetric, comparator domain.Comparator, threshold float64, units domain.Units) (*domain.AlertRule, error) {
+	if userID == "" {
+		return nil, errors.NewValidationError("user ID is required")
+	}
+	if err := authorizeUser(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if units == "" {
+		units = user.PreferredUnits
+	}
+
+	rule, err := domain.NewAlertRule(userID, query, metric, comparator, threshold, units)
+	if err != nil {
+		return nil, errors.NewValidationError(err.Error())
+	}
+
+	existing, err := s.ruleRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= domain.MaxAlertRulesPerUser {
+		return nil, errors.NewConflictError(fmt.Sprintf("at most %d alert rules may be created", domain.MaxAlertRulesPerUser))
+	}
+
+	if err := s.ruleRepo.Create(ctx, rule); err != nil {
+		return nil, err
+	}
+
+	return rule, nil
+}
+
+func (s *AlertService) GetAlertRule(ctx context.Context, userID, ruleID string) (*domain.AlertRule, error) {
+	if err := s.authorize(ctx, userID, ruleID); err != nil {
+		return nil, err
+	}
+	return s.ownedRule(ctx, userID, ruleID)
+}
+
+func (s *AlertService) ListAlertRules(ctx context.Context, userID string) ([]*domain.AlertRule, error) {
+	if userID == "" {
+		return nil, errors.NewValidationError("user ID is required")
+	}
+	if err := authorizeUser(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	return s.ruleRepo.ListByUser(ctx, userID)
+}
+
+func (s *AlertService) DeleteAlertRule(ctx context.Context, userID, ruleID string) error {
+	if err := s.authorize(ctx, userID, ruleID); err != nil {
+		return err
+	}
+
+	rule, err := s.ownedRule(ctx, userID, ruleID)
+	if err != nil {
+		return err
+	}
+
+	return s.ruleRepo.Delete(ctx, rule.ID)
+}
+
+func (s *AlertService) authorize(ctx context.Context, userID, ruleID string) error {
+	if userID == "" {
+		return errors.NewValidationError("user ID is required")
+	}
+	if ruleID == "" {
+		return errors.NewValidationError("alert rule ID is required")
+	}
+	return authorizeUser(ctx, userID)
+}
+
+// ownedRule reports another user's rule as not found, like ownedLocation.
+func (s *AlertService) ownedRule(ctx context.Context, userID, ruleID string) (*domain.AlertRule, error) {
+	rule, err := s.ruleRepo.GetByID(ctx, ruleID)
+	if err != nil {
+		return nil, err
+	}
+	if rule.UserID != userID {
+		return nil, errors.NewNotFoundError("alert rule not found")
+	}
+	return rule, nil
+}
@@ -0,0 +1,112 @@
+package application_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/leinonen/hexagonal-architecture-go/internal/domain"
+	"github.com/leinonen/hexagonal-architecture-go/internal/errors"
+)
+
+func TestAlertService_CreateAlertRule(t *testing.T) {
+	service, _ := newAlertFixture(t)
+	london := domain.CityQuery("London")
+
+	rule, err := service.CreateAlertRule(asUser("user_1"), "user_1", london, domain.MetricTemperature, domain.ComparatorBelow, 32, "")
+	if err != nil {
+		t.Fatalf("CreateAlertRule() unexpected error: %v", err)
+	}
+	if rule.ID == "" || rule.Units != domain.UnitsImperial {
+		t.Errorf("CreateAlertRule() = %+v, want a stored rule in the user's imperial units", rule)
+	}
+
+	rule, _ = service.CreateAlertRule(asUser("user_2"), "user_2", london, domain.MetricTemperature, domain.ComparatorBelow, 0, "")
+	if rule.Units != domain.UnitsMetric {
+		t.Errorf("CreateAlertRule() units = %v, want metric without a preference", rule.Units)
+	}
+
+	if _, err := service.CreateAlertRule(asUser("user_1"), "user_1", london, "snow_depth", domain.ComparatorAbove, 10, ""); !errors.IsValidation(err) {
+		t.Errorf("CreateAlertRule() with an unknown metric error = %v, want validation error", err)
+	}
+	if _, err := service.CreateAlertRule(asUser("user_2"), "user_1", london, domain.MetricTemperature, domain.ComparatorBelow, 0, ""); !errors.IsForbidden(err) {
+		t.Errorf("CreateAlertRule() for another user error = %v, want forbidden", err)
+	}
+
+	for i := 1; i < domain.MaxAlertRulesPerUser; i++ {
+		if _, err := service.CreateAlertRule(asUser("user_1"), "user_1", london, domain.MetricTemperature, domain.ComparatorBelow, float64(i), ""); err != nil {
+			t.Fatalf("CreateAlertRule() unexpected error: %v", err)
+		}
+	}
+	if _, err := service.CreateAlertRule(asUser("user_1"), "user_1", london, domain.MetricTemperature, domain.ComparatorBelow, 0, ""); !errors.IsConflict(err) {
+		t.Errorf("CreateAlertRule() over the limit error = %v, want conflict", err)
+	}
+}
+
+func TestAlertService_Ownership(t *testing.T) {
+	service, _ := newAlertFixture(t)
+
+	rule, _ := service.CreateAlertRule(asUser("user_1"), "user_1", domain.CityQuery("London"), domain.MetricTemperature, domain.ComparatorBelow, 32, "")
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		call    func(ctx context.Context) error
+		errType errors.ErrorType
+	}{
+		{
+			name: "get another user's rule",
+			ctx:  asUser("user_2"),
+			call: func(ctx context.Context) error {
+				_, err := service.GetAlertRule(ctx, "user_2", rule.ID)
+				return err
+			},
+			errType: errors.NotFound,
+		},
+		{
+			name: "delete another user's rule",
+			ctx:  asUser("user_2"),
+			call: func(ctx context.Context) error {
+				return service.DeleteAlertRule(ctx, "user_2", rule.ID)
+			},
+			errType: errors.NotFound,
+		},
+		{
+			name: "list another user's rules",
+			ctx:  asUser("user_2"),
+			call: func(ctx context.Context) error {
+				_, err := service.ListAlertRules(ctx, "user_1")
+				return err
+			},
+			errType: errors.Forbidden,
+		},
+		{
+			name: "admin deletes a user's rule",
+			ctx:  asAdmin(),
+			call: func(ctx context.Context) error {
+				return service.DeleteAlertRule(ctx, "user_1", rule.ID)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.call(tt.ctx)
+
+			if tt.errType == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			appErr, ok := err.(*errors.AppError)
+			if !ok || appErr.Type != tt.errType {
+				t.Errorf("error = %v, want %v", err, tt.errType)
+			}
+		})
+	}
+
+	if rules, _ := service.ListAlertRules(asUser("user_1"), "user_1"); len(rules) != 0 {
+		t.Errorf("ListAlertRules() after delete = %v, want none", rules)
+	}
+}
@@ -0,0 +1,490 @@
+package application
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/leinonen/hexagonal-architecture-go/internal/domain"
+	"github.com/leinonen/hexagonal-architecture-go/internal/errors"
+	"github.com/leinonen/hexagonal-architecture-go/internal/ports"
+)
+
+const (
+	apiKeyPrefix    = "hak_"
+	apiKeyPrefixLen = 12
+
+	accessTokenTTL = 15 * time.Minute
+)
+
+type AuthService struct {
+	apiKeyRepo     ports.APIKeyRepository
+	sessionRepo    ports.SessionRepository
+	userRepo       ports.UserRepository
+	tokenVerifier  ports.TokenVerifier
+	passwordHasher ports.PasswordHasher
+	tokenIssuer    ports.TokenIssuer
+
+	// dummyHash is verified against when the email is unknown so that failed
+	// logins take the same time whether or not the account exists.
+	dummyHashOnce sync.Once
+	dummyHash     string
+
+	// refreshMu serialises refresh token rotation so two concurrent requests
+	// presenting the same token cannot both succeed.
+	refreshMu sync.Mutex
+}
+
+// NewAuthService wires API key, JWT and password authentication.
+// tokenVerifier may be nil, in which case bearer JWTs are rejected; without a
+// passwordHasher and tokenIssuer, login is disabled.
+func NewAuthService(apiKeyRepo ports.APIKeyRepository, sessionRepo ports.SessionRepository, userRepo ports.UserRepository, tokenVerifier ports.TokenVerifier, passwordHasher ports.PasswordHasher, tokenIssuer ports.TokenIssuer) *AuthService {
+	return &AuthService{
+		apiKeyRepo:     apiKeyRepo,
+		sessionRepo:    sessionRepo,
+		userRepo:       userRepo,
+		tokenVerifier:  tokenVerifier,
+		passwordHasher: passwordHasher,
+		tokenIssuer:    tokenIssuer,
+	}
+}
+
+// Login checks an email and password, opens a session and issues a
+// short-lived access token together with the session's refresh token.
+// Consecutive failures lock the account for domain.LockoutDuration.
+func (s *AuthService) Login(ctx context.Context, email, password string, client domain.ClientInfo) (*domain.AuthTokens, error) {
+	if email == "" || password == "" {
+		return nil, errors.NewValidationError("email and password are required")
+	}
+	if s.passwordHasher == nil || s.tokenIssuer == nil {
+		return nil, errors.NewInternalError("password login is not configured")
+	}
+
+	invalid := errors.NewUnauthorizedError("invalid email or password")
+
+	user, err := s.userRepo.GetByEmail(ctx, email)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			s.burnPasswordCheck(password)
+			return nil, invalid
+		}
+		return nil, err
+	}
+
+	// A locked account answers like a wrong password, so that lockouts do
+	// not reveal which emails are registered.
+	now := time.Now()
+	if user.IsLocked(now) || !user.HasPassword() {
+		s.burnPasswordCheck(password)
+		return nil, invalid
+	}
+
+	ok, err := s.passwordHasher.Verify(user.PasswordHash, password)
+	if err != nil {
+		return nil, errors.NewInternalError(fmt.Sprintf("failed to verify password: %v", err))
+	}
+
+	if !ok {
+		if err := s.userRepo.RecordFailedLogin(ctx, user.ID, now); err != nil {
+			return nil, err
+		}
+		return nil, invalid
+	}
+
+	if user.FailedLogins > 0 || !user.LockedUntil.IsZero() {
+		if err := s.userRepo.ResetFailedLogins(ctx, user.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	refreshSecret, err := generateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := domain.NewSession(user.ID, hashToken(refreshSecret), client)
+	if err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	if err := s.sessionRepo.Create(ctx, session); err != nil {
+		return nil, err
+	}
+
+	return s.issueTokens(ctx, user, session, refreshSecret)
+}
+
+// Refresh exchanges a refresh token for a new access token and a new refresh
+// token. Presenting a refresh token that was already rotated away means it
+// leaked, so the whole session is revoked.
+func (s *AuthService) Refresh(ctx context.Context, refreshToken string) (*domain.AuthTokens, error) {
+	if s.tokenIssuer == nil {
+		return nil, errors.NewInternalError("token issuing is not configured")
+	}
+
+	s.refreshMu.Lock()
+	defer s.refreshMu.Unlock()
+
+	session, hash, err := s.lookupSession(ctx, refreshToken)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if session.WasRotatedFrom(hash) {
+		session.Revoke(now)
+		if err := s.sessionRepo.Update(ctx, session); err != nil {
+			return nil, err
+		}
+		return nil, errors.NewUnauthorizedError("refresh token reuse detected, session revoked")
+	}
+	if session.RefreshTokenHash != hash || !session.IsActive(now) {
+		return nil, errors.NewUnauthorizedError("invalid refresh token")
+	}
+
+	user, err := s.userRepo.GetByID(ctx, session.UserID)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, errors.NewUnauthorizedError("invalid refresh token")
+		}
+		return nil, err
+	}
+
+	newSecret, err := generateSecret()
+	if err != nil {
+		return nil, err
+	}
+	session.Rotate(hashToken(newSecret), now)
+	if err := s.sessionRepo.Update(ctx, session); err != nil {
+		return nil, err
+	}
+
+	return s.issueTokens(ctx, user, session, newSecret)
+}
+
+// Logout revokes the session the refresh token belongs to.
+func (s *AuthService) Logout(ctx context.Context, refreshToken string) error {
+	s.refreshMu.Lock()
+	defer s.refreshMu.Unlock()
+
+	session, hash, err := s.lookupSession(ctx, refreshToken)
+	if err != nil {
+		return err
+	}
+	if session.RefreshTokenHash != hash && !session.WasRotatedFrom(hash) {
+		return errors.NewUnauthorizedError("invalid refresh token")
+	}
+
+	session.Revoke(time.Now())
+	return s.sessionRepo.Update(ctx, session)
+}
+
+func (s *AuthService) ListSessions(ctx context.Context, userID string) ([]*domain.Session, error) {
+	if userID == "" {
+		return nil, errors.NewValidationError("user ID is required")
+	}
+	if err := authorizeUser(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	sessions, err := s.sessionRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	active := make([]*domain.Session, 0, len(sessions))
+	for _, session := range sessions {
+		if session.IsActive(now) {
+			active = append(active, session)
+		}
+	}
+	return active, nil
+}
+
+func (s *AuthService) RevokeSession(ctx context.Context, userID, sessionID string) error {
+	if userID == "" {
+		return errors.NewValidationError("user ID is required")
+	}
+	if sessionID == "" {
+		return errors.NewValidationError("session ID is required")
+	}
+	if err := authorizeUser(ctx, userID); err != nil {
+		return err
+	}
+
+	session, err := s.sessionRepo.GetByID(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	if session.UserID != userID {
+		return errors.NewNotFoundError("session not found")
+	}
+
+	session.Revoke(time.Now())
+	return s.sessionRepo.Update(ctx, session)
+}
+
+func (s *AuthService) lookupSession(ctx context.Context, refreshToken string) (*domain.Session, string, error) {
+	sessionID, secret, found := strings.Cut(refreshToken, ".")
+	if !found || sessionID == "" || secret == "" {
+		return nil, "", errors.NewUnauthorizedError("invalid refresh token")
+	}
+
+	session, err := s.sessionRepo.GetByID(ctx, sessionID)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, "", errors.NewUnauthorizedError("invalid refresh token")
+		}
+		return nil, "", err
+	}
+
+	return session, hashToken(secret), nil
+}
+
+func (s *AuthService) issueTokens(ctx context.Context, user *domain.User, session *domain.Session, refreshSecret string) (*domain.AuthTokens, error) {
+	principal := &domain.Principal{
+		UserID: user.ID,
+		Role:   user.Role,
+	}
+
+	token, expiresAt, err := s.tokenIssuer.Issue(ctx, principal, accessTokenTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.AuthTokens{
+		AccessToken:      token,
+		ExpiresAt:        expiresAt,
+		RefreshToken:     session.ID + "." + refreshSecret,
+		RefreshExpiresAt: session.ExpiresAt,
+		SessionID:        session.ID,
+	}, nil
+}
+
+func (s *AuthService) burnPasswordCheck(password string) {
+	s.dummyHashOnce.Do(func() {
+		s.dummyHash, _ = s.passwordHasher.Hash("not-a-real-password-0")
+	})
+	if s.dummyHash != "" {
+		s.passwordHasher.Verify(s.dummyHash, password)
+	}
+}
+
+// Authenticate resolves a bearer credential, dispatching on its shape: compact
+// JWTs carry two dots, API keys never do.
+func (s *AuthService) Authenticate(ctx context.Context, credential string) (*domain.Principal, error) {
+	if strings.Count(credential, ".") == 2 {
+		if s.tokenVerifier == nil {
+			return nil, errors.NewUnauthorizedError("token authentication is not enabled")
+		}
+		return s.authenticateToken(ctx, credential)
+	}
+
+	return s.AuthenticateAPIKey(ctx, credential)
+}
+
+// authenticateToken verifies a JWT and reloads its subject, so that users who
+// were deleted or demoted since the token was issued lose its access at once.
+// The role is always taken from the stored user, never from the token.
+func (s *AuthService) authenticateToken(ctx context.Context, token string) (*domain.Principal, error) {
+	principal, err := s.tokenVerifier.Verify(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByID(ctx, principal.UserID)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, errors.NewUnauthorizedError("invalid token")
+		}
+		return nil, err
+	}
+
+	return &domain.Principal{
+		UserID: user.ID,
+		Role:   user.Role,
+		Method: domain.AuthMethodJWT,
+	}, nil
+}
+
+// Register creates an account together with its first API key, returning the
+// plaintext key. If the key cannot be issued the account is removed again, so
+// that the email can be registered once more.
+func (s *AuthService) Register(ctx context.Context, email, name, password string) (*domain.User, string, error) {
+	user, err := createUser(ctx, s.userRepo, s.passwordHasher, email, name, password)
+	if err != nil {
+		return nil, "", err
+	}
+
+	_, secret, err := s.issueAPIKey(ctx, user.ID, "default")
+	if err != nil {
+		if deleteErr := s.userRepo.Delete(ctx, user.ID); deleteErr != nil {
+			log.Printf("Failed to remove user %s after their API key could not be issued: %v", user.ID, deleteErr)
+		}
+		return nil, "", err
+	}
+
+	return user, secret, nil
+}
+
+// IssueAPIKey creates a new key for the user and returns it together with the
+// plaintext secret, which is never stored and cannot be recovered later.
+func (s *AuthService) IssueAPIKey(ctx context.Context, userID, name string) (*domain.APIKey, string, error) {
+	if userID == "" {
+		return nil, "", errors.NewValidationError("user ID is required")
+	}
+	if err := authorizeUser(ctx, userID); err != nil {
+		return nil, "", err
+	}
+
+	return s.issueAPIKey(ctx, userID, name)
+}
+
+// RegisterAPIKey stores an operator-supplied key, used to bootstrap the first
+// admin account from configuration.
+func (s *AuthService) RegisterAPIKey(ctx context.Context, userID, name, secret string) (*domain.APIKey, error) {
+	if !strings.HasPrefix(secret, apiKeyPrefix) || len(secret) < apiKeyPrefixLen+20 {
+		return nil, errors.NewValidationError("api key must start with " + apiKeyPrefix + " and be at least 32 characters")
+	}
+
+	if _, err := s.userRepo.GetByID(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	key, err := domain.NewAPIKey(userID, name, secret[:apiKeyPrefixLen], hashToken(secret))
+	if err != nil {
+		return nil, errors.NewValidationError(err.Error())
+	}
+
+	if existing, err := s.apiKeyRepo.GetByHash(ctx, key.Hash); err == nil {
+		if existing.UserID != userID {
+			return nil, errors.NewConflictError("api key already belongs to another user")
+		}
+		return existing, nil
+	}
+
+	if err := s.apiKeyRepo.Create(ctx, key); err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+func (s *AuthService) issueAPIKey(ctx context.Context, userID, name string) (*domain.APIKey, string, error) {
+	if _, err := s.userRepo.GetByID(ctx, userID); err != nil {
+		return nil, "", err
+	}
+
+	secret, err := generateAPIKey()
+	if err != nil {
+		return nil, "", err
+	}
+
+	key, err := domain.NewAPIKey(userID, name, secret[:apiKeyPrefixLen], hashToken(secret))
+	if err != nil {
+		return nil, "", errors.NewValidationError(err.Error())
+	}
+
+	if err := s.apiKeyRepo.Create(ctx, key); err != nil {
+		return nil, "", err
+	}
+
+	return key, secret, nil
+}
+
+func (s *AuthService) ListAPIKeys(ctx context.Context, userID string) ([]*domain.APIKey, error) {
+	if userID == "" {
+		return nil, errors.NewValidationError("user ID is required")
+	}
+	if err := authorizeUser(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	return s.apiKeyRepo.ListByUser(ctx, userID)
+}
+
+func (s *AuthService) RevokeAPIKey(ctx context.Context, userID, keyID string) error {
+	if userID == "" {
+		return errors.NewValidationError("user ID is required")
+	}
+	if keyID == "" {
+		return errors.NewValidationError("key ID is required")
+	}
+	if err := authorizeUser(ctx, userID); err != nil {
+		return err
+	}
+
+	keys, err := s.apiKeyRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if key.ID == keyID {
+			return s.apiKeyRepo.Delete(ctx, keyID)
+		}
+	}
+
+	return errors.NewNotFoundError("api key not found")
+}
+
+func (s *AuthService) AuthenticateAPIKey(ctx context.Context, secret string) (*domain.Principal, error) {
+	if !strings.HasPrefix(secret, apiKeyPrefix) {
+		return nil, errors.NewUnauthorizedError("invalid api key")
+	}
+
+	key, err := s.apiKeyRepo.GetByHash(ctx, hashToken(secret))
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, errors.NewUnauthorizedError("invalid api key")
+		}
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByID(ctx, key.UserID)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, errors.NewUnauthorizedError("invalid api key")
+		}
+		return nil, err
+	}
+
+	used := *key
+	used.LastUsedAt = time.Now()
+	if err := s.apiKeyRepo.Update(ctx, &used); err != nil {
+		return nil, err
+	}
+
+	return &domain.Principal{
+		UserID: user.ID,
+		Role:   user.Role,
+		Method: domain.AuthMethodAPIKey,
+	}, nil
+}
+
+func generateAPIKey() (string, error) {
+	secret, err := generateSecret()
+	if err != nil {
+		return "", err
+	}
+	return apiKeyPrefix + secret, nil
+}
+
+func generateSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", errors.NewInternalError(fmt.Sprintf("failed to generate secret: %v", err))
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func hashToken(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,506 @@
+package application_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/leinonen/hexagonal-architecture-go/internal/adapters/repository/memory"
+	"github.com/leinonen/hexagonal-architecture-go/internal/application"
+	"github.com/leinonen/hexagonal-architecture-go/internal/domain"
+	"github.com/leinonen/hexagonal-architecture-go/internal/errors"
+	"github.com/leinonen/hexagonal-architecture-go/internal/ports"
+)
+
+// failingAPIKeyRepository fails to store keys while err is set.
+type failingAPIKeyRepository struct {
+	ports.APIKeyRepository
+	err error
+}
+
+func (r *failingAPIKeyRepository) Create(ctx context.Context, key *domain.APIKey) error {
+	if r.err != nil {
+		return r.err
+	}
+	return r.APIKeyRepository.Create(ctx, key)
+}
+
+func TestAuthService_Register(t *testing.T) {
+	userRepo := newUserRepository(t)
+	keyRepo := &failingAPIKeyRepository{APIKeyRepository: memory.NewAPIKeyRepository()}
+	service := application.NewAuthService(keyRepo, memory.NewSessionRepository(), userRepo, nil, nil, nil)
+	ctx := context.Background()
+
+	user, secret, err := service.Register(ctx, "one@example.com", "One", "")
+	if err != nil {
+		t.Fatalf("Register() unexpected error: %v", err)
+	}
+	if principal, err := service.AuthenticateAPIKey(ctx, secret); err != nil || principal.UserID != user.ID {
+		t.Errorf("AuthenticateAPIKey() with the registered key = %v, %v, want %s", principal, err, user.ID)
+	}
+
+	if _, _, err := service.Register(ctx, "one@example.com", "One", ""); !errors.IsConflict(err) {
+		t.Errorf("Register() with a taken email error = %v, want conflict", err)
+	}
+
+	keyRepo.err = errors.NewInternalError("disk full")
+	if _, _, err := service.Register(ctx, "two@example.com", "Two", ""); !errors.IsInternal(err) {
+		t.Fatalf("Register() error = %v, want the key failure", err)
+	}
+	if _, err := userRepo.GetByEmail(ctx, "two@example.com"); !errors.IsNotFound(err) {
+		t.Errorf("Register() kept a user without credentials, lookup error = %v", err)
+	}
+
+	keyRepo.err = nil
+	if _, _, err := service.Register(ctx, "two@example.com", "Two", ""); err != nil {
+		t.Errorf("Register() again unexpected error: %v", err)
+	}
+}
+
+func TestAuthService_AuthenticateAPIKey(t *testing.T) {
+	service, _ := newAuthFixture(t)
+	ctx := context.Background()
+
+	_, secret, err := service.IssueAPIKey(asUser("user_1"), "user_1", "default")
+	if err != nil {
+		t.Fatalf("IssueAPIKey() unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		secret     string
+		wantUserID string
+		wantErr    bool
+	}{
+		{
+			name:       "valid key",
+			secret:     secret,
+			wantUserID: "user_1",
+		},
+		{
+			name:    "unknown key",
+			secret:  "hak_unknown",
+			wantErr: true,
+		},
+		{
+			name:    "wrong prefix",
+			secret:  "not-a-key",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			principal, err := service.AuthenticateAPIKey(ctx, tt.secret)
+
+			if tt.wantErr {
+				if !errors.IsUnauthorized(err) {
+					t.Errorf("AuthenticateAPIKey() error = %v, want unauthorized", err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("AuthenticateAPIKey() unexpected error: %v", err)
+			}
+
+			if principal.UserID != tt.wantUserID {
+				t.Errorf("AuthenticateAPIKey() user = %v, want %v", principal.UserID, tt.wantUserID)
+			}
+
+			if principal.Role != domain.RoleUser {
+				t.Errorf("AuthenticateAPIKey() role = %v, want %v", principal.Role, domain.RoleUser)
+			}
+
+			if principal.Method != domain.AuthMethodAPIKey {
+				t.Errorf("AuthenticateAPIKey() method = %v, want %v", principal.Method, domain.AuthMethodAPIKey)
+			}
+		})
+	}
+}
+
+func TestAuthService_IssueAPIKey(t *testing.T) {
+	service, keyRepo := newAuthFixture(t)
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		userID  string
+		keyName string
+		errType errors.ErrorType
+	}{
+		{
+			name:    "own key",
+			ctx:     asUser("user_1"),
+			userID:  "user_1",
+			keyName: "laptop",
+		},
+		{
+			name:    "unauthenticated",
+			ctx:     context.Background(),
+			userID:  "user_1",
+			keyName: "laptop",
+			errType: errors.Unauthorized,
+		},
+		{
+			name:    "another user",
+			ctx:     asUser("user_2"),
+			userID:  "user_1",
+			keyName: "laptop",
+			errType: errors.Forbidden,
+		},
+		{
+			name:    "admin for another user",
+			ctx:     asAdmin(),
+			userID:  "user_1",
+			keyName: "support",
+		},
+		{
+			name:    "missing name",
+			ctx:     asUser("user_1"),
+			userID:  "user_1",
+			keyName: "",
+			errType: errors.Validation,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, secret, err := service.IssueAPIKey(tt.ctx, tt.userID, tt.keyName)
+
+			if tt.errType != "" {
+				appErr, ok := err.(*errors.AppError)
+				if !ok || appErr.Type != tt.errType {
+					t.Errorf("IssueAPIKey() error = %v, want %v", err, tt.errType)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("IssueAPIKey() unexpected error: %v", err)
+			}
+
+			if key.UserID != tt.userID || secret == "" {
+				t.Errorf("IssueAPIKey() returned key for %v, want %v", key.UserID, tt.userID)
+			}
+
+			if _, err := keyRepo.GetByHash(context.Background(), secret); key.Hash == "" || key.Hash == secret || err == nil {
+				t.Errorf("IssueAPIKey() stored the plaintext key")
+			}
+		})
+	}
+}
+
+func TestAuthService_RevokeAPIKey(t *testing.T) {
+	service, _ := newAuthFixture(t)
+	ctx := asUser("user_1")
+
+	key, secret, err := service.IssueAPIKey(ctx, "user_1", "laptop")
+	if err != nil {
+		t.Fatalf("IssueAPIKey() unexpected error: %v", err)
+	}
+
+	if err := service.RevokeAPIKey(asUser("user_2"), "user_2", key.ID); !errors.IsNotFound(err) {
+		t.Errorf("RevokeAPIKey() foreign key error = %v, want not found", err)
+	}
+
+	if err := service.RevokeAPIKey(ctx, "user_1", key.ID); err != nil {
+		t.Fatalf("RevokeAPIKey() unexpected error: %v", err)
+	}
+
+	if _, err := service.AuthenticateAPIKey(context.Background(), secret); !errors.IsUnauthorized(err) {
+		t.Errorf("AuthenticateAPIKey() revoked key error = %v, want unauthorized", err)
+	}
+}
+
+func TestAuthService_Authenticate(t *testing.T) {
+	service, _ := newAuthFixture(t)
+	ctx := context.Background()
+
+	_, secret, _ := service.IssueAPIKey(asUser("user_1"), "user_1", "default")
+
+	principal, err := service.Authenticate(ctx, secret)
+	if err != nil {
+		t.Fatalf("Authenticate() api key unexpected error: %v", err)
+	}
+	if principal.Method != domain.AuthMethodAPIKey {
+		t.Errorf("Authenticate() method = %v, want %v", principal.Method, domain.AuthMethodAPIKey)
+	}
+
+	if _, err := service.Authenticate(ctx, "header.payload.signature"); !errors.IsUnauthorized(err) {
+		t.Errorf("Authenticate() jwt without verifier error = %v, want unauthorized", err)
+	}
+}
+
+// mockTokenVerifier accepts tokens of the form "subject.role.signature".
+type mockTokenVerifier struct{}
+
+func (mockTokenVerifier) Verify(ctx context.Context, token string) (*domain.Principal, error) {
+	subject, rest, _ := strings.Cut(token, ".")
+	role, _, _ := strings.Cut(rest, ".")
+	return &domain.Principal{UserID: subject, Role: domain.Role(role), Method: domain.AuthMethodJWT}, nil
+}
+
+func TestAuthService_AuthenticateToken(t *testing.T) {
+	userRepo := newUserRepository(t, testUsers()...)
+	service := application.NewAuthService(memory.NewAPIKeyRepository(), memory.NewSessionRepository(), userRepo, mockTokenVerifier{}, nil, nil)
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		token    string
+		wantRole domain.Role
+		errType  errors.ErrorType
+	}{
+		{name: "role from the stored user", token: "user_1.user.signature", wantRole: domain.RoleUser},
+		{name: "admin claim of a regular user", token: "user_1.admin.signature", wantRole: domain.RoleUser},
+		{name: "deleted user", token: "user_9.admin.signature", errType: errors.Unauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			principal, err := service.Authenticate(ctx, tt.token)
+
+			if tt.errType != "" {
+				appErr, ok := err.(*errors.AppError)
+				if !ok || appErr.Type != tt.errType {
+					t.Errorf("Authenticate() error = %v, want %v", err, tt.errType)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Authenticate() unexpected error: %v", err)
+			}
+			if principal.Role != tt.wantRole || principal.Method != domain.AuthMethodJWT {
+				t.Errorf("Authenticate() = %+v, want role %v by jwt", principal, tt.wantRole)
+			}
+		})
+	}
+
+	user, _ := userRepo.GetByID(ctx, "user_2")
+	promoted := *user
+	promoted.Role = domain.RoleAdmin
+	userRepo.Update(ctx, &promoted)
+	if principal, err := service.Authenticate(ctx, "user_2.user.signature"); err != nil || !principal.IsAdmin() {
+		t.Errorf("Authenticate() after a role change = %+v, %v, want admin", principal, err)
+	}
+}
+
+func TestAuthService_RegisterAPIKey(t *testing.T) {
+	service, _ := newAuthFixture(t)
+	ctx := context.Background()
+
+	if _, err := service.RegisterAPIKey(ctx, "user_1", "bootstrap", "short"); !errors.IsValidation(err) {
+		t.Errorf("RegisterAPIKey() short key error = %v, want validation", err)
+	}
+
+	secret := "hak_bootstrap-secret-for-tests-0123456789"
+	if _, err := service.RegisterAPIKey(ctx, "user_1", "bootstrap", secret); err != nil {
+		t.Fatalf("RegisterAPIKey() unexpected error: %v", err)
+	}
+
+	if _, err := service.RegisterAPIKey(ctx, "user_1", "bootstrap", secret); err != nil {
+		t.Errorf("RegisterAPIKey() should be idempotent, got %v", err)
+	}
+
+	if _, err := service.RegisterAPIKey(ctx, "user_2", "bootstrap", secret); !errors.IsConflict(err) {
+		t.Errorf("RegisterAPIKey() reused key error = %v, want conflict", err)
+	}
+
+	principal, err := service.AuthenticateAPIKey(ctx, secret)
+	if err != nil || principal.UserID != "user_1" {
+		t.Errorf("AuthenticateAPIKey() registered key = %v, %v", principal, err)
+	}
+}
+
+type mockTokenIssuer struct {
+	issued []*domain.Principal
+}
+
+func (m *mockTokenIssuer) Issue(ctx context.Context, principal *domain.Principal, ttl time.Duration) (string, time.Time, error) {
+	m.issued = append(m.issued, principal)
+	return "token-for-" + principal.UserID, time.Now().Add(ttl), nil
+}
+
+func TestAuthService_Login(t *testing.T) {
+	users := testUsers()
+	users[0].PasswordHash = "hashed:correcthorse42"
+	userRepo := newUserRepository(t, users...)
+
+	issuer := &mockTokenIssuer{}
+	service := application.NewAuthService(memory.NewAPIKeyRepository(), memory.NewSessionRepository(), userRepo, nil, mockPasswordHasher{}, issuer)
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		email    string
+		password string
+		errType  errors.ErrorType
+	}{
+		{name: "valid credentials", email: "one@example.com", password: "correcthorse42"},
+		{name: "wrong password", email: "one@example.com", password: "wrong", errType: errors.Unauthorized},
+		{name: "unknown email", email: "ghost@example.com", password: "correcthorse42", errType: errors.Unauthorized},
+		{name: "account without password", email: "two@example.com", password: "anything", errType: errors.Unauthorized},
+		{name: "missing password", email: "one@example.com", password: "", errType: errors.Validation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, err := service.Login(ctx, tt.email, tt.password, domain.ClientInfo{})
+
+			if tt.errType != "" {
+				appErr, ok := err.(*errors.AppError)
+				if !ok || appErr.Type != tt.errType {
+					t.Errorf("Login() error = %v, want %v", err, tt.errType)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Login() unexpected error: %v", err)
+			}
+
+			if tokens.AccessToken != "token-for-user_1" {
+				t.Errorf("Login() access token = %v, want token-for-user_1", tokens.AccessToken)
+			}
+		})
+	}
+}
+
+func TestAuthService_LoginLockout(t *testing.T) {
+	users := testUsers()
+	users[0].PasswordHash = "hashed:correcthorse42"
+	userRepo := newUserRepository(t, users...)
+
+	service := application.NewAuthService(memory.NewAPIKeyRepository(), memory.NewSessionRepository(), userRepo, nil, mockPasswordHasher{}, &mockTokenIssuer{})
+	ctx := context.Background()
+
+	for i := 0; i < domain.MaxFailedLogins; i++ {
+		service.Login(ctx, "one@example.com", "wrong", domain.ClientInfo{})
+	}
+
+	_, err := service.Login(ctx, "one@example.com", "correcthorse42", domain.ClientInfo{})
+	if !errors.IsUnauthorized(err) {
+		t.Fatalf("Login() on locked account error = %v, want unauthorized", err)
+	}
+
+	_, unknown := service.Login(ctx, "ghost@example.com", "correcthorse42", domain.ClientInfo{})
+	if err.Error() != unknown.Error() {
+		t.Errorf("Login() on locked account error = %q, want the unknown email error %q", err, unknown)
+	}
+
+	if user, _ := userRepo.GetByID(ctx, "user_1"); !user.IsLocked(time.Now()) {
+		t.Errorf("Login() did not persist the lockout")
+	}
+}
+
+func TestAuthService_Refresh(t *testing.T) {
+	service, sessionRepo, tokens := newSessionFixture(t)
+	ctx := context.Background()
+
+	if tokens.RefreshToken == "" || tokens.SessionID == "" {
+		t.Fatalf("Login() did not open a session")
+	}
+	if session, _ := sessionRepo.GetByID(ctx, tokens.SessionID); session.RefreshTokenHash == tokens.RefreshToken {
+		t.Errorf("Login() stored the plaintext refresh token")
+	}
+
+	refreshed, err := service.Refresh(ctx, tokens.RefreshToken)
+	if err != nil {
+		t.Fatalf("Refresh() unexpected error: %v", err)
+	}
+	if refreshed.RefreshToken == tokens.RefreshToken {
+		t.Errorf("Refresh() did not rotate the refresh token")
+	}
+	if refreshed.SessionID != tokens.SessionID {
+		t.Errorf("Refresh() session = %v, want %v", refreshed.SessionID, tokens.SessionID)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "malformed", token: "garbage"},
+		{name: "unknown session", token: "session_99.secret"},
+		{name: "wrong secret", token: tokens.SessionID + ".secret"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.Refresh(ctx, tt.token)
+			if !errors.IsUnauthorized(err) {
+				t.Errorf("Refresh() error = %v, want unauthorized", err)
+			}
+		})
+	}
+}
+
+func TestAuthService_RefreshReuseRevokesSession(t *testing.T) {
+	service, sessionRepo, tokens := newSessionFixture(t)
+	ctx := context.Background()
+
+	refreshed, err := service.Refresh(ctx, tokens.RefreshToken)
+	if err != nil {
+		t.Fatalf("Refresh() unexpected error: %v", err)
+	}
+
+	if _, err := service.Refresh(ctx, tokens.RefreshToken); !errors.IsUnauthorized(err) {
+		t.Fatalf("Refresh() with rotated token error = %v, want unauthorized", err)
+	}
+
+	if session, _ := sessionRepo.GetByID(ctx, tokens.SessionID); session.IsActive(time.Now()) {
+		t.Errorf("Refresh() reuse did not revoke the session")
+	}
+
+	if _, err := service.Refresh(ctx, refreshed.RefreshToken); !errors.IsUnauthorized(err) {
+		t.Errorf("Refresh() after reuse error = %v, want unauthorized", err)
+	}
+}
+
+func TestAuthService_Logout(t *testing.T) {
+	service, _, tokens := newSessionFixture(t)
+	ctx := context.Background()
+
+	if err := service.Logout(ctx, tokens.RefreshToken); err != nil {
+		t.Fatalf("Logout() unexpected error: %v", err)
+	}
+
+	if _, err := service.Refresh(ctx, tokens.RefreshToken); !errors.IsUnauthorized(err) {
+		t.Errorf("Refresh() after logout error = %v, want unauthorized", err)
+	}
+}
+
+func TestAuthService_Sessions(t *testing.T) {
+	service, _, tokens := newSessionFixture(t)
+
+	sessions, err := service.ListSessions(asUser("user_1"), "user_1")
+	if err != nil {
+		t.Fatalf("ListSessions() unexpected error: %v", err)
+	}
+	if len(sessions) != 1 || sessions[0].Client.UserAgent != "test" {
+		t.Fatalf("ListSessions() = %v, want the login session", sessions)
+	}
+
+	if _, err := service.ListSessions(asUser("user_2"), "user_1"); !errors.IsForbidden(err) {
+		t.Errorf("ListSessions() for another user error = %v, want forbidden", err)
+	}
+
+	if err := service.RevokeSession(asUser("user_2"), "user_2", tokens.SessionID); !errors.IsNotFound(err) {
+		t.Errorf("RevokeSession() of another user's session error = %v, want not found", err)
+	}
+
+	if err := service.RevokeSession(asUser("user_1"), "user_1", tokens.SessionID); err != nil {
+		t.Fatalf("RevokeSession() unexpected error: %v", err)
+	}
+
+	sessions, _ = service.ListSessions(asUser("user_1"), "user_1")
+	if len(sessions) != 0 {
+		t.Errorf("ListSessions() after revoke = %d sessions, want 0", len(sessions))
+	}
+
+	if _, err := service.Refresh(context.Background(), tokens.RefreshToken); !errors.IsUnauthorized(err) {
+		t.Errorf("Refresh() after revoke error = %v, want unauthorized", err)
+	}
+}
@@ -0,0 +1,31 @@
+package application
+
+import (
+	"context"
+
+	"github.com/leinonen/hexagonal-architecture-go/internal/errors"
+)
+
+// authorizeUser lets callers act on their own account, while admins may act
+// on any account.
+func authorizeUser(ctx context.Context, userID string) error {
+	principal, err := requirePrincipal(ctx)
+	if err != nil {
+		return err
+	}
+	if principal.IsAdmin() || principal.UserID == userID {
+		return nil
+	}
+	return errors.NewForbiddenError("not allowed to access this user")
+}
+
+func requireAdmin(ctx context.Context) error {
+	principal, err := requirePrincipal(ctx)
+	if err != nil {
+		return err
+	}
+	if !principal.IsAdmin() {
+		return errors.NewForbiddenError("admin role required")
+	}
+	return nil
+}
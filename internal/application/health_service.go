@@ -0,0 +1,39 @@
+package application
+
+import (
+	"context"
+
+	"github.com/leinonen/hexagonal-architecture-go/internal/domain"
+	"github.com/leinonen/hexagonal-architecture-go/internal/ports"
+)
+
+type HealthService struct {
+	checkers []ports.HealthChecker
+}
+
+func NewHealthService(checkers ...ports.HealthChecker) *HealthService {
+	return &HealthService{
+		checkers: checkers,
+	}
+}
+
+// Check reports every component and an overall status, which is the worst
+// status of any component.
+func (s *HealthService) Check(ctx context.Context) (domain.HealthStatus, []domain.ComponentHealth) {
+	overall := domain.HealthUp
+	components := make([]domain.ComponentHealth, 0, len(s.checkers))
+
+	for _, checker := range s.checkers {
+		component := checker.CheckHealth(ctx)
+		components = append(components, component)
+
+		switch {
+		case component.Status == domain.HealthDown:
+			overall = domain.HealthDown
+		case component.Status == domain.HealthDegraded && overall == domain.HealthUp:
+			overall = domain.HealthDegraded
+		}
+	}
+
+	return overall, components
+}
@@ -0,0 +1,47 @@
+package application_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/leinonen/hexagonal-architecture-go/internal/application"
+	"github.com/leinonen/hexagonal-architecture-go/internal/domain"
+	"github.com/leinonen/hexagonal-architecture-go/internal/ports"
+)
+
+type stubHealthChecker domain.HealthStatus
+
+func (c stubHealthChecker) CheckHealth(ctx context.Context) domain.ComponentHealth {
+	return domain.ComponentHealth{Name: "stub", Status: domain.HealthStatus(c)}
+}
+
+func TestHealthService_Check(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []domain.HealthStatus
+		want     domain.HealthStatus
+	}{
+		{name: "no components", want: domain.HealthUp},
+		{name: "all up", statuses: []domain.HealthStatus{domain.HealthUp, domain.HealthUp}, want: domain.HealthUp},
+		{name: "one degraded", statuses: []domain.HealthStatus{domain.HealthUp, domain.HealthDegraded}, want: domain.HealthDegraded},
+		{name: "down wins", statuses: []domain.HealthStatus{domain.HealthDown, domain.HealthDegraded}, want: domain.HealthDown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkers := make([]ports.HealthChecker, len(tt.statuses))
+			for i, status := range tt.statuses {
+				checkers[i] = stubHealthChecker(status)
+			}
+
+			status, components := application.NewHealthService(checkers...).Check(context.Background())
+
+			if status != tt.want {
+				t.Errorf("Check() status = %v, want %v", status, tt.want)
+			}
+			if len(components) != len(tt.statuses) {
+				t.Errorf("Check() returned %d components, want %d", len(components), len(tt.statuses))
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package application_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/leinonen/hexagonal-architecture-go/internal/adapters/repository/memory"
+	"github.com/leinonen/hexagonal-architecture-go/internal/application"
+	"github.com/leinonen/hexagonal-architecture-go/internal/domain"
+	"github.com/leinonen/hexagonal-architecture-go/internal/ports"
+)
+
+// Services are tested against the memory repositories. Hand-written doubles
+// stand in only for ports without an in-memory adapter, such as the password
+// hasher and weather providers, or to make a repository fail.
+
+func asUser(userID string) context.Context {
+	return application.WithPrincipal(context.Background(), &domain.Principal{UserID: userID, Role: domain.RoleUser})
+}
+
+func asAdmin() context.Context {
+	return application.WithPrincipal(context.Background(), &domain.Principal{UserID: "admin", Role: domain.RoleAdmin})
+}
+
+// testUsers returns the regular users most tests act as, who are stored as
+// user_1 and user_2.
+func testUsers() []*domain.User {
+	return []*domain.User{
+		{Email: "one@example.com", Name: "One", Role: domain.RoleUser},
+		{Email: "two@example.com", Name: "Two", Role: domain.RoleUser},
+	}
+}
+
+// newUserRepository stores users in a memory repository, which numbers them
+// user_1, user_2 and so on in order.
+func newUserRepository(t *testing.T, users ...*domain.User) ports.UserRepository {
+	t.Helper()
+
+	userRepo := memory.NewUserRepository()
+	for _, user := range users {
+		if err := userRepo.Create(context.Background(), user); err != nil {
+			t.Fatalf("Create() unexpected error: %v", err)
+		}
+	}
+	return userRepo
+}
+
+func newAuthFixture(t *testing.T) (*application.AuthService, ports.APIKeyRepository) {
+	t.Helper()
+
+	keyRepo := memory.NewAPIKeyRepository()
+	return application.NewAuthService(keyRepo, memory.NewSessionRepository(), newUserRepository(t, testUsers()...), nil, nil, nil), keyRepo
+}
+
+// newSessionFixture logs user_1 in with the password correcthorse42.
+func newSessionFixture(t *testing.T) (*application.AuthService, ports.SessionRepository, *domain.AuthTokens) {
+	t.Helper()
+
+	users := testUsers()
+	users[0].PasswordHash = "hashed:correcthorse42"
+
+	sessionRepo := memory.NewSessionRepository()
+	service := application.NewAuthService(memory.NewAPIKeyRepository(), sessionRepo, newUserRepository(t, users...), nil, mockPasswordHasher{}, &mockTokenIssuer{})
+
+	tokens, err := service.Login(context.Background(), "one@example.com", "correcthorse42", domain.ClientInfo{UserAgent: "test"})
+	if err != nil {
+		t.Fatalf("Login() unexpected error: %v", err)
+	}
+	return service, sessionRepo, tokens
+}
+
+func newLocationFixture(t *testing.T, users ...*domain.User) (*application.LocationService, ports.LocationRepository, ports.UserRepository) {
+	t.Helper()
+
+	userRepo := newUserRepository(t, users...)
+	locationRepo := memory.NewLocationRepository()
+	return application.NewLocationService(locationRepo, userRepo), locationRepo, userRepo
+}
+
+// newAlertFixture stores the test users with user_1 preferring imperial units.
+func newAlertFixture(t *testing.T) (*application.AlertService, ports.AlertRuleRepository) {
+	t.Helper()
+
+	users := testUsers()
+	users[0].PreferredUnits = domain.UnitsImperial
+
+	ruleRepo := memory.NewAlertRuleRepository()
+	return application.NewAlertService(ruleRepo, newUserRepository(t, users...)), ruleRepo
+}
+
+// mockPasswordHasher "hashes" a password by prefixing it with "hashed:".
+type mockPasswordHasher struct{}
+
+func (mockPasswordHasher) Hash(password string) (string, error) {
+	return "hashed:" + password, nil
+}
+
+func (mockPasswordHasher) Verify(hash, password string) (bool, error) {
+	return hash == "hashed:"+password, nil
+}
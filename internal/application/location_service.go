@@ -0,0 +1,178 @@
+package application
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/leinonen/hexagonal-architecture-go/internal/domain"
+	"github.com/leinonen/hexagonal-architecture-go/internal/errors"
+	"github.com/leinonen/hexagonal-architecture-go/internal/ports"
+)
+
+// LocationService manages the places users save for weather lookups. A
+// user's first location becomes primary, and deleting the primary location
+// promotes the oldest remaining one.
+type LocationService struct {
+	locationRepo ports.LocationRepository
+	userRepo     ports.UserRepository
+}
+
+func NewLocationService(locationRepo ports.LocationRepository, userRepo ports.UserRepository) *LocationService {
+	return &LocationService{
+		locationRepo: locationRepo,
+		userRepo:     userRepo,
+	}
+}
+
+func (s *LocationService) CreateLocation(ctx context.Context, userID, name string, query domain.WeatherQuery, primary bool) (*domain.Location, error) {
+	if userID == "" {
+		return nil, errors.NewValidationError("user ID is required")
+	}
+	if err := authorizeUser(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	location, err := domain.NewLocation(userID, name, query)
+	if err != nil {
+		return nil, errors.NewValidationError(err.Error())
+	}
+
+	if _, err := s.userRepo.GetByID(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.locationRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= domain.MaxLocationsPerUser {
+		return nil, errors.NewConflictError(fmt.Sprintf("at most %d locations may be saved", domain.MaxLocationsPerUser))
+	}
+
+	location.Primary = primary || len(existing) == 0
+	if err := s.locationRepo.Create(ctx, location); err != nil {
+		return nil, err
+	}
+	if location.Primary {
+		if err := s.demoteOthers(ctx, location); err != nil {
+			return nil, err
+		}
+	}
+
+	return location, nil
+}
+
+func (s *LocationService) GetLocation(ctx context.Context, userID, locationID string) (*domain.Location, error) {
+	if err := s.authorize(ctx, userID, locationID); err != nil {
+		return nil, err
+	}
+	return s.ownedLocation(ctx, userID, locationID)
+}
+
+func (s *LocationService) ListLocations(ctx context.Context, userID string) ([]*domain.Location, error) {
+	if userID == "" {
+		return nil, errors.NewValidationError("user ID is required")
+	}
+	if err := authorizeUser(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	return s.locationRepo.ListByUser(ctx, userID)
+}
+
+// UpdateLocation replaces the location's name and place. Making it primary
+// demotes the user's previous primary location; the primary location itself
+// cannot be demoted, only replaced by another.
+func (s *LocationService) UpdateLocation(ctx context.Context, userID, locationID, name string, query domain.WeatherQuery, primary bool) (*domain.Location, error) {
+	if err := s.authorize(ctx, userID, locationID); err != nil {
+		return nil, err
+	}
+
+	location, err := s.ownedLocation(ctx, userID, locationID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := location.Change(name, query); err != nil {
+		return nil, errors.NewValidationError(err.Error())
+	}
+
+	promoted := primary && !location.Primary
+	location.Primary = location.Primary || primary
+
+	if err := s.locationRepo.Update(ctx, location); err != nil {
+		return nil, err
+	}
+	if promoted {
+		if err := s.demoteOthers(ctx, location); err != nil {
+			return nil, err
+		}
+	}
+
+	return location, nil
+}
+
+func (s *LocationService) DeleteLocation(ctx context.Context, userID, locationID string) error {
+	if err := s.authorize(ctx, userID, locationID); err != nil {
+		return err
+	}
+
+	location, err := s.ownedLocation(ctx, userID, locationID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.locationRepo.Delete(ctx, location.ID); err != nil {
+		return err
+	}
+	if !location.Primary {
+		return nil
+	}
+
+	remaining, err := s.locationRepo.ListByUser(ctx, userID)
+	if err != nil || len(remaining) == 0 {
+		return err
+	}
+	remaining[0].Primary = true
+	return s.locationRepo.Update(ctx, remaining[0])
+}
+
+func (s *LocationService) authorize(ctx context.Context, userID, locationID string) error {
+	if userID == "" {
+		return errors.NewValidationError("user ID is required")
+	}
+	if locationID == "" {
+		return errors.NewValidationError("location ID is required")
+	}
+	return authorizeUser(ctx, userID)
+}
+
+// ownedLocation reports another user's location as not found, so that IDs
+// cannot be probed.
+func (s *LocationService) ownedLocation(ctx context.Context, userID, locationID string) (*domain.Location, error) {
+	location, err := s.locationRepo.GetByID(ctx, locationID)
+	if err != nil {
+		return nil, err
+	}
+	if location.UserID != userID {
+		return nil, errors.NewNotFoundError("location not found")
+	}
+	return location, nil
+}
+
+func (s *LocationService) demoteOthers(ctx context.Context, primary *domain.Location) error {
+	locations, err := s.locationRepo.ListByUser(ctx, primary.UserID)
+	if err != nil {
+		return err
+	}
+
+	for _, location := range locations {
+		if location.ID != primary.ID && location.Primary {
+			location.Primary = false
+			if err := s.locationRepo.Update(ctx, location); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package application_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/leinonen/hexagonal-architecture-go/internal/domain"
+	"github.com/leinonen/hexagonal-architecture-go/internal/errors"
+	"github.com/leinonen/hexagonal-architecture-go/internal/ports"
+)
+
+// primaryName returns the name of the user's primary location, failing the
+// test unless there is exactly one.
+func primaryName(t *testing.T, repo ports.LocationRepository, userID string) string {
+	t.Helper()
+
+	locations, err := repo.ListByUser(context.Background(), userID)
+	if err != nil {
+		t.Fatalf("ListByUser() unexpected error: %v", err)
+	}
+
+	var names []string
+	for _, location := range locations {
+		if location.Primary {
+			names = append(names, location.Name)
+		}
+	}
+	if len(names) != 1 {
+		t.Fatalf("user %s has primary locations %v, want exactly one", userID, names)
+	}
+	return names[0]
+}
+
+func TestLocationService_Primary(t *testing.T) {
+	service, repo, _ := newLocationFixture(t, testUsers()...)
+	ctx := asUser("user_1")
+
+	home, err := service.CreateLocation(ctx, "user_1", "Home", domain.CityQuery("London"), false)
+	if err != nil {
+		t.Fatalf("CreateLocation() unexpected error: %v", err)
+	}
+	if !home.Primary {
+		t.Errorf("CreateLocation() first location should become primary")
+	}
+
+	work, _ := service.CreateLocation(ctx, "user_1", "Work", domain.CityQuery("Paris"), false)
+	if name := primaryName(t, repo, "user_1"); name != "Home" {
+		t.Errorf("primary location = %s, want Home", name)
+	}
+
+	if _, err := service.CreateLocation(ctx, "user_1", "Cabin", domain.CityQuery("Oslo"), true); err != nil {
+		t.Fatalf("CreateLocation() unexpected error: %v", err)
+	}
+	if name := primaryName(t, repo, "user_1"); name != "Cabin" {
+		t.Errorf("primary location = %s, want Cabin", name)
+	}
+
+	if _, err := service.UpdateLocation(ctx, "user_1", work.ID, "Office", domain.CityQuery("Paris"), true); err != nil {
+		t.Fatalf("UpdateLocation() unexpected error: %v", err)
+	}
+	if name := primaryName(t, repo, "user_1"); name != "Office" {
+		t.Errorf("primary location = %s, want Office", name)
+	}
+
+	if err := service.DeleteLocation(ctx, "user_1", work.ID); err != nil {
+		t.Fatalf("DeleteLocation() unexpected error: %v", err)
+	}
+	if name := primaryName(t, repo, "user_1"); name != "Home" {
+		t.Errorf("primary location after deleting it = %s, want the oldest remaining, Home", name)
+	}
+}
+
+func TestLocationService_Authorization(t *testing.T) {
+	service, _, _ := newLocationFixture(t, testUsers()...)
+
+	location, _ := service.CreateLocation(asUser("user_1"), "user_1", "Home", domain.CityQuery("London"), false)
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		call    func(ctx context.Context) error
+		errType errors.ErrorType
+	}{
+		{
+			name: "create for another user",
+			ctx:  asUser("user_2"),
+			call: func(ctx context.Context) error {
+				_, err := service.CreateLocation(ctx, "user_1", "Home", domain.CityQuery("London"), false)
+				return err
+			},
+			errType: errors.Forbidden,
+		},
+		{
+			name: "list another user's locations",
+			ctx:  asUser("user_2"),
+			call: func(ctx context.Context) error {
+				_, err := service.ListLocations(ctx, "user_1")
+				return err
+			},
+			errType: errors.Forbidden,
+		},
+		{
+			name: "get another user's location",
+			ctx:  asUser("user_2"),
+			call: func(ctx context.Context) error {
+				_, err := service.GetLocation(ctx, "user_2", location.ID)
+				return err
+			},
+			errType: errors.NotFound,
+		},
+		{
+			name: "delete another user's location",
+			ctx:  asUser("user_2"),
+			call: func(ctx context.Context) error {
+				return service.DeleteLocation(ctx, "user_2", location.ID)
+			},
+			errType: errors.NotFound,
+		},
+		{
+			name: "admin gets a user's location",
+			ctx:  asAdmin(),
+			call: func(ctx context.Context) error {
+				_, err := service.GetLocation(ctx, "user_1", location.ID)
+				return err
+			},
+		},
+		{
+			name: "admin creates for unknown user",
+			ctx:  asAdmin(),
+			call: func(ctx context.Context) error {
+				_, err := service.CreateLocation(ctx, "user_404", "Home", domain.CityQuery("London"), false)
+				return err
+			},
+			errType: errors.NotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.call(tt.ctx)
+
+			if tt.errType == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			appErr, ok := err.(*errors.AppError)
+			if !ok || appErr.Type != tt.errType {
+				t.Errorf("error = %v, want %v", err, tt.errType)
+			}
+		})
+	}
+}
+
+func TestLocationService_Validation(t *testing.T) {
+	service, _, _ := newLocationFixture(t, testUsers()...)
+	ctx := asUser("user_1")
+
+	if _, err := service.CreateLocation(ctx, "user_1", "Home", domain.WeatherQuery{}, false); !errors.IsValidation(err) {
+		t.Errorf("CreateLocation() without a place error = %v, want validation error", err)
+	}
+
+	for i := 0; i < domain.MaxLocationsPerUser; i++ {
+		if _, err := service.CreateLocation(ctx, "user_1", "", domain.CityQuery(fmt.Sprintf("City %d", i)), false); err != nil {
+			t.Fatalf("CreateLocation() unexpected error: %v", err)
+		}
+	}
+	if _, err := service.CreateLocation(ctx, "user_1", "", domain.CityQuery("London"), false); !errors.IsConflict(err) {
+		t.Errorf("CreateLocation() over the limit error = %v, want conflict", err)
+	}
+}
@@ -0,0 +1,68 @@
+package application
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/leinonen/hexagonal-architecture-go/internal/domain"
+	"github.com/leinonen/hexagonal-architecture-go/internal/ports"
+)
+
+const DefaultSampleInterval = 15 * time.Minute
+
+// ObservationSampler looks up the weather at tracked places on a schedule, so
+// that their history has no gaps when nobody asks for them. It only looks
+// the weather up; recording is left to the weather client.
+type ObservationSampler struct {
+	weatherClient ports.WeatherService
+	places        []domain.WeatherQuery
+	interval      time.Duration
+}
+
+func NewObservationSampler(weatherClient ports.WeatherService, places []domain.WeatherQuery, interval time.Duration) *ObservationSampler {
+	if interval <= 0 {
+		interval = DefaultSampleInterval
+	}
+
+	return &ObservationSampler{
+		weatherClient: weatherClient,
+		places:        places,
+		interval:      interval,
+	}
+}
+
+// Run samples straight away and then every interval until ctx ends.
+func (s *ObservationSampler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.Sample(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("Weather sampling: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sample looks up every tracked place once. The failures are returned
+// together.
+func (s *ObservationSampler) Sample(ctx context.Context) error {
+	var failures []error
+	for _, place := range s.places {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if _, err := s.weatherClient.GetWeather(ctx, place); err != nil {
+			failures = append(failures, fmt.Errorf("%s: %w", place.Key(), err))
+		}
+	}
+	return stderrors.Join(failures...)
+}
@@ -0,0 +1,24 @@
+package application_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/leinonen/hexagonal-architecture-go/internal/application"
+	"github.com/leinonen/hexagonal-architecture-go/internal/domain"
+)
+
+func TestObservationSampler_Sample(t *testing.T) {
+	weather := &thermometer{temperatures: map[string]float64{"London": 5, "Oslo": -3}}
+	places := []domain.WeatherQuery{domain.CityQuery("London"), domain.CityQuery("Atlantis"), domain.CityQuery("Oslo")}
+	sampler := application.NewObservationSampler(weather, places, 0)
+
+	err := sampler.Sample(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "city:atlantis") {
+		t.Errorf("Sample() error = %v, want the failed Atlantis lookup", err)
+	}
+	if weather.lookups != 3 {
+		t.Errorf("weather looked up %d times, want every place despite the failure", weather.lookups)
+	}
+}
@@ -0,0 +1,27 @@
+package application
+
+import (
+	"context"
+
+	"github.com/leinonen/hexagonal-architecture-go/internal/domain"
+	"github.com/leinonen/hexagonal-architecture-go/internal/errors"
+)
+
+type principalKey struct{}
+
+func WithPrincipal(ctx context.Context, principal *domain.Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+func PrincipalFromContext(ctx context.Context) (*domain.Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(*domain.Principal)
+	return principal, ok && principal != nil
+}
+
+func requirePrincipal(ctx context.Context) (*domain.Principal, error) {
+	principal, ok := PrincipalFromContext(ctx)
+	if !ok {
+		return nil, errors.NewUnauthorizedError("authentication required")
+	}
+	return principal, nil
+}
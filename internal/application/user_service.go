@@ -2,6 +2,7 @@ package application
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/leinonen/hexagonal-architecture-go/internal/domain"
@@ -10,22 +11,44 @@ import (
 )
 
 type UserService struct {
-	userRepo ports.UserRepository
+	userRepo       ports.UserRepository
+	sessionRepo    ports.SessionRepository
+	passwordHasher ports.PasswordHasher
+	owned          []ports.UserOwnedRepository
 }
 
-func NewUserService(userRepo ports.UserRepository) *UserService {
+// NewUserService manages accounts. Records in the owned repositories, such
+// as saved locations and alert rules, are deleted together with their user.
+// A user's sessions are revoked when their password changes; sessionRepo may
+// be nil when logins are disabled.
+func NewUserService(userRepo ports.UserRepository, sessionRepo ports.SessionRepository, passwordHasher ports.PasswordHasher, owned ...ports.UserOwnedRepository) *UserService {
 	return &UserService{
-		userRepo: userRepo,
+		userRepo:       userRepo,
+		sessionRepo:    sessionRepo,
+		passwordHasher: passwordHasher,
+		owned:          owned,
 	}
 }
 
-func (s *UserService) CreateUser(ctx context.Context, email, name string) (*domain.User, error) {
+// CreateUser registers a new account. The password is optional; accounts
+// without one can only authenticate with API keys.
+func (s *UserService) CreateUser(ctx context.Context, email, name, password string) (*domain.User, error) {
+	return createUser(ctx, s.userRepo, s.passwordHasher, email, name, password)
+}
+
+func createUser(ctx context.Context, userRepo ports.UserRepository, passwordHasher ports.PasswordHasher, email, name, password string) (*domain.User, error) {
 	user, err := domain.NewUser(email, name)
 	if err != nil {
 		return nil, errors.NewValidationError(err.Error())
 	}
 
-	existingUser, err := s.userRepo.GetByEmail(ctx, email)
+	if password != "" {
+		if user.PasswordHash, err = hashPassword(passwordHasher, password); err != nil {
+			return nil, err
+		}
+	}
+
+	existingUser, err := userRepo.GetByEmail(ctx, email)
 	if err != nil && !errors.IsNotFound(err) {
 		return nil, err
 	}
@@ -33,6 +56,39 @@ func (s *UserService) CreateUser(ctx context.Context, email, name string) (*doma
 		return nil, errors.NewConflictError("user with this email already exists")
 	}
 
+	if err := userRepo.Create(ctx, user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// EnsureAdmin creates the account with the admin role, or promotes it if a
+// user with that email already exists. It is meant for bootstrapping from
+// configuration and performs no authorization.
+func (s *UserService) EnsureAdmin(ctx context.Context, email, name string) (*domain.User, error) {
+	existingUser, err := s.userRepo.GetByEmail(ctx, email)
+	if err != nil && !errors.IsNotFound(err) {
+		return nil, err
+	}
+
+	if existingUser != nil {
+		if existingUser.Role != domain.RoleAdmin {
+			existingUser.Role = domain.RoleAdmin
+			existingUser.UpdatedAt = time.Now()
+			if err := s.userRepo.Update(ctx, existingUser); err != nil {
+				return nil, err
+			}
+		}
+		return existingUser, nil
+	}
+
+	user, err := domain.NewUser(email, name)
+	if err != nil {
+		return nil, errors.NewValidationError(err.Error())
+	}
+	user.Role = domain.RoleAdmin
+
 	if err := s.userRepo.Create(ctx, user); err != nil {
 		return nil, err
 	}
@@ -44,6 +100,9 @@ func (s *UserService) GetUser(ctx context.Context, id string) (*domain.User, err
 	if id == "" {
 		return nil, errors.NewValidationError("user ID is required")
 	}
+	if err := authorizeUser(ctx, id); err != nil {
+		return nil, err
+	}
 
 	return s.userRepo.GetByID(ctx, id)
 }
@@ -56,49 +115,191 @@ func (s *UserService) GetUserByEmail(ctx context.Context, email string) (*domain
 	return s.userRepo.GetByEmail(ctx, email)
 }
 
-func (s *UserService) UpdateUser(ctx context.Context, id, email, name string) (*domain.User, error) {
+// UserUpdate holds the changes to a user's account. Empty fields are left as
+// they are.
+type UserUpdate struct {
+	Email string
+	Name  string
+	// Role may only be changed by admins, and never on their own account.
+	Role domain.Role
+	// Units becomes the user's preferred units for weather lookups.
+	Units domain.Units
+}
+
+// UpdateUser checks every change before saving any of them, so that a
+// rejected update leaves the account untouched. Asking for the role the user
+// already has is not a role change.
+func (s *UserService) UpdateUser(ctx context.Context, id string, update UserUpdate) (*domain.User, error) {
 	if id == "" {
 		return nil, errors.NewValidationError("user ID is required")
 	}
+	if update.Role != "" && !update.Role.Valid() {
+		return nil, errors.NewValidationError("role must be admin or user")
+	}
+	if update.Units != "" && !update.Units.Valid() {
+		return nil, errors.NewValidationError("units must be metric, imperial or standard")
+	}
+	if err := authorizeUser(ctx, id); err != nil {
+		return nil, err
+	}
 
 	user, err := s.userRepo.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
+	updated := *user
+
+	if update.Role != "" && update.Role != user.Role {
+		if err := requireAdmin(ctx); err != nil {
+			return nil, err
+		}
+		principal, _ := PrincipalFromContext(ctx)
+		if principal.UserID == id {
+			return nil, errors.NewConflictError("admins cannot revoke their own admin role")
+		}
+		updated.Role = update.Role
+	}
 
-	if email != "" && email != user.Email {
-		existingUser, err := s.userRepo.GetByEmail(ctx, email)
+	if update.Email != "" && update.Email != user.Email {
+		existingUser, err := s.userRepo.GetByEmail(ctx, update.Email)
 		if err != nil && !errors.IsNotFound(err) {
 			return nil, err
 		}
 		if existingUser != nil && existingUser.ID != id {
 			return nil, errors.NewConflictError("email already in use")
 		}
-		user.Email = email
+		updated.Email = update.Email
 	}
 
-	if name != "" {
-		user.Name = name
+	if update.Name != "" {
+		updated.Name = update.Name
+	}
+	if update.Units != "" {
+		updated.PreferredUnits = update.Units
 	}
 
-	user.UpdatedAt = time.Now()
+	updated.UpdatedAt = time.Now()
 
-	if err := s.userRepo.Update(ctx, user); err != nil {
+	if err := s.userRepo.Update(ctx, &updated); err != nil {
 		return nil, err
 	}
 
-	return user, nil
+	return &updated, nil
+}
+
+// ChangePassword sets a new password. Users must prove the current one;
+// admins may reset another user's password without it. Every session of the
+// user is revoked, so refresh tokens issued under the old password stop
+// working.
+func (s *UserService) ChangePassword(ctx context.Context, id, currentPassword, newPassword string) error {
+	if id == "" {
+		return errors.NewValidationError("user ID is required")
+	}
+	if err := authorizeUser(ctx, id); err != nil {
+		return err
+	}
+
+	user, err := s.userRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	principal, _ := PrincipalFromContext(ctx)
+	if principal.UserID == id && user.HasPassword() {
+		if currentPassword == "" {
+			return errors.NewValidationError("current password is required")
+		}
+		ok, err := s.passwordHasher.Verify(user.PasswordHash, currentPassword)
+		if err != nil {
+			return errors.NewInternalError(fmt.Sprintf("failed to verify password: %v", err))
+		}
+		if !ok {
+			return errors.NewUnauthorizedError("current password is incorrect")
+		}
+	}
+
+	hash, err := hashPassword(s.passwordHasher, newPassword)
+	if err != nil {
+		return err
+	}
+
+	updated := *user
+	updated.PasswordHash = hash
+	updated.RecordSuccessfulLogin()
+	updated.UpdatedAt = time.Now()
+
+	if err := s.userRepo.Update(ctx, &updated); err != nil {
+		return err
+	}
+	return s.revokeSessions(ctx, id)
+}
+
+func (s *UserService) revokeSessions(ctx context.Context, userID string) error {
+	if s.sessionRepo == nil {
+		return nil
+	}
+
+	sessions, err := s.sessionRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, session := range sessions {
+		if !session.IsActive(now) {
+			continue
+		}
+		session.Revoke(now)
+		if err := s.sessionRepo.Update(ctx, session); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func hashPassword(passwordHasher ports.PasswordHasher, password string) (string, error) {
+	if err := domain.ValidatePassword(password); err != nil {
+		return "", errors.NewValidationError(err.Error())
+	}
+	if passwordHasher == nil {
+		return "", errors.NewInternalError("password hashing is not configured")
+	}
+
+	hash, err := passwordHasher.Hash(password)
+	if err != nil {
+		return "", errors.NewInternalError(fmt.Sprintf("failed to hash password: %v", err))
+	}
+	return hash, nil
 }
 
 func (s *UserService) DeleteUser(ctx context.Context, id string) error {
 	if id == "" {
 		return errors.NewValidationError("user ID is required")
 	}
+	if err := requireAdmin(ctx); err != nil {
+		return err
+	}
+
+	if _, err := s.userRepo.GetByID(ctx, id); err != nil {
+		return err
+	}
+
+	// The user's records go first, so that a failure leaves the account in
+	// place and the deletion can be retried.
+	for _, repo := range s.owned {
+		if err := repo.DeleteByUser(ctx, id); err != nil {
+			return err
+		}
+	}
 
 	return s.userRepo.Delete(ctx, id)
 }
 
 func (s *UserService) ListUsers(ctx context.Context, limit, offset int) ([]*domain.User, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+
 	if limit <= 0 {
 		limit = 10
 	}
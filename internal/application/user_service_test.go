@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/leinonen/hexagonal-architecture-go/internal/adapters/repository/memory"
 	"github.com/leinonen/hexagonal-architecture-go/internal/application"
 	"github.com/leinonen/hexagonal-architecture-go/internal/domain"
 	"github.com/leinonen/hexagonal-architecture-go/internal/errors"
@@ -66,6 +67,28 @@ func (m *mockUserRepository) Update(ctx context.Context, user *domain.User) erro
 	return nil
 }
 
+func (m *mockUserRepository) RecordFailedLogin(ctx context.Context, id string, now time.Time) error {
+	user, exists := m.users[id]
+	if !exists {
+		return errors.NewNotFoundError("user not found")
+	}
+	updated := *user
+	updated.RecordFailedLogin(now)
+	m.users[id] = &updated
+	return nil
+}
+
+func (m *mockUserRepository) ResetFailedLogins(ctx context.Context, id string) error {
+	user, exists := m.users[id]
+	if !exists {
+		return errors.NewNotFoundError("user not found")
+	}
+	updated := *user
+	updated.RecordSuccessfulLogin()
+	m.users[id] = &updated
+	return nil
+}
+
 func (m *mockUserRepository) Delete(ctx context.Context, id string) error {
 	if m.deleteErr != nil {
 		return m.deleteErr
@@ -140,8 +163,8 @@ func TestUserService_CreateUser(t *testing.T) {
 			repo := newMockUserRepository()
 			tt.setupMock(repo)
 
-			service := application.NewUserService(repo)
-			user, err := service.CreateUser(ctx, tt.email, tt.userName)
+			service := application.NewUserService(repo, nil, nil)
+			user, err := service.CreateUser(ctx, tt.email, tt.userName, "")
 
 			if tt.wantErr {
 				if err == nil {
@@ -171,7 +194,7 @@ func TestUserService_CreateUser(t *testing.T) {
 }
 
 func TestUserService_GetUser(t *testing.T) {
-	ctx := context.Background()
+	ctx := asAdmin()
 
 	tests := []struct {
 		name      string
@@ -214,7 +237,7 @@ func TestUserService_GetUser(t *testing.T) {
 			repo := newMockUserRepository()
 			tt.setupMock(repo)
 
-			service := application.NewUserService(repo)
+			service := application.NewUserService(repo, nil, nil)
 			user, err := service.GetUser(ctx, tt.userID)
 
 			if tt.wantErr {
@@ -237,7 +260,7 @@ func TestUserService_GetUser(t *testing.T) {
 }
 
 func TestUserService_UpdateUser(t *testing.T) {
-	ctx := context.Background()
+	ctx := asAdmin()
 
 	tests := []struct {
 		name      string
@@ -309,8 +332,8 @@ func TestUserService_UpdateUser(t *testing.T) {
 			repo := newMockUserRepository()
 			tt.setupMock(repo)
 
-			service := application.NewUserService(repo)
-			user, err := service.UpdateUser(ctx, tt.userID, tt.newEmail, tt.newName)
+			service := application.NewUserService(repo, nil, nil)
+			user, err := service.UpdateUser(ctx, tt.userID, application.UserUpdate{Email: tt.newEmail, Name: tt.newName})
 
 			if tt.wantErr {
 				if err == nil {
@@ -336,7 +359,7 @@ func TestUserService_UpdateUser(t *testing.T) {
 }
 
 func TestUserService_DeleteUser(t *testing.T) {
-	ctx := context.Background()
+	ctx := asAdmin()
 
 	tests := []struct {
 		name      string
@@ -369,7 +392,7 @@ func TestUserService_DeleteUser(t *testing.T) {
 			repo := newMockUserRepository()
 			tt.setupMock(repo)
 
-			service := application.NewUserService(repo)
+			service := application.NewUserService(repo, nil, nil)
 			err := service.DeleteUser(ctx, tt.userID)
 
 			if tt.wantErr {
@@ -387,14 +410,14 @@ func TestUserService_DeleteUser(t *testing.T) {
 }
 
 func TestUserService_ListUsers(t *testing.T) {
-	ctx := context.Background()
+	ctx := asAdmin()
 
 	repo := newMockUserRepository()
 	repo.users["1"] = &domain.User{ID: "1", Email: "user1@example.com"}
 	repo.users["2"] = &domain.User{ID: "2", Email: "user2@example.com"}
 	repo.users["3"] = &domain.User{ID: "3", Email: "user3@example.com"}
 
-	service := application.NewUserService(repo)
+	service := application.NewUserService(repo, nil, nil)
 
 	tests := []struct {
 		name      string
@@ -436,3 +459,357 @@ func TestUserService_ListUsers(t *testing.T) {
 		})
 	}
 }
+
+func TestUserService_Authorization(t *testing.T) {
+	service := application.NewUserService(newUserRepository(t, testUsers()...), nil, nil)
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		call    func(ctx context.Context) error
+		errType errors.ErrorType
+	}{
+		{
+			name: "user reads self",
+			ctx:  asUser("user_1"),
+			call: func(ctx context.Context) error {
+				_, err := service.GetUser(ctx, "user_1")
+				return err
+			},
+		},
+		{
+			name: "user reads another user",
+			ctx:  asUser("user_1"),
+			call: func(ctx context.Context) error {
+				_, err := service.GetUser(ctx, "user_2")
+				return err
+			},
+			errType: errors.Forbidden,
+		},
+		{
+			name: "user updates self",
+			ctx:  asUser("user_1"),
+			call: func(ctx context.Context) error {
+				_, err := service.UpdateUser(ctx, "user_1", application.UserUpdate{Name: "Renamed"})
+				return err
+			},
+		},
+		{
+			name: "user updates another user",
+			ctx:  asUser("user_1"),
+			call: func(ctx context.Context) error {
+				_, err := service.UpdateUser(ctx, "user_2", application.UserUpdate{Name: "Renamed"})
+				return err
+			},
+			errType: errors.Forbidden,
+		},
+		{
+			name: "user lists users",
+			ctx:  asUser("user_1"),
+			call: func(ctx context.Context) error {
+				_, err := service.ListUsers(ctx, 10, 0)
+				return err
+			},
+			errType: errors.Forbidden,
+		},
+		{
+			name: "user deletes self",
+			ctx:  asUser("user_1"),
+			call: func(ctx context.Context) error {
+				return service.DeleteUser(ctx, "user_1")
+			},
+			errType: errors.Forbidden,
+		},
+		{
+			name: "user keeps own role",
+			ctx:  asUser("user_1"),
+			call: func(ctx context.Context) error {
+				_, err := service.UpdateUser(ctx, "user_1", application.UserUpdate{Name: "Renamed", Role: domain.RoleUser})
+				return err
+			},
+		},
+		{
+			name: "user promotes self",
+			ctx:  asUser("user_1"),
+			call: func(ctx context.Context) error {
+				_, err := service.UpdateUser(ctx, "user_1", application.UserUpdate{Role: domain.RoleAdmin})
+				return err
+			},
+			errType: errors.Forbidden,
+		},
+		{
+			name: "anonymous reads user",
+			ctx:  context.Background(),
+			call: func(ctx context.Context) error {
+				_, err := service.GetUser(ctx, "user_1")
+				return err
+			},
+			errType: errors.Unauthorized,
+		},
+		{
+			name: "admin updates another user",
+			ctx:  asAdmin(),
+			call: func(ctx context.Context) error {
+				_, err := service.UpdateUser(ctx, "user_2", application.UserUpdate{Name: "Renamed"})
+				return err
+			},
+		},
+		{
+			name: "admin promotes user",
+			ctx:  asAdmin(),
+			call: func(ctx context.Context) error {
+				_, err := service.UpdateUser(ctx, "user_2", application.UserUpdate{Role: domain.RoleAdmin})
+				return err
+			},
+		},
+		{
+			name: "admin deletes user",
+			ctx:  asAdmin(),
+			call: func(ctx context.Context) error {
+				return service.DeleteUser(ctx, "user_1")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.call(tt.ctx)
+
+			if tt.errType == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			appErr, ok := err.(*errors.AppError)
+			if !ok || appErr.Type != tt.errType {
+				t.Errorf("error = %v, want %v", err, tt.errType)
+			}
+		})
+	}
+}
+
+func TestUserService_EnsureAdmin(t *testing.T) {
+	ctx := context.Background()
+	repo := newUserRepository(t)
+	service := application.NewUserService(repo, nil, nil)
+
+	admin, err := service.EnsureAdmin(ctx, "admin@example.com", "Admin")
+	if err != nil {
+		t.Fatalf("EnsureAdmin() unexpected error: %v", err)
+	}
+
+	if admin.Role != domain.RoleAdmin {
+		t.Errorf("EnsureAdmin() role = %v, want %v", admin.Role, domain.RoleAdmin)
+	}
+
+	again, err := service.EnsureAdmin(ctx, "admin@example.com", "Admin")
+	if err != nil {
+		t.Fatalf("EnsureAdmin() second call unexpected error: %v", err)
+	}
+
+	if users, _ := repo.List(ctx, 10, 0); again.ID != admin.ID || len(users) != 1 {
+		t.Errorf("EnsureAdmin() should reuse the existing account")
+	}
+}
+
+func TestUserService_CreateUserWithPassword(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		password string
+		wantErr  bool
+	}{
+		{name: "no password", password: ""},
+		{name: "strong password", password: "correcthorse42"},
+		{name: "weak password", password: "short", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newUserRepository(t)
+			service := application.NewUserService(repo, nil, mockPasswordHasher{})
+
+			user, err := service.CreateUser(ctx, "test@example.com", "Test User", tt.password)
+
+			if tt.wantErr {
+				if !errors.IsValidation(err) {
+					t.Errorf("CreateUser() error = %v, want validation", err)
+				}
+				if _, err := repo.GetByEmail(ctx, "test@example.com"); !errors.IsNotFound(err) {
+					t.Errorf("CreateUser() stored a user despite a weak password")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("CreateUser() unexpected error: %v", err)
+			}
+
+			if user.HasPassword() != (tt.password != "") {
+				t.Errorf("CreateUser() HasPassword = %v, want %v", user.HasPassword(), tt.password != "")
+			}
+
+			if tt.password != "" && user.PasswordHash == tt.password {
+				t.Errorf("CreateUser() stored the plaintext password")
+			}
+		})
+	}
+}
+
+func TestUserService_ChangePassword(t *testing.T) {
+	tests := []struct {
+		name        string
+		ctx         context.Context
+		current     string
+		newPassword string
+		errType     errors.ErrorType
+	}{
+		{
+			name:        "self with current password",
+			ctx:         asUser("user_1"),
+			current:     "correcthorse42",
+			newPassword: "batterystaple99",
+		},
+		{
+			name:        "self with wrong current password",
+			ctx:         asUser("user_1"),
+			current:     "wrong",
+			newPassword: "batterystaple99",
+			errType:     errors.Unauthorized,
+		},
+		{
+			name:        "self without current password",
+			ctx:         asUser("user_1"),
+			newPassword: "batterystaple99",
+			errType:     errors.Validation,
+		},
+		{
+			name:        "weak new password",
+			ctx:         asUser("user_1"),
+			current:     "correcthorse42",
+			newPassword: "weak",
+			errType:     errors.Validation,
+		},
+		{
+			name:        "admin reset",
+			ctx:         asAdmin(),
+			newPassword: "batterystaple99",
+		},
+		{
+			name:        "another user",
+			ctx:         asUser("user_2"),
+			newPassword: "batterystaple99",
+			errType:     errors.Forbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := testUsers()
+			users[0].PasswordHash = "hashed:correcthorse42"
+			repo := newUserRepository(t, users...)
+			sessionRepo := memory.NewSessionRepository()
+			sessions := make([]*domain.Session, 0, 2)
+			for _, userID := range []string{"user_1", "user_2"} {
+				session, _ := domain.NewSession(userID, "hash_"+userID, domain.ClientInfo{})
+				sessionRepo.Create(context.Background(), session)
+				sessions = append(sessions, session)
+			}
+			service := application.NewUserService(repo, sessionRepo, mockPasswordHasher{})
+
+			err := service.ChangePassword(tt.ctx, "user_1", tt.current, tt.newPassword)
+
+			own, _ := sessionRepo.GetByID(context.Background(), sessions[0].ID)
+			if revoked := !own.IsActive(time.Now()); revoked != (tt.errType == "") {
+				t.Errorf("ChangePassword() revoked the user's session = %v, want %v", revoked, tt.errType == "")
+			}
+			other, _ := sessionRepo.GetByID(context.Background(), sessions[1].ID)
+			if !other.IsActive(time.Now()) {
+				t.Errorf("ChangePassword() revoked another user's session")
+			}
+
+			if tt.errType != "" {
+				appErr, ok := err.(*errors.AppError)
+				if !ok || appErr.Type != tt.errType {
+					t.Errorf("ChangePassword() error = %v, want %v", err, tt.errType)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("ChangePassword() unexpected error: %v", err)
+			}
+
+			if user, _ := repo.GetByID(context.Background(), "user_1"); user.PasswordHash != "hashed:"+tt.newPassword {
+				t.Errorf("ChangePassword() did not store the new password hash")
+			}
+		})
+	}
+}
+
+func TestUserService_DeleteUserRemovesOwnedRecords(t *testing.T) {
+	repo := newUserRepository(t, testUsers()...)
+
+	locationRepo := memory.NewLocationRepository()
+	ruleRepo := memory.NewAlertRuleRepository()
+	service := application.NewUserService(repo, nil, nil, locationRepo, ruleRepo)
+	ctx := context.Background()
+
+	for _, userID := range []string{"user_1", "user_2"} {
+		location, _ := domain.NewLocation(userID, "Home", domain.CityQuery("London"))
+		locationRepo.Create(ctx, location)
+		rule, _ := domain.NewAlertRule(userID, domain.CityQuery("London"), domain.MetricTemperature, domain.ComparatorBelow, 0, domain.UnitsMetric)
+		ruleRepo.Create(ctx, rule)
+	}
+
+	if err := service.DeleteUser(asAdmin(), "user_1"); err != nil {
+		t.Fatalf("DeleteUser() unexpected error: %v", err)
+	}
+
+	if locations, _ := locationRepo.ListByUser(ctx, "user_1"); len(locations) != 0 {
+		t.Errorf("DeleteUser() left %d locations", len(locations))
+	}
+	if rules, _ := ruleRepo.ListByUser(ctx, "user_1"); len(rules) != 0 {
+		t.Errorf("DeleteUser() left %d alert rules", len(rules))
+	}
+	if rules, _ := ruleRepo.List(ctx); len(rules) != 1 || rules[0].UserID != "user_2" {
+		t.Errorf("alert rules after DeleteUser() = %v, want only user_2's", rules)
+	}
+
+	if err := service.DeleteUser(asAdmin(), "user_1"); !errors.IsNotFound(err) {
+		t.Errorf("DeleteUser() again error = %v, want not found", err)
+	}
+}
+
+func TestUserService_UpdateUserIsAllOrNothing(t *testing.T) {
+	admin := &domain.User{Email: "admin@example.com", Name: "Admin", Role: domain.RoleAdmin}
+	repo := newUserRepository(t, append(testUsers(), admin)...)
+	service := application.NewUserService(repo, nil, nil)
+	asStoredAdmin := application.WithPrincipal(context.Background(), &domain.Principal{UserID: admin.ID, Role: domain.RoleAdmin})
+
+	_, err := service.UpdateUser(asAdmin(), "user_1", application.UserUpdate{Email: "two@example.com", Name: "Renamed", Role: domain.RoleAdmin, Units: domain.UnitsImperial})
+	if !errors.IsConflict(err) {
+		t.Fatalf("UpdateUser() with a taken email error = %v, want conflict", err)
+	}
+	if user, _ := repo.GetByID(context.Background(), "user_1"); user.Role != domain.RoleUser || user.Name != "One" || user.PreferredUnits != "" {
+		t.Errorf("UpdateUser() saved %+v despite the conflict", user)
+	}
+
+	if _, err := service.UpdateUser(asStoredAdmin, admin.ID, application.UserUpdate{Role: domain.RoleUser}); !errors.IsConflict(err) {
+		t.Errorf("UpdateUser() demoting self error = %v, want conflict", err)
+	}
+	if _, err := service.UpdateUser(asAdmin(), "user_1", application.UserUpdate{Role: "superuser"}); !errors.IsValidation(err) {
+		t.Errorf("UpdateUser() with an unknown role error = %v, want validation error", err)
+	}
+	if _, err := service.UpdateUser(asUser("user_1"), "user_1", application.UserUpdate{Units: "kelvin"}); !errors.IsValidation(err) {
+		t.Errorf("UpdateUser() with unknown units error = %v, want validation error", err)
+	}
+
+	user, err := service.UpdateUser(asUser("user_1"), "user_1", application.UserUpdate{Units: domain.UnitsImperial})
+	if err != nil || user.PreferredUnits != domain.UnitsImperial {
+		t.Errorf("UpdateUser() units = %v, %v, want imperial", user, err)
+	}
+}
@@ -2,52 +2,380 @@ package application
 
 import (
 	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/leinonen/hexagonal-architecture-go/internal/domain"
 	"github.com/leinonen/hexagonal-architecture-go/internal/errors"
 	"github.com/leinonen/hexagonal-architecture-go/internal/ports"
 )
 
+const (
+	DefaultPlaceResults = 5
+	MaxPlaceResults     = 5
+
+	// MaxBatchSize bounds how many places one GetWeatherMany call may ask for.
+	MaxBatchSize = 50
+	// BatchConcurrency is how many lookups of a batch run at once.
+	BatchConcurrency = 8
+
+	// MaxHistoryPeriod bounds how far back one history request may reach.
+	MaxHistoryPeriod = 90 * 24 * time.Hour
+)
+
+// WeatherResult is the outcome of one lookup in a batch: either Weather or
+// Err is set.
+type WeatherResult struct {
+	Query   domain.WeatherQuery
+	Weather *domain.Weather
+	Err     error
+}
+
+// LocationWeather is the current weather at one of a user's saved
+// locations: either Weather or Err is set.
+type LocationWeather struct {
+	Location *domain.Location
+	Weather  *domain.Weather
+	Err      error
+}
+
 type WeatherService struct {
 	weatherClient ports.WeatherService
+	geocoder      ports.Geocoder
 	userRepo      ports.UserRepository
+	locationRepo  ports.LocationRepository
+	observations  ports.ObservationRepository
+}
+
+// WeatherServiceOption adds an optional dependency. Without one, the
+// features that need it are unavailable.
+type WeatherServiceOption func(*WeatherService)
+
+// WithGeocoder enables place search.
+func WithGeocoder(geocoder ports.Geocoder) WeatherServiceOption {
+	return func(s *WeatherService) {
+		s.geocoder = geocoder
+	}
+}
+
+// WithLocations enables the dashboard and primary location lookups.
+func WithLocations(locationRepo ports.LocationRepository) WeatherServiceOption {
+	return func(s *WeatherService) {
+		s.locationRepo = locationRepo
+	}
+}
+
+// WithObservations enables weather history.
+func WithObservations(observations ports.ObservationRepository) WeatherServiceOption {
+	return func(s *WeatherService) {
+		s.observations = observations
+	}
 }
 
-func NewWeatherService(weatherClient ports.WeatherService, userRepo ports.UserRepository) *WeatherService {
-	return &WeatherService{
+func NewWeatherService(weatherClient ports.WeatherService, userRepo ports.UserRepository, opts ...WeatherServiceOption) *WeatherService {
+	service := &WeatherService{
 		weatherClient: weatherClient,
 		userRepo:      userRepo,
 	}
+
+	for _, opt := range opts {
+		opt(service)
+	}
+
+	return service
 }
 
-func (s *WeatherService) GetWeatherForUser(ctx context.Context, userID, city string) (*domain.Weather, error) {
+// GetWeatherForUser reports in the requested units, falling back to the
+// user's preferred units and then metric. A query naming no place looks up
+// the user's primary location.
+func (s *WeatherService) GetWeatherForUser(ctx context.Context, userID string, query domain.WeatherQuery, units domain.Units) (*domain.Weather, error) {
 	if userID == "" {
 		return nil, errors.NewValidationError("user ID is required")
 	}
-	if city == "" {
-		return nil, errors.NewValidationError("city is required")
+	if err := authorizeUser(ctx, userID); err != nil {
+		return nil, err
 	}
 
-	_, err := s.userRepo.GetByID(ctx, userID)
+	user, err := s.userRepo.GetByID(ctx, userID)
 	if err != nil {
-		if errors.IsNotFound(err) {
-			return nil, errors.NewUnauthorizedError("user not found")
+		return nil, err
+	}
+
+	if query.IsZero() {
+		if query, err = s.primaryLocation(ctx, userID); err != nil {
+			return nil, err
 		}
+	}
+	if err := query.Validate(); err != nil {
+		return nil, errors.NewValidationError(err.Error())
+	}
+
+	if units == "" {
+		units = user.PreferredUnits
+	}
+	units, err = resolveUnits(units)
+	if err != nil {
 		return nil, err
 	}
 
-	weather, err := s.weatherClient.GetWeather(ctx, city)
+	weather, err := s.weatherClient.GetWeather(ctx, query)
 	if err != nil {
 		return nil, err
 	}
 
-	return weather, nil
+	return weather.InUnits(units), nil
 }
 
-func (s *WeatherService) GetWeather(ctx context.Context, city string) (*domain.Weather, error) {
-	if city == "" {
-		return nil, errors.NewValidationError("city is required")
+func (s *WeatherService) GetWeather(ctx context.Context, query domain.WeatherQuery, units domain.Units) (*domain.Weather, error) {
+	if err := query.Validate(); err != nil {
+		return nil, errors.NewValidationError(err.Error())
+	}
+	units, err := resolveUnits(units)
+	if err != nil {
+		return nil, err
+	}
+
+	weather, err := s.weatherClient.GetWeather(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return weather.InUnits(units), nil
+}
+
+// GetWeatherMany looks up several places concurrently and reports each
+// outcome separately, in the order asked, so one unknown city does not fail
+// the others. Lookups that have not finished when ctx ends fail with an
+// external service error.
+func (s *WeatherService) GetWeatherMany(ctx context.Context, queries []domain.WeatherQuery, units domain.Units) ([]WeatherResult, error) {
+	if len(queries) == 0 {
+		return nil, errors.NewValidationError("at least one place is required")
+	}
+	if len(queries) > MaxBatchSize {
+		return nil, errors.NewValidationError(fmt.Sprintf("at most %d places may be requested at once", MaxBatchSize))
+	}
+	units, err := resolveUnits(units)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]WeatherResult, len(queries))
+	slots := make(chan struct{}, BatchConcurrency)
+	var wg sync.WaitGroup
+
+	for i, query := range queries {
+		results[i].Query = query
+		if err := query.Validate(); err != nil {
+			results[i].Err = errors.NewValidationError(err.Error())
+			continue
+		}
+
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			results[i].Err = deadlineError(ctx)
+			continue
+		}
+
+		wg.Add(1)
+		go func(result *WeatherResult) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			weather, err := s.weatherClient.GetWeather(ctx, result.Query)
+			switch {
+			case ctx.Err() != nil && err != nil:
+				result.Err = deadlineError(ctx)
+			case err != nil:
+				result.Err = err
+			default:
+				result.Weather = weather.InUnits(units)
+			}
+		}(&results[i])
+	}
+
+	wg.Wait()
+	return results, nil
+}
+
+// GetDashboard looks up the current weather at every location the user has
+// saved, in the user's preferred units unless others are asked for. Like
+// GetWeatherMany it reports a failed lookup on its entry instead of failing
+// the dashboard.
+func (s *WeatherService) GetDashboard(ctx context.Context, userID string, units domain.Units) ([]LocationWeather, error) {
+	if userID == "" {
+		return nil, errors.NewValidationError("user ID is required")
+	}
+	if err := authorizeUser(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if units == "" {
+		units = user.PreferredUnits
+	}
+	units, err = resolveUnits(units)
+	if err != nil {
+		return nil, err
 	}
 
-	return s.weatherClient.GetWeather(ctx, city)
+	var locations []*domain.Location
+	if s.locationRepo != nil {
+		if locations, err = s.locationRepo.ListByUser(ctx, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	dashboard := make([]LocationWeather, len(locations))
+	if len(locations) == 0 {
+		return dashboard, nil
+	}
+
+	queries := make([]domain.WeatherQuery, len(locations))
+	for i, location := range locations {
+		queries[i] = location.Query
+	}
+	results, err := s.GetWeatherMany(ctx, queries, units)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, result := range results {
+		dashboard[i] = LocationWeather{Location: locations[i], Weather: result.Weather, Err: result.Err}
+	}
+	return dashboard, nil
+}
+
+func deadlineError(ctx context.Context) error {
+	return errors.NewExternalServiceError(fmt.Sprintf("weather lookup did not finish in time: %v", ctx.Err()))
+}
+
+func (s *WeatherService) GetForecast(ctx context.Context, query domain.WeatherQuery, days int, units domain.Units) (*domain.Forecast, error) {
+	if err := query.Validate(); err != nil {
+		return nil, errors.NewValidationError(err.Error())
+	}
+	units, err := resolveUnits(units)
+	if err != nil {
+		return nil, err
+	}
+	if days == 0 {
+		days = domain.DefaultForecastDays
+	}
+	if days < 1 || days > domain.MaxForecastDays {
+		return nil, errors.NewValidationError(fmt.Sprintf("days must be between 1 and %d", domain.MaxForecastDays))
+	}
+
+	forecast, err := s.weatherClient.GetForecast(ctx, query, days)
+	if err != nil {
+		return nil, err
+	}
+
+	return forecast.InUnits(units), nil
+}
+
+// GetHistory aggregates the recorded weather at a place into min, max and
+// average per interval. Without from and to it covers the last day, in
+// hourly intervals unless another is given.
+func (s *WeatherService) GetHistory(ctx context.Context, query domain.WeatherQuery, from, to time.Time, interval time.Duration, units domain.Units) (*domain.History, error) {
+	if s.observations == nil {
+		return nil, errors.NewInternalError("weather history is not configured")
+	}
+
+	if err := query.Validate(); err != nil {
+		return nil, errors.NewValidationError(err.Error())
+	}
+	units, err := resolveUnits(units)
+	if err != nil {
+		return nil, err
+	}
+
+	if to.IsZero() {
+		to = time.Now()
+	}
+	if from.IsZero() {
+		from = to.Add(-domain.DefaultHistoryPeriod)
+	}
+	if interval == 0 {
+		interval = domain.DefaultHistoryInterval
+	}
+
+	if !from.Before(to) {
+		return nil, errors.NewValidationError("from must be before to")
+	}
+	if to.Sub(from) > MaxHistoryPeriod {
+		return nil, errors.NewValidationError(fmt.Sprintf("history may span at most %d days", MaxHistoryPeriod/(24*time.Hour)))
+	}
+	if interval < domain.MinHistoryInterval {
+		return nil, errors.NewValidationError(fmt.Sprintf("interval must be at least %v", domain.MinHistoryInterval))
+	}
+	if (to.Sub(from)+interval-1)/interval > domain.MaxHistoryBuckets {
+		return nil, errors.NewValidationError(fmt.Sprintf("history may span at most %d intervals", domain.MaxHistoryBuckets))
+	}
+
+	observations, err := s.observations.List(ctx, query, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	// Name the place as the provider resolved it, however the query named it.
+	place := query
+	if len(observations) > 0 {
+		place = observations[0].Query
+	}
+
+	return domain.NewHistory(place, observations, from, to, interval, units), nil
+}
+
+// SearchPlaces resolves a place name to candidate locations so that callers
+// can pick one and look up its weather by coordinates.
+func (s *WeatherService) SearchPlaces(ctx context.Context, name, country string, limit int) ([]domain.Place, error) {
+	if s.geocoder == nil {
+		return nil, errors.NewInternalError("geocoding is not configured")
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, errors.NewValidationError("place name is required")
+	}
+	if err := (domain.WeatherQuery{City: name, Country: country}).Validate(); err != nil {
+		return nil, errors.NewValidationError(err.Error())
+	}
+	if limit == 0 {
+		limit = DefaultPlaceResults
+	}
+	if limit < 1 || limit > MaxPlaceResults {
+		return nil, errors.NewValidationError(fmt.Sprintf("limit must be between 1 and %d", MaxPlaceResults))
+	}
+
+	return s.geocoder.Geocode(ctx, name, country, limit)
+}
+
+func (s *WeatherService) primaryLocation(ctx context.Context, userID string) (domain.WeatherQuery, error) {
+	if s.locationRepo != nil {
+		locations, err := s.locationRepo.ListByUser(ctx, userID)
+		if err != nil {
+			return domain.WeatherQuery{}, err
+		}
+		for _, location := range locations {
+			if location.Primary {
+				return location.Query, nil
+			}
+		}
+	}
+	return domain.WeatherQuery{}, errors.NewValidationError("city or lat and lon parameters are required when no primary location is saved")
+}
+
+func resolveUnits(units domain.Units) (domain.Units, error) {
+	if units == "" {
+		return domain.UnitsMetric, nil
+	}
+	if !units.Valid() {
+		return "", errors.NewValidationError("units must be metric, imperial or standard")
+	}
+	return units, nil
 }
@@ -0,0 +1,372 @@
+package application_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/leinonen/hexagonal-architecture-go/internal/application"
+	"github.com/leinonen/hexagonal-architecture-go/internal/domain"
+	"github.com/leinonen/hexagonal-architecture-go/internal/errors"
+)
+
+type stubWeatherClient struct{}
+
+func (stubWeatherClient) GetWeather(ctx context.Context, query domain.WeatherQuery) (*domain.Weather, error) {
+	return &domain.Weather{City: query.City, Temperature: 10, Units: domain.UnitsMetric}, nil
+}
+
+func (stubWeatherClient) GetForecast(ctx context.Context, query domain.WeatherQuery, days int) (*domain.Forecast, error) {
+	return &domain.Forecast{City: query.City, Daily: []domain.DailyForecast{{MaxTemperature: 10}}, Units: domain.UnitsMetric}, nil
+}
+
+func TestWeatherService_Units(t *testing.T) {
+	users := testUsers()
+	users[0].PreferredUnits = domain.UnitsImperial
+	repo := newUserRepository(t, users...)
+	service := application.NewWeatherService(stubWeatherClient{}, repo)
+	london := domain.CityQuery("London")
+
+	tests := []struct {
+		name            string
+		call            func() (*domain.Weather, error)
+		wantUnits       domain.Units
+		wantTemperature float64
+	}{
+		{
+			name:            "default",
+			call:            func() (*domain.Weather, error) { return service.GetWeather(context.Background(), london, "") },
+			wantUnits:       domain.UnitsMetric,
+			wantTemperature: 10,
+		},
+		{
+			name: "requested",
+			call: func() (*domain.Weather, error) {
+				return service.GetWeather(context.Background(), london, domain.UnitsStandard)
+			},
+			wantUnits:       domain.UnitsStandard,
+			wantTemperature: 283.15,
+		},
+		{
+			name: "user preference",
+			call: func() (*domain.Weather, error) {
+				return service.GetWeatherForUser(asUser("user_1"), "user_1", london, "")
+			},
+			wantUnits:       domain.UnitsImperial,
+			wantTemperature: 50,
+		},
+		{
+			name: "request overrides preference",
+			call: func() (*domain.Weather, error) {
+				return service.GetWeatherForUser(asUser("user_1"), "user_1", london, domain.UnitsMetric)
+			},
+			wantUnits:       domain.UnitsMetric,
+			wantTemperature: 10,
+		},
+		{
+			name: "user without preference",
+			call: func() (*domain.Weather, error) {
+				return service.GetWeatherForUser(asUser("user_2"), "user_2", london, "")
+			},
+			wantUnits:       domain.UnitsMetric,
+			wantTemperature: 10,
+		},
+		{
+			name: "admin for a user",
+			call: func() (*domain.Weather, error) {
+				return service.GetWeatherForUser(asAdmin(), "user_1", london, "")
+			},
+			wantUnits:       domain.UnitsImperial,
+			wantTemperature: 50,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			weather, err := tt.call()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if weather.Units != tt.wantUnits || weather.Temperature != tt.wantTemperature {
+				t.Errorf("got %v %v, want %v %v", weather.Temperature, weather.Units, tt.wantTemperature, tt.wantUnits)
+			}
+		})
+	}
+
+	if _, err := service.GetWeatherForUser(asUser("user_2"), "user_1", london, ""); !errors.IsForbidden(err) {
+		t.Errorf("GetWeatherForUser() for another user error = %v, want forbidden", err)
+	}
+	if _, err := service.GetWeather(context.Background(), london, "kelvin"); !errors.IsValidation(err) {
+		t.Errorf("GetWeather() unknown units error = %v, want validation error", err)
+	}
+
+	forecast, err := service.GetForecast(context.Background(), london, 1, domain.UnitsImperial)
+	if err != nil || forecast.Daily[0].MaxTemperature != 50 || forecast.Units != domain.UnitsImperial {
+		t.Errorf("GetForecast() = %+v, %v, want imperial", forecast, err)
+	}
+}
+
+func TestWeatherService_SearchPlacesWithoutGeocoder(t *testing.T) {
+	service := application.NewWeatherService(stubWeatherClient{}, newUserRepository(t))
+
+	if _, err := service.SearchPlaces(context.Background(), "Paris", "", 0); !errors.IsInternal(err) {
+		t.Errorf("SearchPlaces() error = %v, want internal error", err)
+	}
+}
+
+// slowWeatherClient takes delay per lookup and records how many lookups ran
+// at once.
+type slowWeatherClient struct {
+	delay time.Duration
+
+	mu       sync.Mutex
+	running  int
+	maxSeen  int
+	requests int
+}
+
+func (c *slowWeatherClient) GetWeather(ctx context.Context, query domain.WeatherQuery) (*domain.Weather, error) {
+	c.mu.Lock()
+	c.running++
+	c.requests++
+	c.maxSeen = max(c.maxSeen, c.running)
+	c.mu.Unlock()
+
+	defer func() {
+		c.mu.Lock()
+		c.running--
+		c.mu.Unlock()
+	}()
+
+	select {
+	case <-time.After(c.delay):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	if query.City == "Atlantis" {
+		return nil, errors.NewNotFoundError("city not found")
+	}
+	return &domain.Weather{City: query.City, Temperature: 10, Units: domain.UnitsMetric}, nil
+}
+
+func (c *slowWeatherClient) GetForecast(ctx context.Context, query domain.WeatherQuery, days int) (*domain.Forecast, error) {
+	return nil, errors.NewInternalError("not implemented")
+}
+
+func TestWeatherService_GetWeatherMany(t *testing.T) {
+	client := &slowWeatherClient{delay: 10 * time.Millisecond}
+	service := application.NewWeatherService(client, newUserRepository(t))
+
+	queries := []domain.WeatherQuery{domain.CityQuery("Atlantis"), {City: "Paris", Country: "France"}}
+	for i := 0; i < 20; i++ {
+		queries = append(queries, domain.CityQuery(fmt.Sprintf("City %d", i)))
+	}
+
+	results, err := service.GetWeatherMany(context.Background(), queries, domain.UnitsImperial)
+	if err != nil {
+		t.Fatalf("GetWeatherMany() unexpected error: %v", err)
+	}
+
+	if len(results) != len(queries) {
+		t.Fatalf("GetWeatherMany() returned %d results, want %d", len(results), len(queries))
+	}
+	if !errors.IsNotFound(results[0].Err) {
+		t.Errorf("result for Atlantis error = %v, want not found", results[0].Err)
+	}
+	if !errors.IsValidation(results[1].Err) {
+		t.Errorf("result for an invalid country error = %v, want validation error", results[1].Err)
+	}
+	for i, result := range results[2:] {
+		if result.Err != nil || result.Weather.City != queries[i+2].City || result.Weather.Temperature != 50 {
+			t.Errorf("result %d = %+v, want %s in imperial units", i+2, result, queries[i+2].City)
+		}
+	}
+
+	if client.requests != 21 {
+		t.Errorf("client called %d times, want 21 (invalid queries are not looked up)", client.requests)
+	}
+	if client.maxSeen > application.BatchConcurrency {
+		t.Errorf("%d lookups ran at once, want at most %d", client.maxSeen, application.BatchConcurrency)
+	}
+}
+
+func TestWeatherService_GetWeatherManyDeadline(t *testing.T) {
+	client := &slowWeatherClient{delay: time.Second}
+	service := application.NewWeatherService(client, newUserRepository(t))
+
+	queries := make([]domain.WeatherQuery, application.BatchConcurrency+2)
+	for i := range queries {
+		queries[i] = domain.CityQuery(fmt.Sprintf("City %d", i))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	results, err := service.GetWeatherMany(ctx, queries, "")
+	if err != nil {
+		t.Fatalf("GetWeatherMany() unexpected error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("GetWeatherMany() took %v, want it to stop at the deadline", elapsed)
+	}
+
+	for i, result := range results {
+		if !errors.IsExternalService(result.Err) {
+			t.Errorf("result %d error = %v, want external service error", i, result.Err)
+		}
+	}
+}
+
+func TestWeatherService_GetWeatherManyValidation(t *testing.T) {
+	service := application.NewWeatherService(stubWeatherClient{}, newUserRepository(t))
+
+	tooMany := make([]domain.WeatherQuery, application.MaxBatchSize+1)
+	for i := range tooMany {
+		tooMany[i] = domain.CityQuery("London")
+	}
+
+	for name, queries := range map[string][]domain.WeatherQuery{"empty": nil, "too many": tooMany} {
+		if _, err := service.GetWeatherMany(context.Background(), queries, ""); !errors.IsValidation(err) {
+			t.Errorf("GetWeatherMany(%s) error = %v, want validation error", name, err)
+		}
+	}
+	if _, err := service.GetWeatherMany(context.Background(), []domain.WeatherQuery{domain.CityQuery("London")}, "kelvin"); !errors.IsValidation(err) {
+		t.Errorf("GetWeatherMany() with unknown units error = %v, want validation error", err)
+	}
+}
+
+type mockObservationRepository struct {
+	observations []*domain.Observation
+	from, to     time.Time
+}
+
+func (m *mockObservationRepository) Record(ctx context.Context, observation *domain.Observation) error {
+	m.observations = append(m.observations, observation)
+	return nil
+}
+
+func (m *mockObservationRepository) List(ctx context.Context, query domain.WeatherQuery, from, to time.Time) ([]*domain.Observation, error) {
+	m.from, m.to = from, to
+	return m.observations, nil
+}
+
+func TestWeatherService_GetHistory(t *testing.T) {
+	repo := &mockObservationRepository{}
+	service := application.NewWeatherService(stubWeatherClient{}, newUserRepository(t), application.WithObservations(repo))
+	ctx := context.Background()
+	london := domain.CityQuery("London")
+
+	now := time.Now()
+	repo.Record(ctx, &domain.Observation{Query: london, Weather: domain.Weather{Temperature: 10, ObservedAt: now.Add(-30 * time.Minute)}})
+
+	history, err := service.GetHistory(ctx, london, time.Time{}, time.Time{}, 0, "")
+	if err != nil {
+		t.Fatalf("GetHistory() unexpected error: %v", err)
+	}
+	if period := repo.to.Sub(repo.from); period != domain.DefaultHistoryPeriod || history.Interval != domain.DefaultHistoryInterval || history.Units != domain.UnitsMetric {
+		t.Errorf("GetHistory() defaults = %v over %v in %v, want hourly over the last day in metric", history.Interval, period, history.Units)
+	}
+	if len(history.Buckets) != 1 || history.Buckets[0].Temperature.Avg != 10 {
+		t.Errorf("GetHistory() buckets = %+v, want the one observation", history.Buckets)
+	}
+
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		query    domain.WeatherQuery
+		from, to time.Time
+		interval time.Duration
+		units    domain.Units
+	}{
+		{name: "invalid place", query: domain.CityQuery("<script>"), from: from, to: from.Add(time.Hour)},
+		{name: "from after to", query: london, from: from.Add(time.Hour), to: from},
+		{name: "too long", query: london, from: from, to: from.Add(application.MaxHistoryPeriod + time.Hour), interval: 24 * time.Hour},
+		{name: "interval too short", query: london, from: from, to: from.Add(time.Hour), interval: time.Second},
+		{name: "too many intervals", query: london, from: from, to: from.Add(24 * time.Hour), interval: time.Minute},
+		{name: "invalid units", query: london, from: from, to: from.Add(time.Hour), units: "kelvin"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := service.GetHistory(ctx, tt.query, tt.from, tt.to, tt.interval, tt.units); !errors.IsValidation(err) {
+				t.Errorf("GetHistory() error = %v, want validation error", err)
+			}
+		})
+	}
+}
+
+func TestWeatherService_GetHistoryWithoutRepository(t *testing.T) {
+	service := application.NewWeatherService(stubWeatherClient{}, newUserRepository(t))
+
+	if _, err := service.GetHistory(context.Background(), domain.CityQuery("London"), time.Time{}, time.Time{}, 0, ""); !errors.IsInternal(err) {
+		t.Errorf("GetHistory() error = %v, want internal error", err)
+	}
+}
+
+func TestWeatherService_PrimaryLocation(t *testing.T) {
+	locations, locationRepo, userRepo := newLocationFixture(t, testUsers()...)
+	service := application.NewWeatherService(stubWeatherClient{}, userRepo, application.WithLocations(locationRepo))
+
+	if _, err := service.GetWeatherForUser(asUser("user_1"), "user_1", domain.WeatherQuery{}, ""); !errors.IsValidation(err) {
+		t.Errorf("GetWeatherForUser() without a place or primary location error = %v, want validation error", err)
+	}
+
+	locations.CreateLocation(asUser("user_1"), "user_1", "Home", domain.CityQuery("Oslo"), false)
+
+	weather, err := service.GetWeatherForUser(asUser("user_1"), "user_1", domain.WeatherQuery{}, "")
+	if err != nil {
+		t.Fatalf("GetWeatherForUser() unexpected error: %v", err)
+	}
+	if weather.City != "Oslo" {
+		t.Errorf("GetWeatherForUser() city = %v, want the primary location Oslo", weather.City)
+	}
+
+	weather, _ = service.GetWeatherForUser(asUser("user_1"), "user_1", domain.CityQuery("London"), "")
+	if weather.City != "London" {
+		t.Errorf("GetWeatherForUser() city = %v, want the requested London", weather.City)
+	}
+}
+
+func TestWeatherService_GetDashboard(t *testing.T) {
+	users := testUsers()
+	users[0].PreferredUnits = domain.UnitsImperial
+	locations, locationRepo, userRepo := newLocationFixture(t, users...)
+	service := application.NewWeatherService(&slowWeatherClient{}, userRepo, application.WithLocations(locationRepo))
+	ctx := asUser("user_1")
+
+	dashboard, err := service.GetDashboard(ctx, "user_1", "")
+	if err != nil || len(dashboard) != 0 {
+		t.Fatalf("GetDashboard() without locations = %v, %v, want an empty dashboard", dashboard, err)
+	}
+
+	locations.CreateLocation(ctx, "user_1", "Home", domain.CityQuery("London"), false)
+	locations.CreateLocation(ctx, "user_1", "Lost", domain.CityQuery("Atlantis"), false)
+	locations.CreateLocation(ctx, "user_1", "Work", domain.CityQuery("Paris"), false)
+
+	dashboard, err = service.GetDashboard(ctx, "user_1", "")
+	if err != nil {
+		t.Fatalf("GetDashboard() unexpected error: %v", err)
+	}
+	if len(dashboard) != 3 {
+		t.Fatalf("GetDashboard() returned %d entries, want 3", len(dashboard))
+	}
+	for _, i := range []int{0, 2} {
+		entry := dashboard[i]
+		if entry.Err != nil || entry.Weather.City != entry.Location.Query.City || entry.Weather.Units != domain.UnitsImperial {
+			t.Errorf("entry %d = %+v, want %s in the user's imperial units", i, entry, entry.Location.Name)
+		}
+	}
+	if dashboard[1].Location.Name != "Lost" || !errors.IsNotFound(dashboard[1].Err) {
+		t.Errorf("entry for Atlantis = %+v, want a not found error", dashboard[1])
+	}
+
+	if _, err := service.GetDashboard(asUser("user_2"), "user_1", ""); !errors.IsForbidden(err) {
+		t.Errorf("GetDashboard() for another user error = %v, want forbidden", err)
+	}
+	if _, err := service.GetDashboard(asAdmin(), "user_1", domain.UnitsMetric); err != nil {
+		t.Errorf("GetDashboard() as admin unexpected error: %v", err)
+	}
+}
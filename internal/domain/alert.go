@@ -0,0 +1,165 @@
+package domain
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+const MaxAlertRulesPerUser = 20
+
+// AlertMetric names the weather reading an alert rule watches.
+type AlertMetric string
+
+const (
+	MetricTemperature   AlertMetric = "temperature"
+	MetricFeelsLike     AlertMetric = "feels_like"
+	MetricHumidity      AlertMetric = "humidity"
+	MetricWindSpeed     AlertMetric = "wind_speed"
+	MetricWindGust      AlertMetric = "wind_gust"
+	MetricPrecipitation AlertMetric = "precipitation"
+	MetricPressure      AlertMetric = "pressure"
+)
+
+func (m AlertMetric) Valid() bool {
+	switch m {
+	case MetricTemperature, MetricFeelsLike, MetricHumidity, MetricWindSpeed, MetricWindGust, MetricPrecipitation, MetricPressure:
+		return true
+	}
+	return false
+}
+
+// Value reads the metric from weather, in whatever units weather is in.
+func (m AlertMetric) Value(weather *Weather) float64 {
+	switch m {
+	case MetricTemperature:
+		return weather.Temperature
+	case MetricFeelsLike:
+		return weather.FeelsLike
+	case MetricHumidity:
+		return float64(weather.Humidity)
+	case MetricWindSpeed:
+		return weather.WindSpeed
+	case MetricWindGust:
+		return weather.WindGust
+	case MetricPrecipitation:
+		return weather.Precipitation
+	case MetricPressure:
+		return weather.Pressure
+	}
+	return math.NaN()
+}
+
+// Comparator says how a reading is compared with a rule's threshold.
+type Comparator string
+
+const (
+	ComparatorBelow        Comparator = "lt"
+	ComparatorBelowOrEqual Comparator = "lte"
+	ComparatorAbove        Comparator = "gt"
+	ComparatorAboveOrEqual Comparator = "gte"
+)
+
+func (c Comparator) Valid() bool {
+	return c == ComparatorBelow || c == ComparatorBelowOrEqual || c == ComparatorAbove || c == ComparatorAboveOrEqual
+}
+
+func (c Comparator) Compare(value, threshold float64) bool {
+	switch c {
+	case ComparatorBelow:
+		return value < threshold
+	case ComparatorBelowOrEqual:
+		return value <= threshold
+	case ComparatorAbove:
+		return value > threshold
+	case ComparatorAboveOrEqual:
+		return value >= threshold
+	}
+	return false
+}
+
+// AlertRule asks for a notification when a reading at a place crosses a
+// threshold, such as the temperature in London dropping below 0 °C. The
+// threshold is in the rule's units. A rule fires when its condition starts to
+// hold and not again until the condition has cleared, so a cold week is one
+// alert rather than one per check.
+type AlertRule struct {
+	ID         string
+	UserID     string
+	Query      WeatherQuery
+	Metric     AlertMetric
+	Comparator Comparator
+	Threshold  float64
+	Units      Units
+	// Triggered is set while the condition holds; TriggeredAt is when it
+	// last started to.
+	Triggered   bool
+	TriggeredAt time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// NewAlertRule validates a rule. Units default to metric.
+func NewAlertRule(userID string, query WeatherQuery, metric AlertMetric, comparator Comparator, threshold float64, units Units) (*AlertRule, error) {
+	if userID == "" {
+		return nil, errors.New("user ID is required")
+	}
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+	if !metric.Valid() {
+		return nil, errors.New("metric must be temperature, feels_like, humidity, wind_speed, wind_gust, precipitation or pressure")
+	}
+	if !comparator.Valid() {
+		return nil, errors.New("comparator must be lt, lte, gt or gte")
+	}
+	if math.IsNaN(threshold) || math.IsInf(threshold, 0) {
+		return nil, errors.New("threshold must be a number")
+	}
+	if units == "" {
+		units = UnitsMetric
+	}
+	if !units.Valid() {
+		return nil, errors.New("units must be metric, imperial or standard")
+	}
+
+	now := time.Now()
+	return &AlertRule{
+		UserID:     userID,
+		Query:      query,
+		Metric:     metric,
+		Comparator: comparator,
+		Threshold:  threshold,
+		Units:      units,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}, nil
+}
+
+// Check reads the rule's metric from metric weather, converted to the
+// rule's units, and reports whether the condition holds.
+func (r *AlertRule) Check(weather *Weather) (float64, bool) {
+	value := r.Metric.Value(weather.InUnits(r.Units))
+	return value, r.Comparator.Compare(value, r.Threshold)
+}
+
+// Observe records a reading and reports whether it should be notified: the
+// condition holds now but did not at the previous reading.
+func (r *AlertRule) Observe(weather *Weather, at time.Time) (float64, bool) {
+	value, holds := r.Check(weather)
+	fire := holds && !r.Triggered
+
+	r.Triggered = holds
+	if fire {
+		r.TriggeredAt = at
+	}
+	return value, fire
+}
+
+// Alert tells a user that a rule's condition started to hold.
+type Alert struct {
+	Rule        AlertRule
+	Weather     *Weather
+	Value       float64
+	TriggeredAt time.Time
+}
@@ -0,0 +1,75 @@
+package domain_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/leinonen/hexagonal-architecture-go/internal/domain"
+)
+
+func TestNewAlertRule(t *testing.T) {
+	london := domain.CityQuery("London")
+
+	tests := []struct {
+		name       string
+		userID     string
+		query      domain.WeatherQuery
+		metric     domain.AlertMetric
+		comparator domain.Comparator
+		threshold  float64
+		units      domain.Units
+		wantErr    bool
+	}{
+		{name: "freezing", userID: "user_1", query: london, metric: domain.MetricTemperature, comparator: domain.ComparatorBelow},
+		{name: "imperial", userID: "user_1", query: london, metric: domain.MetricWindSpeed, comparator: domain.ComparatorAbove, threshold: 40, units: domain.UnitsImperial},
+		{name: "missing user", query: london, metric: domain.MetricTemperature, comparator: domain.ComparatorBelow, wantErr: true},
+		{name: "missing place", userID: "user_1", metric: domain.MetricTemperature, comparator: domain.ComparatorBelow, wantErr: true},
+		{name: "unknown metric", userID: "user_1", query: london, metric: "snow_depth", comparator: domain.ComparatorBelow, wantErr: true},
+		{name: "unknown comparator", userID: "user_1", query: london, metric: domain.MetricTemperature, comparator: "<", wantErr: true},
+		{name: "invalid threshold", userID: "user_1", query: london, metric: domain.MetricTemperature, comparator: domain.ComparatorBelow, threshold: math.NaN(), wantErr: true},
+		{name: "invalid units", userID: "user_1", query: london, metric: domain.MetricTemperature, comparator: domain.ComparatorBelow, units: "kelvin", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := domain.NewAlertRule(tt.userID, tt.query, tt.metric, tt.comparator, tt.threshold, tt.units)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewAlertRule() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && tt.units == "" && rule.Units != domain.UnitsMetric {
+				t.Errorf("NewAlertRule() units = %v, want metric by default", rule.Units)
+			}
+		})
+	}
+}
+
+func TestAlertRule_Observe(t *testing.T) {
+	rule, _ := domain.NewAlertRule("user_1", domain.CityQuery("London"), domain.MetricTemperature, domain.ComparatorBelow, 32, domain.UnitsImperial)
+	start := time.Now()
+
+	readings := []struct {
+		celsius       float64
+		wantValue     float64
+		wantFire      bool
+		wantTriggered bool
+	}{
+		{celsius: 5, wantValue: 41},
+		{celsius: -5, wantValue: 23, wantFire: true, wantTriggered: true},
+		{celsius: -10, wantValue: 14, wantTriggered: true},
+		{celsius: 0, wantValue: 32},
+		{celsius: -1, wantValue: 30.2, wantFire: true, wantTriggered: true},
+	}
+
+	for i, reading := range readings {
+		at := start.Add(time.Duration(i) * time.Hour)
+		value, fire := rule.Observe(&domain.Weather{Temperature: reading.celsius, Units: domain.UnitsMetric}, at)
+
+		if math.Abs(value-reading.wantValue) > 1e-9 || fire != reading.wantFire || rule.Triggered != reading.wantTriggered {
+			t.Errorf("reading %d at %v °C = %v, fire %v, triggered %v, want %v, %v, %v", i, reading.celsius, value, fire, rule.Triggered, reading.wantValue, reading.wantFire, reading.wantTriggered)
+		}
+		if fire && !rule.TriggeredAt.Equal(at) {
+			t.Errorf("reading %d TriggeredAt = %v, want %v", i, rule.TriggeredAt, at)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+type APIKey struct {
+	ID         string
+	UserID     string
+	Name       string
+	Prefix     string
+	Hash       string
+	CreatedAt  time.Time
+	LastUsedAt time.Time
+}
+
+func NewAPIKey(userID, name, prefix, hash string) (*APIKey, error) {
+	if userID == "" {
+		return nil, errors.New("user ID is required")
+	}
+	if name == "" {
+		return nil, errors.New("name is required")
+	}
+	if hash == "" {
+		return nil, errors.New("hash is required")
+	}
+
+	return &APIKey{
+		UserID:    userID,
+		Name:      name,
+		Prefix:    prefix,
+		Hash:      hash,
+		CreatedAt: time.Now(),
+	}, nil
+}
@@ -0,0 +1,12 @@
+package domain
+
+import "time"
+
+// AuthTokens is what a successful login hands back to the client.
+type AuthTokens struct {
+	AccessToken      string
+	ExpiresAt        time.Time
+	RefreshToken     string
+	RefreshExpiresAt time.Time
+	SessionID        string
+}
@@ -0,0 +1,20 @@
+package domain
+
+// Condition classifies the weather independently of the provider's own codes
+// and wording, which stay available in Weather.Description.
+type Condition string
+
+const (
+	ConditionClear        Condition = "clear"
+	ConditionPartlyCloudy Condition = "partly_cloudy"
+	ConditionCloudy       Condition = "cloudy"
+	// ConditionFog covers fog, mist, haze, smoke and dust.
+	ConditionFog     Condition = "fog"
+	ConditionDrizzle Condition = "drizzle"
+	ConditionRain    Condition = "rain"
+	// ConditionSleet covers sleet, freezing rain and ice pellets.
+	ConditionSleet        Condition = "sleet"
+	ConditionSnow         Condition = "snow"
+	ConditionThunderstorm Condition = "thunderstorm"
+	ConditionUnknown      Condition = "unknown"
+)
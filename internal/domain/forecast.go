@@ -0,0 +1,84 @@
+package domain
+
+import "time"
+
+const (
+	DefaultForecastDays = 3
+	MaxForecastDays     = 5
+)
+
+type Forecast struct {
+	City        string
+	Country     string
+	Coordinates Coordinates
+	Provider    string
+	Units       Units
+	Hourly      []HourlyForecast
+	Daily       []DailyForecast
+
+	FetchedAt time.Time
+	ExpiresAt time.Time
+}
+
+type HourlyForecast struct {
+	Time        time.Time
+	Temperature float64
+	Description string
+	Humidity    int
+	WindSpeed   float64
+	// PrecipitationChance is the probability of precipitation, from 0 to 1.
+	PrecipitationChance float64
+}
+
+type DailyForecast struct {
+	// Date is midnight of the forecast day in the location's time zone.
+	Date                time.Time
+	MinTemperature      float64
+	MaxTemperature      float64
+	Description         string
+	Humidity            int
+	MaxWindSpeed        float64
+	PrecipitationChance float64
+}
+
+// SummarizeDays groups hourly entries by calendar day in loc and derives a
+// daily summary for each, for providers that only publish hourly data. The
+// description is taken from the entry closest to midday.
+func SummarizeDays(hourly []HourlyForecast, loc *time.Location) []DailyForecast {
+	var days []DailyForecast
+	var humiditySum, count int
+	var middayDistance time.Duration
+
+	for _, hour := range hourly {
+		local := hour.Time.In(loc)
+		date := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+		distance := local.Sub(date.Add(12 * time.Hour)).Abs()
+
+		if len(days) == 0 || !days[len(days)-1].Date.Equal(date) {
+			days = append(days, DailyForecast{
+				Date:           date,
+				MinTemperature: hour.Temperature,
+				MaxTemperature: hour.Temperature,
+				Description:    hour.Description,
+			})
+			humiditySum, count = 0, 0
+			middayDistance = distance
+		}
+
+		day := &days[len(days)-1]
+		day.MinTemperature = min(day.MinTemperature, hour.Temperature)
+		day.MaxTemperature = max(day.MaxTemperature, hour.Temperature)
+		day.MaxWindSpeed = max(day.MaxWindSpeed, hour.WindSpeed)
+		day.PrecipitationChance = max(day.PrecipitationChance, hour.PrecipitationChance)
+		if distance < middayDistance {
+			day.Description = hour.Description
+			middayDistance = distance
+		}
+
+		humiditySum += hour.Humidity
+		count++
+		day.Humidity = humiditySum / count
+	}
+
+	return days
+}
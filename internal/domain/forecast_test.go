@@ -0,0 +1,52 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/leinonen/hexagonal-architecture-go/internal/domain"
+)
+
+func TestSummarizeDays(t *testing.T) {
+	loc := time.FixedZone("UTC+2", 2*60*60)
+	start := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	var hourly []domain.HourlyForecast
+	for i := 0; i < 10; i++ {
+		hourly = append(hourly, domain.HourlyForecast{
+			Time:                start.Add(time.Duration(i) * 3 * time.Hour),
+			Temperature:         float64(10 + i),
+			Description:         "hour " + string(rune('0'+i)),
+			Humidity:            50 + i*2,
+			WindSpeed:           float64(i),
+			PrecipitationChance: float64(i) / 10,
+		})
+	}
+
+	days := domain.SummarizeDays(hourly, loc)
+
+	// 00:00 UTC is 02:00 local, so entries 0-7 (02:00-23:00) fall on June 1
+	// and entries 8-9 on June 2.
+	if len(days) != 2 {
+		t.Fatalf("SummarizeDays() returned %d days, want 2", len(days))
+	}
+
+	first := days[0]
+	if !first.Date.Equal(time.Date(2024, 6, 1, 0, 0, 0, 0, loc)) {
+		t.Errorf("day 1 date = %v, want June 1 local midnight", first.Date)
+	}
+	if first.MinTemperature != 10 || first.MaxTemperature != 17 {
+		t.Errorf("day 1 min/max = %v/%v, want 10/17", first.MinTemperature, first.MaxTemperature)
+	}
+	if first.Description != "hour 3" {
+		t.Errorf("day 1 description = %v, want the entry at 11:00 local", first.Description)
+	}
+	if first.Humidity != 57 || first.MaxWindSpeed != 7 {
+		t.Errorf("day 1 humidity/wind = %v/%v, want 57/7", first.Humidity, first.MaxWindSpeed)
+	}
+
+	second := days[1]
+	if second.MinTemperature != 18 || second.MaxTemperature != 19 || second.PrecipitationChance != 0.9 {
+		t.Errorf("day 2 = %+v, want min 18, max 19, precipitation 0.9", second)
+	}
+}
@@ -0,0 +1,15 @@
+package domain
+
+type HealthStatus string
+
+const (
+	HealthUp       HealthStatus = "up"
+	HealthDegraded HealthStatus = "degraded"
+	HealthDown     HealthStatus = "down"
+)
+
+type ComponentHealth struct {
+	Name   string
+	Status HealthStatus
+	Detail string
+}
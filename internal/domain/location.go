@@ -0,0 +1,71 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	MaxLocationsPerUser   = 20
+	MaxLocationNameLength = 100
+)
+
+// Location is a place a user has saved so that weather lookups need not name
+// it every time. At most one of a user's locations is primary; it is used
+// when a lookup names no place.
+type Location struct {
+	ID        string
+	UserID    string
+	Name      string
+	Query     WeatherQuery
+	Primary   bool
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// NewLocation saves query under name, which defaults to the city or the
+// coordinates.
+func NewLocation(userID, name string, query WeatherQuery) (*Location, error) {
+	if userID == "" {
+		return nil, errors.New("user ID is required")
+	}
+
+	location := &Location{
+		UserID:    userID,
+		CreatedAt: time.Now(),
+	}
+	if err := location.Change(name, query); err != nil {
+		return nil, err
+	}
+	return location, nil
+}
+
+// Change renames the location and points it at another place.
+func (l *Location) Change(name string, query WeatherQuery) error {
+	if err := query.Validate(); err != nil {
+		return err
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = defaultLocationName(query)
+	}
+	if utf8.RuneCountInString(name) > MaxLocationNameLength {
+		return fmt.Errorf("name must be at most %d characters", MaxLocationNameLength)
+	}
+
+	l.Name = name
+	l.Query = query
+	l.UpdatedAt = time.Now()
+	return nil
+}
+
+func defaultLocationName(query WeatherQuery) string {
+	if query.Coordinates != nil {
+		return query.Coordinates.String()
+	}
+	return strings.TrimSpace(query.City)
+}
@@ -0,0 +1,43 @@
+package domain_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/leinonen/hexagonal-architecture-go/internal/domain"
+)
+
+func TestNewLocation(t *testing.T) {
+	tests := []struct {
+		name         string
+		userID       string
+		locationName string
+		query        domain.WeatherQuery
+		wantName     string
+		wantErr      bool
+	}{
+		{name: "named", userID: "user_1", locationName: " Home ", query: domain.CityQuery("London"), wantName: "Home"},
+		{name: "named after the city", userID: "user_1", query: domain.CityQuery(" Paris "), wantName: "Paris"},
+		{
+			name:     "named after the coordinates",
+			userID:   "user_1",
+			query:    domain.WeatherQuery{Coordinates: &domain.Coordinates{Latitude: 35.68, Longitude: 139.69}},
+			wantName: "35.6800,139.6900",
+		},
+		{name: "missing user", query: domain.CityQuery("London"), wantErr: true},
+		{name: "missing place", userID: "user_1", locationName: "Home", wantErr: true},
+		{name: "name too long", userID: "user_1", locationName: strings.Repeat("x", domain.MaxLocationNameLength+1), query: domain.CityQuery("London"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			location, err := domain.NewLocation(tt.userID, tt.locationName, tt.query)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewLocation() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && location.Name != tt.wantName {
+				t.Errorf("NewLocation() name = %q, want %q", location.Name, tt.wantName)
+			}
+		})
+	}
+}
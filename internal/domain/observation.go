@@ -0,0 +1,132 @@
+package domain
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	DefaultHistoryPeriod   = 24 * time.Hour
+	DefaultHistoryInterval = time.Hour
+	MinHistoryInterval     = time.Minute
+	// MaxHistoryBuckets bounds how many intervals one history request may
+	// span.
+	MaxHistoryBuckets = 1000
+)
+
+// Observation is weather fetched from a provider for a place, kept so that
+// trends can be charted. Weather is metric, as providers report it.
+type Observation struct {
+	ID string
+	// Query names the place the provider resolved the lookup to, so that
+	// lookups naming one place in different ways share a history.
+	Query WeatherQuery
+	// Lookup is the query the weather was fetched for, by which the history
+	// of Query can be found too.
+	Lookup     WeatherQuery
+	Weather    Weather
+	RecordedAt time.Time
+}
+
+func NewObservation(query WeatherQuery, weather *Weather) *Observation {
+	return &Observation{
+		Query:      weather.Place(query),
+		Lookup:     query,
+		Weather:    *weather,
+		RecordedAt: time.Now(),
+	}
+}
+
+// Time is when the provider measured the weather, or when it was recorded
+// for providers that do not say.
+func (o *Observation) Time() time.Time {
+	if !o.Weather.ObservedAt.IsZero() {
+		return o.Weather.ObservedAt
+	}
+	return o.RecordedAt
+}
+
+// Aggregate summarises the readings of one metric over an interval.
+type Aggregate struct {
+	Min float64
+	Max float64
+	Avg float64
+}
+
+// HistoryBucket aggregates the observations made in [Start, Start+interval).
+type HistoryBucket struct {
+	Start       time.Time
+	Count       int
+	Temperature Aggregate
+	Humidity    Aggregate
+	WindSpeed   Aggregate
+	Pressure    Aggregate
+}
+
+// History is the aggregated weather at a place between From and To, one
+// bucket per Interval that has any observations, oldest first.
+type History struct {
+	Query    WeatherQuery
+	From     time.Time
+	To       time.Time
+	Interval time.Duration
+	Units    Units
+	Buckets  []HistoryBucket
+}
+
+// NewHistory aggregates observations into intervals counted from from,
+// converting them to units. Observations outside [from, to) are ignored.
+func NewHistory(query WeatherQuery, observations []*Observation, from, to time.Time, interval time.Duration, units Units) *History {
+	history := &History{
+		Query:    query,
+		From:     from,
+		To:       to,
+		Interval: interval,
+		Units:    units,
+		Buckets:  make([]HistoryBucket, 0),
+	}
+
+	readings := make(map[int][]*Weather)
+	for _, observation := range observations {
+		at := observation.Time()
+		if at.Before(from) || !at.Before(to) {
+			continue
+		}
+		i := int(at.Sub(from) / interval)
+		readings[i] = append(readings[i], observation.Weather.InUnits(units))
+	}
+
+	indexes := make([]int, 0, len(readings))
+	for i := range readings {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+
+	for _, i := range indexes {
+		weathers := readings[i]
+		history.Buckets = append(history.Buckets, HistoryBucket{
+			Start:       from.Add(time.Duration(i) * interval),
+			Count:       len(weathers),
+			Temperature: aggregate(weathers, func(w *Weather) float64 { return w.Temperature }),
+			Humidity:    aggregate(weathers, func(w *Weather) float64 { return float64(w.Humidity) }),
+			WindSpeed:   aggregate(weathers, func(w *Weather) float64 { return w.WindSpeed }),
+			Pressure:    aggregate(weathers, func(w *Weather) float64 { return w.Pressure }),
+		})
+	}
+
+	return history
+}
+
+func aggregate(weathers []*Weather, metric func(*Weather) float64) Aggregate {
+	result := Aggregate{Min: math.Inf(1), Max: math.Inf(-1)}
+	sum := 0.0
+	for _, weather := range weathers {
+		value := metric(weather)
+		result.Min = min(result.Min, value)
+		result.Max = max(result.Max, value)
+		sum += value
+	}
+	result.Avg = sum / float64(len(weathers))
+	return result
+}
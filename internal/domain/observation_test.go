@@ -0,0 +1,84 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/leinonen/hexagonal-architecture-go/internal/domain"
+)
+
+func TestNewHistory(t *testing.T) {
+	from := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	to := from.Add(3 * time.Hour)
+	london := domain.CityQuery("London")
+
+	observe := func(offset time.Duration, temperature float64, humidity int) *domain.Observation {
+		return &domain.Observation{
+			Query:   london,
+			Weather: domain.Weather{Temperature: temperature, Humidity: humidity, WindSpeed: 10, Units: domain.UnitsMetric, ObservedAt: from.Add(offset)},
+		}
+	}
+
+	observations := []*domain.Observation{
+		observe(-time.Minute, 50, 0),
+		observe(10*time.Minute, 0, 80),
+		observe(50*time.Minute, 10, 60),
+		observe(20*time.Minute, 5, 70),
+		observe(2*time.Hour+30*time.Minute, -5, 90),
+		observe(3*time.Hour, 50, 0),
+	}
+
+	history := domain.NewHistory(london, observations, from, to, time.Hour, domain.UnitsImperial)
+
+	if len(history.Buckets) != 2 {
+		t.Fatalf("NewHistory() returned %d buckets, want 2 (empty hours and readings outside the range are left out)", len(history.Buckets))
+	}
+
+	first := history.Buckets[0]
+	if !first.Start.Equal(from) || first.Count != 3 {
+		t.Errorf("first bucket starts %v with %d readings, want %v with 3", first.Start, first.Count, from)
+	}
+	if first.Temperature != (domain.Aggregate{Min: 32, Max: 50, Avg: 41}) {
+		t.Errorf("first bucket temperature = %+v, want 32–50 °F averaging 41", first.Temperature)
+	}
+	if first.Humidity != (domain.Aggregate{Min: 60, Max: 80, Avg: 70}) {
+		t.Errorf("first bucket humidity = %+v, want 60–80%% averaging 70", first.Humidity)
+	}
+	if speed := first.WindSpeed.Avg; speed < 22.3 || speed > 22.4 {
+		t.Errorf("first bucket wind speed = %v, want about 22.37 mph", speed)
+	}
+
+	last := history.Buckets[1]
+	if !last.Start.Equal(from.Add(2*time.Hour)) || last.Count != 1 || last.Temperature.Min != 23 {
+		t.Errorf("last bucket = %+v, want the 02:00 bucket at 23 °F", last)
+	}
+}
+
+func TestObservation_Time(t *testing.T) {
+	recorded := time.Date(2024, 1, 15, 6, 5, 0, 0, time.UTC)
+	observed := recorded.Add(-5 * time.Minute)
+
+	observation := &domain.Observation{RecordedAt: recorded}
+	if !observation.Time().Equal(recorded) {
+		t.Errorf("Time() = %v, want the recording time when the provider gives none", observation.Time())
+	}
+
+	observation.Weather.ObservedAt = observed
+	if !observation.Time().Equal(observed) {
+		t.Errorf("Time() = %v, want the observation time", observation.Time())
+	}
+}
+
+func TestNewObservation_ResolvedPlace(t *testing.T) {
+	coordinates := domain.WeatherQuery{Coordinates: &domain.Coordinates{Latitude: 51.51, Longitude: -0.13}}
+
+	observation := domain.NewObservation(coordinates, &domain.Weather{City: "London", Country: "GB"})
+	if want := (domain.WeatherQuery{City: "London", Country: "GB"}); observation.Query != want {
+		t.Errorf("NewObservation() query = %s, want the resolved place %s", observation.Query.Key(), want.Key())
+	}
+
+	observation = domain.NewObservation(coordinates, &domain.Weather{})
+	if observation.Query != coordinates {
+		t.Errorf("NewObservation() query = %s, want the lookup when the provider names no city", observation.Query.Key())
+	}
+}
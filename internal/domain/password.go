@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"errors"
+	"unicode"
+)
+
+const (
+	MinPasswordLength = 12
+	// MaxPasswordBytes matches the input limit of bcrypt; longer passwords
+	// would be silently truncated.
+	MaxPasswordBytes = 72
+)
+
+func ValidatePassword(password string) error {
+	if len([]rune(password)) < MinPasswordLength {
+		return errors.New("password must be at least 12 characters")
+	}
+	if len(password) > MaxPasswordBytes {
+		return errors.New("password must be at most 72 bytes")
+	}
+
+	var hasLetter, hasDigit, hasOther bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLetter(r):
+			hasLetter = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		default:
+			hasOther = true
+		}
+	}
+
+	if !hasLetter || !(hasDigit || hasOther) {
+		return errors.New("password must contain letters and at least one digit or symbol")
+	}
+
+	return nil
+}
@@ -0,0 +1,61 @@
+package domain_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/leinonen/hexagonal-architecture-go/internal/domain"
+)
+
+func TestValidatePassword(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		wantErr  bool
+	}{
+		{
+			name:     "letters and digits",
+			password: "correcthorse42",
+			wantErr:  false,
+		},
+		{
+			name:     "letters and symbols",
+			password: "correct-horse-battery",
+			wantErr:  false,
+		},
+		{
+			name:     "too short",
+			password: "short1",
+			wantErr:  true,
+		},
+		{
+			name:     "letters only",
+			password: "correcthorsebattery",
+			wantErr:  true,
+		},
+		{
+			name:     "digits only",
+			password: "123456789012",
+			wantErr:  true,
+		},
+		{
+			name:     "longer than bcrypt accepts",
+			password: strings.Repeat("a1", 40),
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := domain.ValidatePassword(tt.password)
+
+			if tt.wantErr && err == nil {
+				t.Errorf("ValidatePassword() expected error, got nil")
+			}
+
+			if !tt.wantErr && err != nil {
+				t.Errorf("ValidatePassword() unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+type Coordinates struct {
+	Latitude  float64
+	Longitude float64
+}
+
+func (c Coordinates) Validate() error {
+	if !(c.Latitude >= -90 && c.Latitude <= 90) {
+		return errors.New("latitude must be between -90 and 90")
+	}
+	if !(c.Longitude >= -180 && c.Longitude <= 180) {
+		return errors.New("longitude must be between -180 and 180")
+	}
+	return nil
+}
+
+func (c Coordinates) String() string {
+	return fmt.Sprintf("%.4f,%.4f", c.Latitude, c.Longitude)
+}
+
+// Place is a named location returned by geocoding. Country is an ISO 3166
+// alpha-2 code; State is only set where the provider knows it.
+type Place struct {
+	Name        string
+	State       string
+	Country     string
+	Coordinates Coordinates
+}
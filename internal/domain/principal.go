@@ -0,0 +1,19 @@
+package domain
+
+type AuthMethod string
+
+const (
+	AuthMethodAPIKey AuthMethod = "api_key"
+	AuthMethodJWT    AuthMethod = "jwt"
+)
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	UserID string
+	Role   Role
+	Method AuthMethod
+}
+
+func (p *Principal) IsAdmin() bool {
+	return p.Role == RoleAdmin
+}
@@ -0,0 +1,82 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+const SessionLifetime = 30 * 24 * time.Hour
+
+// MaxRotatedTokenHashes bounds how many rotated away refresh token hashes a
+// session remembers. Replaying an older token is still refused, just without
+// revoking the session.
+const MaxRotatedTokenHashes = 32
+
+type ClientInfo struct {
+	UserAgent string
+	IPAddress string
+}
+
+// Session is a long-lived login backed by a rotating refresh token. Only
+// hashes of refresh tokens are kept; hashes of tokens that were already
+// rotated away are remembered so that replaying one can be detected.
+type Session struct {
+	ID                 string
+	UserID             string
+	RefreshTokenHash   string
+	RotatedTokenHashes []string
+	Client             ClientInfo
+	CreatedAt          time.Time
+	LastUsedAt         time.Time
+	ExpiresAt          time.Time
+	RevokedAt          time.Time
+}
+
+func NewSession(userID, refreshTokenHash string, client ClientInfo) (*Session, error) {
+	if userID == "" {
+		return nil, errors.New("user ID is required")
+	}
+	if refreshTokenHash == "" {
+		return nil, errors.New("refresh token hash is required")
+	}
+
+	now := time.Now()
+	return &Session{
+		UserID:           userID,
+		RefreshTokenHash: refreshTokenHash,
+		Client:           client,
+		CreatedAt:        now,
+		LastUsedAt:       now,
+		ExpiresAt:        now.Add(SessionLifetime),
+	}, nil
+}
+
+func (s *Session) IsActive(now time.Time) bool {
+	return s.RevokedAt.IsZero() && now.Before(s.ExpiresAt)
+}
+
+func (s *Session) Revoke(now time.Time) {
+	if s.RevokedAt.IsZero() {
+		s.RevokedAt = now
+	}
+}
+
+// Rotate replaces the current refresh token hash and remembers the old one,
+// forgetting the oldest once MaxRotatedTokenHashes are remembered.
+func (s *Session) Rotate(newHash string, now time.Time) {
+	if n := len(s.RotatedTokenHashes); n >= MaxRotatedTokenHashes {
+		s.RotatedTokenHashes = append(s.RotatedTokenHashes[:0:0], s.RotatedTokenHashes[n-MaxRotatedTokenHashes+1:]...)
+	}
+	s.RotatedTokenHashes = append(s.RotatedTokenHashes, s.RefreshTokenHash)
+	s.RefreshTokenHash = newHash
+	s.LastUsedAt = now
+}
+
+func (s *Session) WasRotatedFrom(hash string) bool {
+	for _, h := range s.RotatedTokenHashes {
+		if h == hash {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+package domain_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/leinonen/hexagonal-architecture-go/internal/domain"
+)
+
+func TestNewSession(t *testing.T) {
+	if _, err := domain.NewSession("", "hash", domain.ClientInfo{}); err == nil {
+		t.Errorf("NewSession() without user ID should fail")
+	}
+	if _, err := domain.NewSession("user_1", "", domain.ClientInfo{}); err == nil {
+		t.Errorf("NewSession() without token hash should fail")
+	}
+
+	session, err := domain.NewSession("user_1", "hash", domain.ClientInfo{})
+	if err != nil {
+		t.Fatalf("NewSession() unexpected error: %v", err)
+	}
+	if !session.IsActive(time.Now()) {
+		t.Errorf("NewSession() should be active")
+	}
+	if session.IsActive(session.ExpiresAt) {
+		t.Errorf("Session should not be active once expired")
+	}
+}
+
+func TestSession_RotateAndRevoke(t *testing.T) {
+	session, _ := domain.NewSession("user_1", "hash_1", domain.ClientInfo{})
+	now := time.Now()
+
+	session.Rotate("hash_2", now)
+	if session.RefreshTokenHash != "hash_2" || !session.WasRotatedFrom("hash_1") {
+		t.Errorf("Rotate() should replace the hash and remember the old one")
+	}
+	if session.WasRotatedFrom("hash_2") {
+		t.Errorf("WasRotatedFrom() should not match the current hash")
+	}
+
+	session.Revoke(now)
+	if session.IsActive(now) {
+		t.Errorf("Revoke() should deactivate the session")
+	}
+
+	session.Revoke(now.Add(time.Hour))
+	if !session.RevokedAt.Equal(now) {
+		t.Errorf("Revoke() should keep the first revocation time")
+	}
+}
+
+func TestSession_RotateForgetsOldestHashes(t *testing.T) {
+	session, _ := domain.NewSession("user_1", "hash_0", domain.ClientInfo{})
+	now := time.Now()
+
+	for i := 1; i <= domain.MaxRotatedTokenHashes+5; i++ {
+		session.Rotate(fmt.Sprintf("hash_%d", i), now)
+	}
+
+	if len(session.RotatedTokenHashes) != domain.MaxRotatedTokenHashes {
+		t.Errorf("Rotate() remembered %d hashes, want %d", len(session.RotatedTokenHashes), domain.MaxRotatedTokenHashes)
+	}
+	if session.WasRotatedFrom("hash_0") {
+		t.Errorf("WasRotatedFrom() should have forgotten the oldest hash")
+	}
+	if !session.WasRotatedFrom(fmt.Sprintf("hash_%d", domain.MaxRotatedTokenHashes+4)) {
+		t.Errorf("WasRotatedFrom() should remember the latest rotated hash")
+	}
+}
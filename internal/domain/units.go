@@ -0,0 +1,71 @@
+package domain
+
+// Units is the measurement system temperatures and wind speeds are reported
+// in. Providers always report metric; other systems are converted from it.
+// Pressure, visibility and precipitation are always metric.
+type Units string
+
+const (
+	// UnitsMetric reports temperature in °C and wind speed in m/s.
+	UnitsMetric Units = "metric"
+	// UnitsImperial reports temperature in °F and wind speed in mph.
+	UnitsImperial Units = "imperial"
+	// UnitsStandard reports temperature in K and wind speed in m/s.
+	UnitsStandard Units = "standard"
+)
+
+const metersPerSecondToMPH = 3600 / 1609.344
+
+func (u Units) Valid() bool {
+	return u == UnitsMetric || u == UnitsImperial || u == UnitsStandard
+}
+
+func (u Units) temperature(celsius float64) float64 {
+	switch u {
+	case UnitsImperial:
+		return celsius*9/5 + 32
+	case UnitsStandard:
+		return celsius + 273.15
+	}
+	return celsius
+}
+
+func (u Units) windSpeed(metersPerSecond float64) float64 {
+	if u == UnitsImperial {
+		return metersPerSecond * metersPerSecondToMPH
+	}
+	return metersPerSecond
+}
+
+// InUnits returns a copy of metric weather converted to units.
+func (w Weather) InUnits(units Units) *Weather {
+	w.Temperature = units.temperature(w.Temperature)
+	w.FeelsLike = units.temperature(w.FeelsLike)
+	w.MinTemperature = units.temperature(w.MinTemperature)
+	w.MaxTemperature = units.temperature(w.MaxTemperature)
+	w.WindSpeed = units.windSpeed(w.WindSpeed)
+	w.WindGust = units.windSpeed(w.WindGust)
+	w.Units = units
+	return &w
+}
+
+// InUnits returns a copy of a metric forecast converted to units.
+func (f Forecast) InUnits(units Units) *Forecast {
+	hourly := make([]HourlyForecast, len(f.Hourly))
+	for i, hour := range f.Hourly {
+		hour.Temperature = units.temperature(hour.Temperature)
+		hour.WindSpeed = units.windSpeed(hour.WindSpeed)
+		hourly[i] = hour
+	}
+
+	daily := make([]DailyForecast, len(f.Daily))
+	for i, day := range f.Daily {
+		day.MinTemperature = units.temperature(day.MinTemperature)
+		day.MaxTemperature = units.temperature(day.MaxTemperature)
+		day.MaxWindSpeed = units.windSpeed(day.MaxWindSpeed)
+		daily[i] = day
+	}
+
+	f.Hourly, f.Daily, f.Units = hourly, daily, units
+	return &f
+}
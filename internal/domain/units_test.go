@@ -0,0 +1,58 @@
+package domain_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/leinonen/hexagonal-architecture-go/internal/domain"
+)
+
+func TestWeather_InUnits(t *testing.T) {
+	metric := domain.Weather{City: "London", Temperature: 20, WindSpeed: 10, Units: domain.UnitsMetric}
+
+	tests := []struct {
+		units           domain.Units
+		wantTemperature float64
+		wantWindSpeed   float64
+	}{
+		{units: domain.UnitsMetric, wantTemperature: 20, wantWindSpeed: 10},
+		{units: domain.UnitsImperial, wantTemperature: 68, wantWindSpeed: 22.369},
+		{units: domain.UnitsStandard, wantTemperature: 293.15, wantWindSpeed: 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.units), func(t *testing.T) {
+			converted := metric.InUnits(tt.units)
+
+			if math.Abs(converted.Temperature-tt.wantTemperature) > 0.001 {
+				t.Errorf("InUnits() temperature = %v, want %v", converted.Temperature, tt.wantTemperature)
+			}
+			if math.Abs(converted.WindSpeed-tt.wantWindSpeed) > 0.001 {
+				t.Errorf("InUnits() wind speed = %v, want %v", converted.WindSpeed, tt.wantWindSpeed)
+			}
+			if converted.Units != tt.units {
+				t.Errorf("InUnits() units = %v, want %v", converted.Units, tt.units)
+			}
+		})
+	}
+
+	if metric.Temperature != 20 {
+		t.Errorf("InUnits() modified the original weather")
+	}
+}
+
+func TestForecast_InUnits(t *testing.T) {
+	metric := domain.Forecast{
+		Hourly: []domain.HourlyForecast{{Temperature: 0, WindSpeed: 1}},
+		Daily:  []domain.DailyForecast{{MinTemperature: -40, MaxTemperature: 100, MaxWindSpeed: 0}},
+	}
+
+	converted := metric.InUnits(domain.UnitsImperial)
+
+	if converted.Hourly[0].Temperature != 32 || converted.Daily[0].MinTemperature != -40 || converted.Daily[0].MaxTemperature != 212 {
+		t.Errorf("InUnits() = %+v, want Fahrenheit", converted)
+	}
+	if metric.Hourly[0].Temperature != 0 || metric.Daily[0].MaxTemperature != 100 {
+		t.Errorf("InUnits() modified the original forecast")
+	}
+}
@@ -5,12 +5,57 @@ import (
 	"time"
 )
 
+type Role string
+
+const (
+	RoleAdmin Role = "admin"
+	RoleUser  Role = "user"
+)
+
+func (r Role) Valid() bool {
+	return r == RoleAdmin || r == RoleUser
+}
+
+const (
+	MaxFailedLogins = 5
+	LockoutDuration = 15 * time.Minute
+)
+
 type User struct {
-	ID        string
-	Email     string
-	Name      string
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID           string
+	Email        string
+	Name         string
+	Role         Role
+	PasswordHash string
+	// PreferredUnits is used for weather lookups that do not ask for units.
+	PreferredUnits Units
+	FailedLogins   int
+	LockedUntil    time.Time
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+func (u *User) HasPassword() bool {
+	return u.PasswordHash != ""
+}
+
+func (u *User) IsLocked(now time.Time) bool {
+	return now.Before(u.LockedUntil)
+}
+
+// RecordFailedLogin counts a failed attempt and locks the account once
+// MaxFailedLogins consecutive failures have been reached.
+func (u *User) RecordFailedLogin(now time.Time) {
+	u.FailedLogins++
+	if u.FailedLogins >= MaxFailedLogins {
+		u.LockedUntil = now.Add(LockoutDuration)
+		u.FailedLogins = 0
+	}
+}
+
+func (u *User) RecordSuccessfulLogin() {
+	u.FailedLogins = 0
+	u.LockedUntil = time.Time{}
 }
 
 func NewUser(email, name string) (*User, error) {
@@ -24,7 +69,8 @@ func NewUser(email, name string) (*User, error) {
 	return &User{
 		Email:     email,
 		Name:      name,
+		Role:      RoleUser,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}, nil
-}
\ No newline at end of file
+}
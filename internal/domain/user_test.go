@@ -64,6 +64,10 @@ func TestNewUser(t *testing.T) {
 				t.Errorf("NewUser() name = %v, want %v", user.Name, tt.userName)
 			}
 
+			if user.Role != domain.RoleUser {
+				t.Errorf("NewUser() role = %v, want %v", user.Role, domain.RoleUser)
+			}
+
 			if user.CreatedAt.IsZero() {
 				t.Errorf("NewUser() CreatedAt should not be zero")
 			}
@@ -78,3 +82,32 @@ func TestNewUser(t *testing.T) {
 		})
 	}
 }
+
+func TestUser_Lockout(t *testing.T) {
+	user, _ := domain.NewUser("test@example.com", "Test User")
+	now := time.Now()
+
+	for i := 0; i < domain.MaxFailedLogins-1; i++ {
+		user.RecordFailedLogin(now)
+	}
+
+	if user.IsLocked(now) {
+		t.Fatalf("IsLocked() = true after %d failures, want false", domain.MaxFailedLogins-1)
+	}
+
+	user.RecordFailedLogin(now)
+
+	if !user.IsLocked(now) {
+		t.Fatalf("IsLocked() = false after %d failures, want true", domain.MaxFailedLogins)
+	}
+
+	if user.IsLocked(now.Add(domain.LockoutDuration)) {
+		t.Errorf("IsLocked() should expire after LockoutDuration")
+	}
+
+	user.RecordSuccessfulLogin()
+
+	if user.IsLocked(now) || user.FailedLogins != 0 {
+		t.Errorf("RecordSuccessfulLogin() should clear the lockout")
+	}
+}
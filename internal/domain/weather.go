@@ -1,9 +1,52 @@
 package domain
 
+import "time"
+
 type Weather struct {
-	City        string
-	Temperature float64
-	Description string
-	Humidity    int
-	WindSpeed   float64
-}
\ No newline at end of file
+	City           string
+	Country        string
+	Coordinates    Coordinates
+	Temperature    float64
+	FeelsLike      float64
+	MinTemperature float64
+	MaxTemperature float64
+	Condition      Condition
+	Description    string
+	Humidity       int
+	// Pressure is the sea-level air pressure in hPa.
+	Pressure float64
+	// Visibility is in metres.
+	Visibility float64
+	// CloudCover is the share of the sky covered by cloud, in percent.
+	CloudCover int
+	WindSpeed  float64
+	WindGust   float64
+	// WindDirection is the direction the wind blows from, in degrees
+	// clockwise from north.
+	WindDirection int
+	// Precipitation is rain and snow over the last hour, in mm.
+	Precipitation float64
+	Sunrise       time.Time
+	Sunset        time.Time
+	// ObservedAt is when the provider measured the conditions.
+	ObservedAt time.Time
+	// Units says how temperatures and wind speeds are measured. Providers
+	// report metric.
+	Units Units
+	// Provider names the upstream service that produced the data.
+	Provider string
+
+	// FetchedAt is when the provider produced the data; ExpiresAt is set by a
+	// cache to say how long it will keep serving it.
+	FetchedAt time.Time
+	ExpiresAt time.Time
+}
+
+// Place names the place the weather is for by the city and country the
+// provider resolved query to, or is query when the provider named no city.
+func (w Weather) Place(query WeatherQuery) WeatherQuery {
+	if w.City == "" {
+		return query
+	}
+	return WeatherQuery{City: w.City, Country: w.Country}
+}
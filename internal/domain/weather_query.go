@@ -0,0 +1,92 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const MaxCityLength = 100
+
+// WeatherQuery says where weather is wanted: either by city name, optionally
+// narrowed by ISO 3166 country code, or by coordinates.
+type WeatherQuery struct {
+	City        string
+	Country     string
+	Coordinates *Coordinates
+}
+
+func CityQuery(city string) WeatherQuery {
+	return WeatherQuery{City: city}
+}
+
+// IsZero reports whether the query names no place at all.
+func (q WeatherQuery) IsZero() bool {
+	return q.City == "" && q.Country == "" && q.Coordinates == nil
+}
+
+func (q WeatherQuery) Validate() error {
+	if q.Coordinates != nil {
+		if q.City != "" || q.Country != "" {
+			return errors.New("either a city or coordinates may be given, not both")
+		}
+		return q.Coordinates.Validate()
+	}
+
+	if strings.TrimSpace(q.City) == "" {
+		return errors.New("city is required")
+	}
+	if utf8.RuneCountInString(q.City) > MaxCityLength {
+		return fmt.Errorf("city must be at most %d characters", MaxCityLength)
+	}
+	if !isCityName(q.City) {
+		return errors.New("city may only contain letters, digits, spaces, hyphens, apostrophes and periods")
+	}
+	if q.Country != "" && !isCountryCode(q.Country) {
+		return errors.New("country must be a two-letter ISO 3166 code")
+	}
+	return nil
+}
+
+// Key identifies the query regardless of letter case and spacing, for use by
+// caches.
+func (q WeatherQuery) Key() string {
+	if q.Coordinates != nil {
+		return "coord:" + q.Coordinates.String()
+	}
+
+	key := "city:" + strings.ToLower(strings.Join(strings.Fields(q.City), " "))
+	if q.Country != "" {
+		key += "," + strings.ToLower(q.Country)
+	}
+	return key
+}
+
+// isCityName accepts place names in any script, such as "São Paulo",
+// "Saint-Étienne", "N'Djamena" or "St. John's", and rejects characters that
+// have a meaning in URLs or provider queries.
+func isCityName(name string) bool {
+	for _, r := range name {
+		switch {
+		case unicode.IsLetter(r), unicode.IsMark(r), unicode.IsDigit(r):
+		case r == ' ', r == '-', r == '\'', r == '’', r == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func isCountryCode(code string) bool {
+	if len(code) != 2 {
+		return false
+	}
+	for _, r := range code {
+		if (r < 'A' || r > 'Z') && (r < 'a' || r > 'z') {
+			return false
+		}
+	}
+	return true
+}
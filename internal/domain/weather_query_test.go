@@ -0,0 +1,64 @@
+package domain_test
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/leinonen/hexagonal-architecture-go/internal/domain"
+)
+
+func TestWeatherQuery_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   domain.WeatherQuery
+		wantErr bool
+	}{
+		{name: "city", query: domain.CityQuery("London")},
+		{name: "city and country", query: domain.WeatherQuery{City: "Paris", Country: "US"}},
+		{name: "coordinates", query: domain.WeatherQuery{Coordinates: &domain.Coordinates{Latitude: -33.87, Longitude: 151.21}}},
+		{name: "coordinate bounds", query: domain.WeatherQuery{Coordinates: &domain.Coordinates{Latitude: 90, Longitude: -180}}},
+		{name: "empty", query: domain.WeatherQuery{}, wantErr: true},
+		{name: "blank city", query: domain.CityQuery("   "), wantErr: true},
+		{name: "country name", query: domain.WeatherQuery{City: "Paris", Country: "France"}, wantErr: true},
+		{name: "latitude too large", query: domain.WeatherQuery{Coordinates: &domain.Coordinates{Latitude: 90.1}}, wantErr: true},
+		{name: "longitude too small", query: domain.WeatherQuery{Coordinates: &domain.Coordinates{Longitude: -181}}, wantErr: true},
+		{name: "not a number", query: domain.WeatherQuery{Coordinates: &domain.Coordinates{Latitude: math.NaN()}}, wantErr: true},
+		{name: "city and coordinates", query: domain.WeatherQuery{City: "London", Coordinates: &domain.Coordinates{}}, wantErr: true},
+		{name: "accented city", query: domain.CityQuery("São Paulo")},
+		{name: "punctuated city", query: domain.CityQuery("St. John's")},
+		{name: "non-latin city", query: domain.CityQuery("東京")},
+		{name: "injected parameter", query: domain.CityQuery("São Paulo&units=imperial"), wantErr: true},
+		{name: "injected country", query: domain.CityQuery("Paris,US"), wantErr: true},
+		{name: "path traversal", query: domain.CityQuery("../../admin"), wantErr: true},
+		{name: "fragment", query: domain.CityQuery("London#appid=x"), wantErr: true},
+		{name: "control character", query: domain.CityQuery("London\r\nX-Injected: 1"), wantErr: true},
+		{name: "too long", query: domain.CityQuery(strings.Repeat("a", domain.MaxCityLength+1)), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.query.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWeatherQuery_Key(t *testing.T) {
+	if a, b := domain.CityQuery(" New  York "), domain.CityQuery("new york"); a.Key() != b.Key() {
+		t.Errorf("Key() = %q and %q, want equal for the same city", a.Key(), b.Key())
+	}
+
+	paris := domain.CityQuery("Paris")
+	parisUS := domain.WeatherQuery{City: "Paris", Country: "us"}
+	if paris.Key() == parisUS.Key() {
+		t.Errorf("Key() = %q for both, want country to distinguish queries", paris.Key())
+	}
+
+	near := domain.WeatherQuery{Coordinates: &domain.Coordinates{Latitude: 51.50001, Longitude: -0.12}}
+	far := domain.WeatherQuery{Coordinates: &domain.Coordinates{Latitude: 51.6, Longitude: -0.12}}
+	if near.Key() != "coord:51.5000,-0.1200" || near.Key() == far.Key() {
+		t.Errorf("Key() = %q and %q", near.Key(), far.Key())
+	}
+}
@@ -0,0 +1,70 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/leinonen/hexagonal-architecture-go/internal/domain"
+)
+
+// AlertRuleDTO asks to be alerted when Metric at a place compares to
+// Threshold as Comparator says, e.g. "temperature" "lt" 0. Units default to
+// the user's preferred units.
+type AlertRuleDTO struct {
+	WeatherQueryDTO
+	Metric     string   `json:"metric"`
+	Comparator string   `json:"comparator"`
+	Threshold  *float64 `json:"threshold"`
+	Units      string   `json:"units,omitempty"`
+}
+
+type AlertRuleResponseDTO struct {
+	ID string `json:"id"`
+	WeatherQueryDTO
+	Metric      string  `json:"metric"`
+	Comparator  string  `json:"comparator"`
+	Threshold   float64 `json:"threshold"`
+	Units       string  `json:"units"`
+	Triggered   bool    `json:"triggered"`
+	TriggeredAt string  `json:"triggered_at,omitempty"`
+	CreatedAt   string  `json:"created_at"`
+}
+
+func ToAlertRuleResponseDTO(rule *domain.AlertRule) *AlertRuleResponseDTO {
+	if rule == nil {
+		return nil
+	}
+
+	response := &AlertRuleResponseDTO{
+		ID: rule.ID,
+		WeatherQueryDTO: WeatherQueryDTO{
+			City:    rule.Query.City,
+			Country: rule.Query.Country,
+		},
+		Metric:     string(rule.Metric),
+		Comparator: string(rule.Comparator),
+		Threshold:  rule.Threshold,
+		Units:      string(rule.Units),
+		Triggered:  rule.Triggered,
+		CreatedAt:  rule.CreatedAt.Format(time.RFC3339),
+	}
+	if coordinates := rule.Query.Coordinates; coordinates != nil {
+		latitude, longitude := coordinates.Latitude, coordinates.Longitude
+		response.Latitude, response.Longitude = &latitude, &longitude
+	}
+	if !rule.TriggeredAt.IsZero() {
+		response.TriggeredAt = rule.TriggeredAt.Format(time.RFC3339)
+	}
+	return response
+}
+
+func ToAlertRuleResponseDTOs(rules []*domain.AlertRule) []*AlertRuleResponseDTO {
+	if rules == nil {
+		return nil
+	}
+
+	dtos := make([]*AlertRuleResponseDTO, len(rules))
+	for i, rule := range rules {
+		dtos[i] = ToAlertRuleResponseDTO(rule)
+	}
+	return dtos
+}
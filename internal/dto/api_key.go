@@ -0,0 +1,64 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/leinonen/hexagonal-architecture-go/internal/domain"
+)
+
+type CreateAPIKeyDTO struct {
+	Name string `json:"name" validate:"required,min=1"`
+}
+
+type APIKeyResponseDTO struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Prefix     string `json:"prefix"`
+	CreatedAt  string `json:"created_at"`
+	LastUsedAt string `json:"last_used_at,omitempty"`
+}
+
+type IssuedAPIKeyResponseDTO struct {
+	*APIKeyResponseDTO
+	Key string `json:"key"`
+}
+
+func ToAPIKeyResponseDTO(key *domain.APIKey) *APIKeyResponseDTO {
+	if key == nil {
+		return nil
+	}
+
+	response := &APIKeyResponseDTO{
+		ID:        key.ID,
+		Name:      key.Name,
+		Prefix:    key.Prefix,
+		CreatedAt: key.CreatedAt.Format(time.RFC3339),
+	}
+	if !key.LastUsedAt.IsZero() {
+		response.LastUsedAt = key.LastUsedAt.Format(time.RFC3339)
+	}
+	return response
+}
+
+func ToAPIKeyResponseDTOs(keys []*domain.APIKey) []*APIKeyResponseDTO {
+	if keys == nil {
+		return nil
+	}
+
+	dtos := make([]*APIKeyResponseDTO, len(keys))
+	for i, key := range keys {
+		dtos[i] = ToAPIKeyResponseDTO(key)
+	}
+	return dtos
+}
+
+func ToIssuedAPIKeyResponseDTO(key *domain.APIKey, secret string) *IssuedAPIKeyResponseDTO {
+	if key == nil {
+		return nil
+	}
+
+	return &IssuedAPIKeyResponseDTO{
+		APIKeyResponseDTO: ToAPIKeyResponseDTO(key),
+		Key:               secret,
+	}
+}
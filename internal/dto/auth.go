@@ -0,0 +1,86 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/leinonen/hexagonal-architecture-go/internal/domain"
+)
+
+type LoginDTO struct {
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required"`
+}
+
+type ChangePasswordDTO struct {
+	CurrentPassword string `json:"current_password,omitempty"`
+	NewPassword     string `json:"new_password" validate:"required,min=12"`
+}
+
+type RefreshTokenDTO struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+type TokenResponseDTO struct {
+	AccessToken      string `json:"access_token"`
+	TokenType        string `json:"token_type"`
+	ExpiresIn        int    `json:"expires_in"`
+	ExpiresAt        string `json:"expires_at"`
+	RefreshToken     string `json:"refresh_token,omitempty"`
+	RefreshExpiresAt string `json:"refresh_expires_at,omitempty"`
+	SessionID        string `json:"session_id,omitempty"`
+}
+
+type SessionResponseDTO struct {
+	ID         string `json:"id"`
+	UserAgent  string `json:"user_agent,omitempty"`
+	IPAddress  string `json:"ip_address,omitempty"`
+	CreatedAt  string `json:"created_at"`
+	LastUsedAt string `json:"last_used_at"`
+	ExpiresAt  string `json:"expires_at"`
+}
+
+func ToTokenResponseDTO(tokens *domain.AuthTokens) *TokenResponseDTO {
+	if tokens == nil {
+		return nil
+	}
+
+	response := &TokenResponseDTO{
+		AccessToken:  tokens.AccessToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(time.Until(tokens.ExpiresAt).Seconds()),
+		ExpiresAt:    tokens.ExpiresAt.Format(time.RFC3339),
+		RefreshToken: tokens.RefreshToken,
+		SessionID:    tokens.SessionID,
+	}
+	if !tokens.RefreshExpiresAt.IsZero() {
+		response.RefreshExpiresAt = tokens.RefreshExpiresAt.Format(time.RFC3339)
+	}
+	return response
+}
+
+func ToSessionResponseDTO(session *domain.Session) *SessionResponseDTO {
+	if session == nil {
+		return nil
+	}
+
+	return &SessionResponseDTO{
+		ID:         session.ID,
+		UserAgent:  session.Client.UserAgent,
+		IPAddress:  session.Client.IPAddress,
+		CreatedAt:  session.CreatedAt.Format(time.RFC3339),
+		LastUsedAt: session.LastUsedAt.Format(time.RFC3339),
+		ExpiresAt:  session.ExpiresAt.Format(time.RFC3339),
+	}
+}
+
+func ToSessionResponseDTOs(sessions []*domain.Session) []*SessionResponseDTO {
+	if sessions == nil {
+		return nil
+	}
+
+	dtos := make([]*SessionResponseDTO, len(sessions))
+	for i, session := range sessions {
+		dtos[i] = ToSessionResponseDTO(session)
+	}
+	return dtos
+}
@@ -0,0 +1,41 @@
+package dto
+
+import "github.com/leinonen/hexagonal-architecture-go/internal/domain"
+
+type HealthResponseDTO struct {
+	Status     string                `json:"status"`
+	Components []*ComponentHealthDTO `json:"components,omitempty"`
+}
+
+type ComponentHealthDTO struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Detail string `json:"detail,omitempty"`
+}
+
+func ToHealthResponseDTO(status domain.HealthStatus, components []domain.ComponentHealth) *HealthResponseDTO {
+	response := &HealthResponseDTO{
+		Status: healthLabel(status),
+	}
+
+	for _, component := range components {
+		response.Components = append(response.Components, &ComponentHealthDTO{
+			Name:   component.Name,
+			Status: string(component.Status),
+			Detail: component.Detail,
+		})
+	}
+
+	return response
+}
+
+func healthLabel(status domain.HealthStatus) string {
+	switch status {
+	case domain.HealthUp:
+		return "healthy"
+	case domain.HealthDegraded:
+		return "degraded"
+	default:
+		return "unhealthy"
+	}
+}
@@ -0,0 +1,73 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/leinonen/hexagonal-architecture-go/internal/domain"
+)
+
+// LocationDTO saves a place under a name. Name defaults to the city or the
+// coordinates.
+type LocationDTO struct {
+	Name string `json:"name,omitempty"`
+	WeatherQueryDTO
+	Primary bool `json:"primary,omitempty"`
+}
+
+type LocationResponseDTO struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	WeatherQueryDTO
+	Primary   bool   `json:"primary"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+func ToLocationResponseDTO(location *domain.Location) *LocationResponseDTO {
+	if location == nil {
+		return nil
+	}
+
+	response := &LocationResponseDTO{
+		ID:   location.ID,
+		Name: location.Name,
+		WeatherQueryDTO: WeatherQueryDTO{
+			City:    location.Query.City,
+			Country: location.Query.Country,
+		},
+		Primary:   location.Primary,
+		CreatedAt: location.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: location.UpdatedAt.Format(time.RFC3339),
+	}
+	if coordinates := location.Query.Coordinates; coordinates != nil {
+		latitude, longitude := coordinates.Latitude, coordinates.Longitude
+		response.Latitude, response.Longitude = &latitude, &longitude
+	}
+	return response
+}
+
+func ToLocationResponseDTOs(locations []*domain.Location) []*LocationResponseDTO {
+	if locations == nil {
+		return nil
+	}
+
+	dtos := make([]*LocationResponseDTO, len(locations))
+	for i, location := range locations {
+		dtos[i] = ToLocationResponseDTO(location)
+	}
+	return dtos
+}
+
+// DashboardItemDTO is the current weather at one saved location. Like
+// BatchWeatherResultDTO it carries either Weather or Error, with Status the
+// HTTP status the lookup on its own would have answered with.
+type DashboardItemDTO struct {
+	Location *LocationResponseDTO `json:"location"`
+	Status   int                  `json:"status"`
+	Weather  *WeatherResponseDTO  `json:"weather,omitempty"`
+	Error    string               `json:"error,omitempty"`
+}
+
+type DashboardResponseDTO struct {
+	Locations []DashboardItemDTO `json:"locations"`
+}
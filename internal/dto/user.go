@@ -7,23 +7,35 @@ import (
 )
 
 type CreateUserDTO struct {
-	Email string `json:"email" validate:"required,email"`
-	Name  string `json:"name" validate:"required,min=1"`
+	Email    string `json:"email" validate:"required,email"`
+	Name     string `json:"name" validate:"required,min=1"`
+	Password string `json:"password,omitempty" validate:"omitempty,min=12"`
 }
 
 type UpdateUserDTO struct {
 	Email string `json:"email,omitempty" validate:"omitempty,email"`
 	Name  string `json:"name,omitempty" validate:"omitempty,min=1"`
+	Role  string `json:"role,omitempty" validate:"omitempty,oneof=admin user"`
+	Units string `json:"units,omitempty" validate:"omitempty,oneof=metric imperial standard"`
 }
 
 type UserResponseDTO struct {
 	ID        string `json:"id"`
 	Email     string `json:"email"`
 	Name      string `json:"name"`
+	Role      string `json:"role"`
+	Units     string `json:"units,omitempty"`
 	CreatedAt string `json:"created_at"`
 	UpdatedAt string `json:"updated_at"`
 }
 
+// CreateUserResponseDTO is returned once on registration and carries the
+// user's initial API key, which cannot be retrieved again.
+type CreateUserResponseDTO struct {
+	*UserResponseDTO
+	APIKey string `json:"api_key,omitempty"`
+}
+
 func ToUserResponseDTO(user *domain.User) *UserResponseDTO {
 	if user == nil {
 		return nil
@@ -33,6 +45,8 @@ func ToUserResponseDTO(user *domain.User) *UserResponseDTO {
 		ID:        user.ID,
 		Email:     user.Email,
 		Name:      user.Name,
+		Role:      string(user.Role),
+		Units:     string(user.PreferredUnits),
 		CreatedAt: user.CreatedAt.Format(time.RFC3339),
 		UpdatedAt: user.UpdatedAt.Format(time.RFC3339),
 	}
@@ -1,13 +1,42 @@
 package dto
 
-import "github.com/leinonen/hexagonal-architecture-go/internal/domain"
+import (
+	"time"
+
+	"github.com/leinonen/hexagonal-architecture-go/internal/domain"
+)
+
+// WeatherResponseVersion identifies the shape of WeatherResponseDTO. Version 2
+// added feels-like, min/max, pressure, visibility, cloud cover, wind gust and
+// direction, precipitation, sun times, observation time and condition. Fields
+// are only ever added within a version, never renamed or removed.
+const WeatherResponseVersion = 2
 
 type WeatherResponseDTO struct {
-	City        string  `json:"city"`
-	Temperature float64 `json:"temperature"`
-	Description string  `json:"description"`
-	Humidity    int     `json:"humidity"`
-	WindSpeed   float64 `json:"wind_speed"`
+	Version        int     `json:"version"`
+	City           string  `json:"city"`
+	Country        string  `json:"country,omitempty"`
+	Latitude       float64 `json:"latitude"`
+	Longitude      float64 `json:"longitude"`
+	Temperature    float64 `json:"temperature"`
+	FeelsLike      float64 `json:"feels_like"`
+	MinTemperature float64 `json:"min_temperature"`
+	MaxTemperature float64 `json:"max_temperature"`
+	Condition      string  `json:"condition"`
+	Description    string  `json:"description"`
+	Humidity       int     `json:"humidity"`
+	Pressure       float64 `json:"pressure"`
+	Visibility     float64 `json:"visibility"`
+	CloudCover     int     `json:"cloud_cover"`
+	WindSpeed      float64 `json:"wind_speed"`
+	WindGust       float64 `json:"wind_gust"`
+	WindDirection  int     `json:"wind_direction"`
+	Precipitation  float64 `json:"precipitation"`
+	Sunrise        string  `json:"sunrise,omitempty"`
+	Sunset         string  `json:"sunset,omitempty"`
+	ObservedAt     string  `json:"observed_at,omitempty"`
+	Units          string  `json:"units"`
+	Provider       string  `json:"provider,omitempty"`
 }
 
 func ToWeatherResponseDTO(weather *domain.Weather) *WeatherResponseDTO {
@@ -16,10 +45,219 @@ func ToWeatherResponseDTO(weather *domain.Weather) *WeatherResponseDTO {
 	}
 
 	return &WeatherResponseDTO{
-		City:        weather.City,
-		Temperature: weather.Temperature,
-		Description: weather.Description,
-		Humidity:    weather.Humidity,
-		WindSpeed:   weather.WindSpeed,
+		Version:        WeatherResponseVersion,
+		City:           weather.City,
+		Country:        weather.Country,
+		Latitude:       weather.Coordinates.Latitude,
+		Longitude:      weather.Coordinates.Longitude,
+		Temperature:    weather.Temperature,
+		FeelsLike:      weather.FeelsLike,
+		MinTemperature: weather.MinTemperature,
+		MaxTemperature: weather.MaxTemperature,
+		Condition:      string(weather.Condition),
+		Description:    weather.Description,
+		Humidity:       weather.Humidity,
+		Pressure:       weather.Pressure,
+		Visibility:     weather.Visibility,
+		CloudCover:     weather.CloudCover,
+		WindSpeed:      weather.WindSpeed,
+		WindGust:       weather.WindGust,
+		WindDirection:  weather.WindDirection,
+		Precipitation:  weather.Precipitation,
+		Sunrise:        formatTime(weather.Sunrise),
+		Sunset:         formatTime(weather.Sunset),
+		ObservedAt:     formatTime(weather.ObservedAt),
+		Units:          string(weather.Units),
+		Provider:       weather.Provider,
+	}
+}
+
+// formatTime renders times as RFC 3339 and leaves unknown times empty.
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+type ForecastResponseDTO struct {
+	City      string               `json:"city"`
+	Country   string               `json:"country,omitempty"`
+	Latitude  float64              `json:"latitude"`
+	Longitude float64              `json:"longitude"`
+	Units     string               `json:"units"`
+	Provider  string               `json:"provider,omitempty"`
+	Daily     []*DailyForecastDTO  `json:"daily"`
+	Hourly    []*HourlyForecastDTO `json:"hourly"`
+}
+
+type DailyForecastDTO struct {
+	Date                string  `json:"date"`
+	MinTemperature      float64 `json:"min_temperature"`
+	MaxTemperature      float64 `json:"max_temperature"`
+	Description         string  `json:"description"`
+	Humidity            int     `json:"humidity"`
+	MaxWindSpeed        float64 `json:"max_wind_speed"`
+	PrecipitationChance float64 `json:"precipitation_chance"`
+}
+
+type HourlyForecastDTO struct {
+	Time                string  `json:"time"`
+	Temperature         float64 `json:"temperature"`
+	Description         string  `json:"description"`
+	Humidity            int     `json:"humidity"`
+	WindSpeed           float64 `json:"wind_speed"`
+	PrecipitationChance float64 `json:"precipitation_chance"`
+}
+
+func ToForecastResponseDTO(forecast *domain.Forecast) *ForecastResponseDTO {
+	if forecast == nil {
+		return nil
+	}
+
+	response := &ForecastResponseDTO{
+		City:      forecast.City,
+		Country:   forecast.Country,
+		Latitude:  forecast.Coordinates.Latitude,
+		Longitude: forecast.Coordinates.Longitude,
+		Units:     string(forecast.Units),
+		Provider:  forecast.Provider,
+		Daily:     make([]*DailyForecastDTO, len(forecast.Daily)),
+		Hourly:    make([]*HourlyForecastDTO, len(forecast.Hourly)),
+	}
+
+	for i, day := range forecast.Daily {
+		response.Daily[i] = &DailyForecastDTO{
+			Date:                day.Date.Format(time.DateOnly),
+			MinTemperature:      day.MinTemperature,
+			MaxTemperature:      day.MaxTemperature,
+			Description:         day.Description,
+			Humidity:            day.Humidity,
+			MaxWindSpeed:        day.MaxWindSpeed,
+			PrecipitationChance: day.PrecipitationChance,
+		}
+	}
+
+	for i, hour := range forecast.Hourly {
+		response.Hourly[i] = &HourlyForecastDTO{
+			Time:                hour.Time.Format(time.RFC3339),
+			Temperature:         hour.Temperature,
+			Description:         hour.Description,
+			Humidity:            hour.Humidity,
+			WindSpeed:           hour.WindSpeed,
+			PrecipitationChance: hour.PrecipitationChance,
+		}
+	}
+
+	return response
+}
+
+type PlaceResponseDTO struct {
+	Name      string  `json:"name"`
+	State     string  `json:"state,omitempty"`
+	Country   string  `json:"country"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+func ToPlaceResponseDTO(place *domain.Place) *PlaceResponseDTO {
+	if place == nil {
+		return nil
+	}
+
+	return &PlaceResponseDTO{
+		Name:      place.Name,
+		State:     place.State,
+		Country:   place.Country,
+		Latitude:  place.Coordinates.Latitude,
+		Longitude: place.Coordinates.Longitude,
+	}
+}
+
+// WeatherQueryDTO names a place either by city, optionally with an ISO 3166
+// alpha-2 country code, or by latitude and longitude.
+type WeatherQueryDTO struct {
+	City      string   `json:"city,omitempty"`
+	Country   string   `json:"country,omitempty"`
+	Latitude  *float64 `json:"latitude,omitempty"`
+	Longitude *float64 `json:"longitude,omitempty"`
+}
+
+type BatchWeatherRequestDTO struct {
+	Places []WeatherQueryDTO `json:"places"`
+}
+
+// BatchWeatherResultDTO is the outcome for one place of a batch. Status is
+// the HTTP status the same lookup on its own would have answered with.
+type BatchWeatherResultDTO struct {
+	Query   WeatherQueryDTO     `json:"query"`
+	Status  int                 `json:"status"`
+	Weather *WeatherResponseDTO `json:"weather,omitempty"`
+	Error   string              `json:"error,omitempty"`
+}
+
+type BatchWeatherResponseDTO struct {
+	Results []BatchWeatherResultDTO `json:"results"`
+}
+
+type AggregateDTO struct {
+	Min float64 `json:"min"`
+	Max float64 `json:"max"`
+	Avg float64 `json:"avg"`
+}
+
+// HistoryPointDTO aggregates the observations made in the interval starting
+// at Start.
+type HistoryPointDTO struct {
+	Start       string       `json:"start"`
+	Count       int          `json:"count"`
+	Temperature AggregateDTO `json:"temperature"`
+	Humidity    AggregateDTO `json:"humidity"`
+	WindSpeed   AggregateDTO `json:"wind_speed"`
+	Pressure    AggregateDTO `json:"pressure"`
+}
+
+// HistoryResponseDTO lists one point per interval that has observations;
+// intervals without any are left out.
+type HistoryResponseDTO struct {
+	WeatherQueryDTO
+	From     string            `json:"from"`
+	To       string            `json:"to"`
+	Interval string            `json:"interval"`
+	Units    string            `json:"units"`
+	Points   []HistoryPointDTO `json:"points"`
+}
+
+func ToHistoryResponseDTO(history *domain.History) *HistoryResponseDTO {
+	if history == nil {
+		return nil
+	}
+
+	response := &HistoryResponseDTO{
+		WeatherQueryDTO: WeatherQueryDTO{
+			City:    history.Query.City,
+			Country: history.Query.Country,
+		},
+		From:     history.From.UTC().Format(time.RFC3339),
+		To:       history.To.UTC().Format(time.RFC3339),
+		Interval: history.Interval.String(),
+		Units:    string(history.Units),
+		Points:   make([]HistoryPointDTO, len(history.Buckets)),
+	}
+	if coordinates := history.Query.Coordinates; coordinates != nil {
+		latitude, longitude := coordinates.Latitude, coordinates.Longitude
+		response.Latitude, response.Longitude = &latitude, &longitude
+	}
+
+	for i, bucket := range history.Buckets {
+		response.Points[i] = HistoryPointDTO{
+			Start:       bucket.Start.UTC().Format(time.RFC3339),
+			Count:       bucket.Count,
+			Temperature: AggregateDTO(bucket.Temperature),
+			Humidity:    AggregateDTO(bucket.Humidity),
+			WindSpeed:   AggregateDTO(bucket.WindSpeed),
+			Pressure:    AggregateDTO(bucket.Pressure),
+		}
 	}
+	return response
 }
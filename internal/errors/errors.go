@@ -1,29 +1,45 @@
 package errors
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 type ErrorType string
 
 const (
-	NotFound         ErrorType = "NOT_FOUND"
-	Validation       ErrorType = "VALIDATION"
-	Conflict         ErrorType = "CONFLICT"
-	Internal         ErrorType = "INTERNAL"
-	ExternalService  ErrorType = "EXTERNAL_SERVICE"
-	Unauthorized     ErrorType = "UNAUTHORIZED"
+	NotFound        ErrorType = "NOT_FOUND"
+	Validation      ErrorType = "VALIDATION"
+	Conflict        ErrorType = "CONFLICT"
+	Internal        ErrorType = "INTERNAL"
+	ExternalService ErrorType = "EXTERNAL_SERVICE"
+	Unauthorized    ErrorType = "UNAUTHORIZED"
+	Forbidden       ErrorType = "FORBIDDEN"
+	// Misconfiguration means an upstream service rejected our own credentials
+	// or settings, so retrying will not help until an operator steps in.
+	Misconfiguration ErrorType = "MISCONFIGURATION"
+	RateLimited      ErrorType = "RATE_LIMITED"
 )
 
 type AppError struct {
 	Type    ErrorType
 	Message string
 	Err     error
+	// Detail is what an upstream service said about the failure, for logs.
+	Detail string
+	// RetryAfter is how long a rate-limited caller should wait, when known.
+	RetryAfter time.Duration
 }
 
 func (e *AppError) Error() string {
+	message := e.Message
+	if e.Detail != "" {
+		message += ": " + e.Detail
+	}
 	if e.Err != nil {
-		return fmt.Sprintf("%s: %s (%v)", e.Type, e.Message, e.Err)
+		return fmt.Sprintf("%s: %s (%v)", e.Type, message, e.Err)
 	}
-	return fmt.Sprintf("%s: %s", e.Type, e.Message)
+	return fmt.Sprintf("%s: %s", e.Type, message)
 }
 
 func (e *AppError) Unwrap() error {
@@ -72,6 +88,51 @@ func NewUnauthorizedError(message string) error {
 	}
 }
 
+func NewForbiddenError(message string) error {
+	return &AppError{
+		Type:    Forbidden,
+		Message: message,
+	}
+}
+
+func NewMisconfigurationError(message string) error {
+	return &AppError{
+		Type:    Misconfiguration,
+		Message: message,
+	}
+}
+
+// NewRateLimitedError reports that an upstream service is throttling us.
+// retryAfter is zero when the service did not say how long to wait.
+func NewRateLimitedError(message string, retryAfter time.Duration) error {
+	return &AppError{
+		Type:       RateLimited,
+		Message:    message,
+		RetryAfter: retryAfter,
+	}
+}
+
+// WithDetail returns a copy of an AppError carrying detail. Other errors are
+// returned unchanged.
+func WithDetail(err error, detail string) error {
+	appErr, ok := err.(*AppError)
+	if !ok || detail == "" {
+		return err
+	}
+	detailed := *appErr
+	detailed.Detail = detail
+	return &detailed
+}
+
+// RetryAfter reports how long to wait before retrying a rate-limited call.
+func RetryAfter(err error) (time.Duration, bool) {
+	appErr, ok := err.(*AppError)
+	if !ok || appErr.Type != RateLimited || appErr.RetryAfter <= 0 {
+		return 0, false
+	}
+	return appErr.RetryAfter, true
+}
+
 func IsNotFound(err error) bool {
 	appErr, ok := err.(*AppError)
 	return ok && appErr.Type == NotFound
@@ -100,4 +161,25 @@ func IsExternalService(err error) bool {
 func IsUnauthorized(err error) bool {
 	appErr, ok := err.(*AppError)
 	return ok && appErr.Type == Unauthorized
-}
\ No newline at end of file
+}
+
+func IsForbidden(err error) bool {
+	appErr, ok := err.(*AppError)
+	return ok && appErr.Type == Forbidden
+}
+
+func IsMisconfiguration(err error) bool {
+	appErr, ok := err.(*AppError)
+	return ok && appErr.Type == Misconfiguration
+}
+
+func IsRateLimited(err error) bool {
+	appErr, ok := err.(*AppError)
+	return ok && appErr.Type == RateLimited
+}
+
+// IsUpstreamFailure reports whether err means an upstream service could not
+// answer, as opposed to answering that something does not exist.
+func IsUpstreamFailure(err error) bool {
+	return IsExternalService(err) || IsMisconfiguration(err) || IsRateLimited(err)
+}
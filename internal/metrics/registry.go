@@ -0,0 +1,90 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	kindCounter = "counter"
+	kindGauge   = "gauge"
+)
+
+// Registry collects metrics that are read on demand and renders them in the
+// Prometheus text exposition format. A name may carry labels, as in
+// `name{label="value"}`; series sharing a base name are grouped under one
+// HELP/TYPE header.
+type Registry struct {
+	mu       sync.RWMutex
+	families map[string]*family
+	order    []string
+}
+
+type family struct {
+	help   string
+	kind   string
+	series []series
+}
+
+type series struct {
+	name  string
+	value func() float64
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		families: make(map[string]*family),
+	}
+}
+
+// CounterFunc registers a monotonically increasing value read from fn.
+func (r *Registry) CounterFunc(name, help string, fn func() float64) {
+	r.register(name, help, kindCounter, fn)
+}
+
+// GaugeFunc registers a value that may go up and down, read from fn.
+func (r *Registry) GaugeFunc(name, help string, fn func() float64) {
+	r.register(name, help, kindGauge, fn)
+}
+
+func (r *Registry) register(name, help, kind string, fn func() float64) {
+	base, _, _ := strings.Cut(name, "{")
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	f, exists := r.families[base]
+	if !exists {
+		f = &family{help: help, kind: kind}
+		r.families[base] = f
+		r.order = append(r.order, base)
+	}
+	f.series = append(f.series, series{name: name, value: fn})
+}
+
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var b strings.Builder
+	for _, base := range r.order {
+		f := r.families[base]
+		fmt.Fprintf(&b, "# HELP %s %s\n", base, f.help)
+		fmt.Fprintf(&b, "# TYPE %s %s\n", base, f.kind)
+		for _, s := range f.series {
+			fmt.Fprintf(&b, "%s %s\n", s.name, strconv.FormatFloat(s.value(), 'g', -1, 64))
+		}
+	}
+
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	r.WriteTo(w)
+}
@@ -0,0 +1,53 @@
+package metrics_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/leinonen/hexagonal-architecture-go/internal/metrics"
+)
+
+func TestRegistry_WriteTo(t *testing.T) {
+	registry := metrics.NewRegistry()
+
+	hits := 0.0
+	registry.CounterFunc("cache_hits_total", "Cache hits.", func() float64 { return hits })
+	registry.GaugeFunc(`circuit_state{provider="a"}`, "Circuit state.", func() float64 { return 1 })
+	registry.GaugeFunc(`circuit_state{provider="b"}`, "Circuit state.", func() float64 { return 0.5 })
+
+	hits = 3
+
+	var out strings.Builder
+	if _, err := registry.WriteTo(&out); err != nil {
+		t.Fatalf("WriteTo() unexpected error: %v", err)
+	}
+
+	want := `# HELP cache_hits_total Cache hits.
+# TYPE cache_hits_total counter
+cache_hits_total 3
+# HELP circuit_state Circuit state.
+# TYPE circuit_state gauge
+circuit_state{provider="a"} 1
+circuit_state{provider="b"} 0.5
+`
+	if out.String() != want {
+		t.Errorf("WriteTo() =\n%s\nwant\n%s", out.String(), want)
+	}
+}
+
+func TestRegistry_ServeHTTP(t *testing.T) {
+	registry := metrics.NewRegistry()
+	registry.GaugeFunc("up", "Whether the server is up.", func() float64 { return 1 })
+
+	w := httptest.NewRecorder()
+	registry.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if !strings.HasPrefix(w.Header().Get("Content-Type"), "text/plain") {
+		t.Errorf("ServeHTTP() content type = %v, want text/plain", w.Header().Get("Content-Type"))
+	}
+	if !strings.Contains(w.Body.String(), "up 1\n") {
+		t.Errorf("ServeHTTP() body = %q, want the up series", w.Body.String())
+	}
+}
@@ -0,0 +1,11 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/leinonen/hexagonal-architecture-go/internal/domain"
+)
+
+type HealthChecker interface {
+	CheckHealth(ctx context.Context) domain.ComponentHealth
+}
@@ -0,0 +1,13 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/leinonen/hexagonal-architecture-go/internal/domain"
+)
+
+// Notifier delivers alerts to users. An error means the alert was not
+// delivered and should be sent again later.
+type Notifier interface {
+	Notify(ctx context.Context, alert domain.Alert) error
+}
@@ -2,6 +2,7 @@ package ports
 
 import (
 	"context"
+	"time"
 
 	"github.com/leinonen/hexagonal-architecture-go/internal/domain"
 )
@@ -13,4 +14,58 @@ type UserRepository interface {
 	Update(ctx context.Context, user *domain.User) error
 	Delete(ctx context.Context, id string) error
 	List(ctx context.Context, limit, offset int) ([]*domain.User, error)
+	// RecordFailedLogin counts a failed login against the user in one step,
+	// so that concurrent attempts cannot lose each other's counts.
+	RecordFailedLogin(ctx context.Context, id string, now time.Time) error
+	// ResetFailedLogins clears the user's failed login count and lockout.
+	ResetFailedLogins(ctx context.Context, id string) error
+}
+
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *domain.APIKey) error
+	GetByHash(ctx context.Context, hash string) (*domain.APIKey, error)
+	ListByUser(ctx context.Context, userID string) ([]*domain.APIKey, error)
+	Update(ctx context.Context, key *domain.APIKey) error
+	Delete(ctx context.Context, id string) error
+}
+
+type SessionRepository interface {
+	Create(ctx context.Context, session *domain.Session) error
+	GetByID(ctx context.Context, id string) (*domain.Session, error)
+	Update(ctx context.Context, session *domain.Session) error
+	ListByUser(ctx context.Context, userID string) ([]*domain.Session, error)
+}
+
+// UserOwnedRepository stores records that belong to a user and are deleted
+// along with them.
+type UserOwnedRepository interface {
+	DeleteByUser(ctx context.Context, userID string) error
+}
+
+type LocationRepository interface {
+	Create(ctx context.Context, location *domain.Location) error
+	GetByID(ctx context.Context, id string) (*domain.Location, error)
+	ListByUser(ctx context.Context, userID string) ([]*domain.Location, error)
+	Update(ctx context.Context, location *domain.Location) error
+	Delete(ctx context.Context, id string) error
+	UserOwnedRepository
+}
+
+type AlertRuleRepository interface {
+	Create(ctx context.Context, rule *domain.AlertRule) error
+	GetByID(ctx context.Context, id string) (*domain.AlertRule, error)
+	ListByUser(ctx context.Context, userID string) ([]*domain.AlertRule, error)
+	// List returns every user's rules, for evaluation.
+	List(ctx context.Context) ([]*domain.AlertRule, error)
+	Update(ctx context.Context, rule *domain.AlertRule) error
+	Delete(ctx context.Context, id string) error
+	UserOwnedRepository
+}
+
+type ObservationRepository interface {
+	Record(ctx context.Context, observation *domain.Observation) error
+	// List returns the observations of the place query names made in
+	// [from, to), oldest first. query may name the place as the provider
+	// resolved it, or as any lookup recorded for it.
+	List(ctx context.Context, query domain.WeatherQuery, from, to time.Time) ([]*domain.Observation, error)
 }
@@ -0,0 +1,16 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/leinonen/hexagonal-architecture-go/internal/domain"
+)
+
+type TokenVerifier interface {
+	Verify(ctx context.Context, token string) (*domain.Principal, error)
+}
+
+type TokenIssuer interface {
+	Issue(ctx context.Context, principal *domain.Principal, ttl time.Duration) (string, time.Time, error)
+}
@@ -7,5 +7,10 @@ import (
 )
 
 type WeatherService interface {
-	GetWeather(ctx context.Context, city string) (*domain.Weather, error)
+	GetWeather(ctx context.Context, query domain.WeatherQuery) (*domain.Weather, error)
+	GetForecast(ctx context.Context, query domain.WeatherQuery, days int) (*domain.Forecast, error)
+}
+
+type Geocoder interface {
+	Geocode(ctx context.Context, name, country string, limit int) ([]domain.Place, error)
 }